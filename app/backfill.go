@@ -0,0 +1,41 @@
+package app
+
+import (
+	"category-crud/config"
+	"category-crud/db"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+const backfillUsage = "usage: backfill snapshots"
+
+// Backfill runs the `backfill` subcommand, which fills columns added to
+// existing tables after the fact. Like Migrate it returns errors so the
+// connection is closed before the process exits.
+func Backfill(args []string) error {
+	if len(args) == 0 || args[0] != "snapshots" {
+		return errors.New(backfillUsage)
+	}
+
+	config, err := config.Load()
+	if err != nil {
+		return err
+	}
+	conn, err := db.Open(*config)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// finished batches stay committed; rerun to continue
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	updated, err := db.BackfillSnapshots(ctx, conn)
+	fmt.Printf("Backfilled %d transaction details\n", updated)
+	return err
+}
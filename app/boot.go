@@ -1,19 +1,21 @@
 package app
 
 import (
+	"category-crud/auth"
 	"category-crud/config"
 	"category-crud/db"
 	_ "category-crud/docs"
 	"category-crud/handler"
+	"category-crud/logger"
+	"category-crud/metrics"
+	"category-crud/model"
 	"category-crud/repository"
+	"category-crud/repository/memory"
 	"category-crud/route"
 	"category-crud/service"
-	"database/sql"
 	"fmt"
 	"log"
-	"net/http"
-
-	"github.com/doug-martin/goqu/v9"
+	"log/slog"
 )
 
 // @title Category CRUD API
@@ -28,50 +30,201 @@ import (
 // @license.url https://opensource.org/licenses/MIT
 // @BasePath /
 
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description Static API key from auth.api_keys
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT signed with HS256 or RS256, sent as "Bearer <token>". The role claim must be admin, manager or cashier.
+
 func Start() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run serves HTTP until SIGINT or SIGTERM and releases the repositories
+// once in-flight requests have drained.
+func run() error {
 	config, err := config.Load()
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	if _, err := logger.Setup(*config); err != nil {
+		return err
 	}
-	db, builder, err := db.Configure(*config)
+	if config.Store.Currency != "" {
+		if err := model.SetDefaultCurrency(config.Store.Currency); err != nil {
+			return err
+		}
+	}
+	var appMetrics *metrics.Metrics
+	if config.Metrics.Enabled {
+		appMetrics = metrics.New()
+	}
+	repositories, closeRepositories, err := setupRepositories(*config, appMetrics)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	defer db.Close()
-	productHandler, _, productRepo := setupProduct(db, builder)
+	defer closeRepositories()
+	authMiddleware, err := setupAuth(*config, repositories.User)
+	if err != nil {
+		return err
+	}
+	userHandler, err := setupUser(*config, repositories.User)
+	if err != nil {
+		return err
+	}
+	transactionHandler, err := setupTransaction(*config, repositories.Transaction, appMetrics)
+	if err != nil {
+		return err
+	}
+	healthService := service.NewHealthService(repositories.Health)
 	handlerGroup := &handler.HandlerGroup{
-		Product:     productHandler,
-		Category:    setupCategory(db, builder),
-		Transaction: setupTransaction(db, builder, productRepo),
+		Product:     setupProduct(repositories.Product, repositories.Category, repositories.TaxClass),
+		Category:    setupCategory(repositories.Category, repositories.TaxClass),
+		Promotion:   setupPromotion(repositories.Promotion, repositories.Product, repositories.Category),
+		TaxClass:    setupTaxClass(repositories.TaxClass),
+		Shift:       setupShift(repositories.Shift, repositories.Transaction),
+		Transaction: transactionHandler,
+		User:        userHandler,
+		Health:      handler.NewHealthHandler(healthService),
+		Auth:        authMiddleware,
+	}
+	if appMetrics != nil {
+		handlerGroup.Metrics = handler.NewMetricsHandler(appMetrics)
 	}
 	r := route.Configure(handlerGroup)
 
-	port := config.Server.Port
-	fmt.Println("Server starting on :" + port)
-	fmt.Println("Swagger documentation available at http://localhost:8080/swagger/index.html")
-	log.Fatal(http.ListenAndServe(":"+port, r))
+	server := newServer(*config, r)
+	slog.Info("server starting",
+		slog.String("addr", server.Addr),
+		slog.String("swagger", "http://localhost:"+config.Server.Port+"/swagger/index.html"),
+	)
+	return serve(server, config.Server.ShutdownTimeout, healthService.ShuttingDown)
+}
+
+// setupRepositories builds the storage backend selected by db.driver and
+// returns a function that releases it.
+func setupRepositories(config config.Template, appMetrics *metrics.Metrics) (*repository.RepositoryGroup, func() error, error) {
+	switch config.DB.Driver {
+	case "memory":
+		store := memory.NewStore()
+		return &repository.RepositoryGroup{
+			Category:    memory.NewCategoryRepository(store),
+			Product:     memory.NewProductRepository(store),
+			Promotion:   memory.NewPromotionRepository(store),
+			Shift:       memory.NewShiftRepository(store),
+			TaxClass:    memory.NewTaxClassRepository(store),
+			Transaction: memory.NewTransactionRepository(store),
+			User:        memory.NewUserRepository(store),
+			Health:      memory.NewHealthRepository(),
+		}, func() error { return nil }, nil
+	case "", "postgres":
+		conn, builder, err := db.Configure(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		migrator, err := db.NewMigrator(conn)
+		if err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+		appMetrics.RegisterDB(conn, "postgres")
+		timeout := config.DB.QueryTimeout
+		return &repository.RepositoryGroup{
+			Category:    repository.NewCategoryRepository(conn, builder, timeout),
+			Product:     repository.NewProductRepository(conn, builder, timeout),
+			Promotion:   repository.NewPromotionRepository(conn, builder, timeout),
+			Shift:       repository.NewShiftRepository(conn, builder, timeout),
+			TaxClass:    repository.NewTaxClassRepository(conn, builder, timeout),
+			Transaction: repository.NewTransactionRepository(conn, builder, timeout),
+			User:        repository.NewUserRepository(conn, builder, timeout),
+			Health:      repository.NewHealthRepository(conn, migrator),
+		}, conn.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown db driver %q", config.DB.Driver)
+	}
 }
 
-func setupProduct(db *sql.DB, builder *goqu.Database) (*handler.ProductHandler, *service.ProductService, *repository.ProductRepository) {
-	productRepo := repository.NewProductRepository(db, builder)
-	productService := service.NewProductService(productRepo)
+func setupAuth(config config.Template, userRepo repository.UserRepository) (*handler.AuthMiddleware, error) {
+	if !config.Auth.Enabled {
+		slog.Warn("authentication is disabled, every route is public")
+		return handler.NewAuthMiddleware(nil), nil
+	}
+
+	authenticator, err := auth.NewAuthenticator(config, userRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler.NewAuthMiddleware(authenticator), nil
+}
+
+func setupUser(config config.Template, userRepo repository.UserRepository) (*handler.UserHandler, error) {
+	issuer, err := auth.NewIssuer(config)
+	if err != nil {
+		return nil, err
+	}
+	userService := service.NewUserService(userRepo, issuer)
+	userHandler := handler.NewUserHandler(userService)
+
+	return userHandler, nil
+}
+
+func setupProduct(productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository, taxClassRepo repository.TaxClassRepository) *handler.ProductHandler {
+	productService := service.NewProductService(productRepo, categoryRepo, taxClassRepo)
 	productHandler := handler.NewProductHandler(productService)
 
-	return productHandler, productService, productRepo
+	return productHandler
 }
 
-func setupCategory(db *sql.DB, builder *goqu.Database) *handler.CategoryHandler {
-	categoryRepo := repository.NewCategoryRepository(db, builder)
-	categoryService := service.NewCategoryService(categoryRepo)
+func setupCategory(categoryRepo repository.CategoryRepository, taxClassRepo repository.TaxClassRepository) *handler.CategoryHandler {
+	categoryService := service.NewCategoryService(categoryRepo, taxClassRepo)
 	categoryHandler := handler.NewCategoryHandler(categoryService)
 
 	return categoryHandler
 }
 
-func setupTransaction(db *sql.DB, builder *goqu.Database, productRepo *repository.ProductRepository) *handler.TransactionHandler {
-	transactionRepo := repository.NewTransactionRepository(db, builder, productRepo)
-	transactionService := service.NewTransactionService(transactionRepo)
+func setupPromotion(promotionRepo repository.PromotionRepository, productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository) *handler.PromotionHandler {
+	promotionService := service.NewPromotionService(promotionRepo, productRepo, categoryRepo)
+	promotionHandler := handler.NewPromotionHandler(promotionService)
+
+	return promotionHandler
+}
+
+func setupTaxClass(taxClassRepo repository.TaxClassRepository) *handler.TaxClassHandler {
+	taxClassService := service.NewTaxClassService(taxClassRepo)
+	taxClassHandler := handler.NewTaxClassHandler(taxClassService)
+
+	return taxClassHandler
+}
+
+func setupShift(shiftRepo repository.ShiftRepository, transactionRepo repository.TransactionRepository) *handler.ShiftHandler {
+	shiftService := service.NewShiftService(shiftRepo, transactionRepo)
+	shiftHandler := handler.NewShiftHandler(shiftService)
+
+	return shiftHandler
+}
+
+func setupTransaction(config config.Template, transactionRepo repository.TransactionRepository, appMetrics *metrics.Metrics) (*handler.TransactionHandler, error) {
+	tax, err := service.ParseTaxPolicy(config.Tax.PriceMode, config.Tax.Rounding)
+	if err != nil {
+		return nil, err
+	}
+	options := service.TransactionOptions{
+		IdempotencyTTL: config.Checkout.IdempotencyTTL,
+		VoidWindow:     config.Checkout.VoidWindow,
+		Tax:            tax,
+	}
+	if appMetrics != nil {
+		options.Metrics = appMetrics
+	}
+	transactionService := service.NewTransactionService(transactionRepo, options)
 	transactionHandler := handler.NewTransactionHandler(transactionService)
 
-	return transactionHandler
+	return transactionHandler, nil
 }
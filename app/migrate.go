@@ -0,0 +1,96 @@
+package app
+
+import (
+	"category-crud/config"
+	"category-crud/db"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+)
+
+const migrateUsage = "usage: migrate up|down|status|to <version>"
+
+// Migrate runs the `migrate` subcommand against the configured database.
+// Errors are returned rather than fatal so the connection is closed, and
+// with it the migration lock released, before the process exits.
+func Migrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	config, err := config.Load()
+	if err != nil {
+		return err
+	}
+	conn, err := db.Open(*config)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	migrator, err := db.NewMigrator(conn)
+	if err != nil {
+		return err
+	}
+
+	// Ctrl-C rolls back the migration in progress
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		printMigrations("Applied", applied)
+		return err
+	case "down":
+		migration, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		if migration == nil {
+			fmt.Println("Nothing to roll back")
+			return nil
+		}
+		printMigrations("Rolled back", []db.Migration{*migration})
+	case "to":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		migrated, err := migrator.To(ctx, version)
+		printMigrations("Migrated", migrated)
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(os.Stdout, "%04d  %-32s %s\n", status.Version, status.Name, state)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+	return nil
+}
+
+func printMigrations(action string, migrations []db.Migration) {
+	if len(migrations) == 0 {
+		fmt.Println("Database schema is up to date")
+		return
+	}
+	for _, migration := range migrations {
+		fmt.Printf("%s %04d_%s\n", action, migration.Version, migration.Name)
+	}
+}
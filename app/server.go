@@ -0,0 +1,75 @@
+package app
+
+import (
+	"category-crud/config"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultReadTimeout       = 15 * time.Second
+	defaultReadHeaderTimeout = 5 * time.Second
+	defaultWriteTimeout      = 30 * time.Second
+	defaultIdleTimeout       = 60 * time.Second
+	defaultShutdownTimeout   = 20 * time.Second
+)
+
+func newServer(config config.Template, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              ":" + config.Server.Port,
+		Handler:           handler,
+		ReadTimeout:       orDefault(config.Server.ReadTimeout, defaultReadTimeout),
+		ReadHeaderTimeout: orDefault(config.Server.ReadHeaderTimeout, defaultReadHeaderTimeout),
+		WriteTimeout:      orDefault(config.Server.WriteTimeout, defaultWriteTimeout),
+		IdleTimeout:       orDefault(config.Server.IdleTimeout, defaultIdleTimeout),
+	}
+}
+
+// serve runs server until SIGINT or SIGTERM, then calls onShutdown and
+// waits up to shutdownTimeout for in-flight requests to finish.
+func serve(server *http.Server, shutdownTimeout time.Duration, onShutdown func()) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		return err
+	case <-ctx.Done():
+	}
+	// a second signal kills the process immediately
+	stop()
+
+	shutdownTimeout = orDefault(shutdownTimeout, defaultShutdownTimeout)
+	slog.Info("shutting down, draining connections", slog.String("timeout", shutdownTimeout.String()))
+	onShutdown()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	if err := <-serverErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	slog.Info("server stopped")
+	return nil
+}
+
+func orDefault(value, fallback time.Duration) time.Duration {
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
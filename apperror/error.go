@@ -0,0 +1,114 @@
+// Package apperror defines the typed errors shared by the repository,
+// service and handler layers. Handlers translate a Code into an HTTP
+// status; the lower layers never deal with HTTP themselves.
+package apperror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type Code string
+
+const (
+	CodeBadRequest        Code = "bad_request"
+	CodeUnauthorized      Code = "unauthorized"
+	CodeForbidden         Code = "forbidden"
+	CodeNotFound          Code = "not_found"
+	CodeValidation        Code = "validation_failed"
+	CodeUnprocessable     Code = "unprocessable"
+	CodeConflict          Code = "conflict"
+	CodeInsufficientStock Code = "insufficient_stock"
+	CodeTimeout           Code = "timeout"
+	CodeInternal          Code = "internal_error"
+)
+
+type Error struct {
+	Code    Code
+	Message string
+	// Details carries structured context such as the offending items.
+	Details interface{}
+	// Err is the underlying cause, if any.
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func New(code Code, message string, details interface{}) *Error {
+	return &Error{Code: code, Message: message, Details: details}
+}
+
+func BadRequest(message string) *Error {
+	return New(CodeBadRequest, message, nil)
+}
+
+func Unauthorized(message string) *Error {
+	return New(CodeUnauthorized, message, nil)
+}
+
+func Forbidden(message string) *Error {
+	return New(CodeForbidden, message, nil)
+}
+
+func NotFound(message string) *Error {
+	return New(CodeNotFound, message, nil)
+}
+
+func Validation(message string, details interface{}) *Error {
+	return New(CodeValidation, message, details)
+}
+
+func Unprocessable(message string, details interface{}) *Error {
+	return New(CodeUnprocessable, message, details)
+}
+
+func Conflict(message string, details interface{}) *Error {
+	return New(CodeConflict, message, details)
+}
+
+func InsufficientStock(message string, details interface{}) *Error {
+	return New(CodeInsufficientStock, message, details)
+}
+
+// Wrap attaches a cause to a typed error.
+func Wrap(err error, code Code, message string) *Error {
+	return &Error{Code: code, Message: message, Err: err}
+}
+
+// IsTimeout reports whether err comes from an expired deadline, either
+// directly or as a statement the database canceled (SQLSTATE 57014).
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var sqlErr interface{ SQLState() string }
+	return errors.As(err, &sqlErr) && sqlErr.SQLState() == "57014"
+}
+
+// As returns the typed error in err's chain, or nil.
+func As(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return nil
+}
+
+// CodeOf returns the code of a typed error, or CodeInternal for any
+// other error.
+func CodeOf(err error) Code {
+	if appErr := As(err); appErr != nil {
+		return appErr.Code
+	}
+	return CodeInternal
+}
@@ -0,0 +1,60 @@
+// Package auth authenticates callers by static API key or JWT and
+// describes who they are as a Principal.
+package auth
+
+import (
+	"category-crud/apperror"
+	"context"
+	"slices"
+)
+
+const (
+	RoleAdmin   = "admin"
+	RoleManager = "manager"
+	RoleCashier = "cashier"
+)
+
+// Roles lists every role a credential may carry.
+var Roles = []string{RoleAdmin, RoleManager, RoleCashier}
+
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+var (
+	ErrMissingCredentials = apperror.Unauthorized("authentication required")
+	ErrInvalidCredentials = apperror.Unauthorized("invalid or expired credentials")
+	ErrForbidden          = apperror.Forbidden("your role is not allowed to perform this operation")
+)
+
+// Principal is the authenticated caller. UserID is set for tokens issued
+// to a user account by the login endpoint and is zero otherwise.
+type Principal struct {
+	Subject string
+	Role    string
+	Method  string
+	UserID  int
+}
+
+// HasRole reports whether the principal has one of roles.
+func (p *Principal) HasRole(roles ...string) bool {
+	return p != nil && slices.Contains(roles, p.Role)
+}
+
+func ValidRole(role string) bool {
+	return slices.Contains(Roles, role)
+}
+
+type contextKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// FromContext returns the caller attached by the auth middleware, or nil
+// when authentication is disabled.
+func FromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(contextKey{}).(*Principal)
+	return principal
+}
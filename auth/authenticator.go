@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"category-crud/apperror"
+	"category-crud/config"
+	"category-crud/model"
+	"context"
+	"crypto/rsa"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims are the JWT claims the API understands on top of the registered
+// ones; sub identifies the caller.
+type Claims struct {
+	Role   string `json:"role"`
+	UserID int    `json:"uid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+type apiKey struct {
+	key       []byte
+	principal Principal
+}
+
+// Users loads user accounts, e.g. a repository.UserRepository. A missing
+// user is reported with an apperror.CodeNotFound error.
+type Users interface {
+	GetByID(ctx context.Context, id int) (*model.User, error)
+}
+
+type Authenticator struct {
+	apiKeys    []apiKey
+	hmacSecret []byte
+	rsaKey     *rsa.PublicKey
+	parser     *jwt.Parser
+	users      Users
+}
+
+// NewAuthenticator loads the API keys and JWT signing keys from config.
+// Tokens issued to a user account are checked against users on every
+// request.
+func NewAuthenticator(config config.Template, users Users) (*Authenticator, error) {
+	authenticator := &Authenticator{users: users}
+
+	seen := make(map[string]bool, len(config.Auth.APIKeys))
+	for _, key := range config.Auth.APIKeys {
+		if key.Key == "" {
+			return nil, fmt.Errorf("api key %q: key is empty", key.Name)
+		}
+		if !ValidRole(key.Role) {
+			return nil, fmt.Errorf("api key %q: unknown role %q", key.Name, key.Role)
+		}
+		if seen[key.Key] {
+			return nil, fmt.Errorf("api key %q: key is used more than once", key.Name)
+		}
+		seen[key.Key] = true
+
+		authenticator.apiKeys = append(authenticator.apiKeys, apiKey{
+			key:       []byte(key.Key),
+			principal: Principal{Subject: key.Name, Role: key.Role, Method: MethodAPIKey},
+		})
+	}
+
+	jwtConfig := config.Auth.JWT
+	var methods []string
+	if jwtConfig.HS256Secret != "" {
+		authenticator.hmacSecret = []byte(jwtConfig.HS256Secret)
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if jwtConfig.RS256PublicKey != "" {
+		key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(jwtConfig.RS256PublicKey))
+		if err != nil {
+			return nil, fmt.Errorf("parse rs256 public key: %w", err)
+		}
+		authenticator.rsaKey = key
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	if len(authenticator.apiKeys) == 0 && len(methods) == 0 {
+		return nil, errors.New("auth is enabled but no api keys or jwt keys are configured")
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+	}
+	if jwtConfig.Issuer != "" {
+		options = append(options, jwt.WithIssuer(jwtConfig.Issuer))
+	}
+	if jwtConfig.Audience != "" {
+		options = append(options, jwt.WithAudience(jwtConfig.Audience))
+	}
+	authenticator.parser = jwt.NewParser(options...)
+
+	return authenticator, nil
+}
+
+// AuthenticateAPIKey looks key up among the configured API keys.
+func (a *Authenticator) AuthenticateAPIKey(key string) (*Principal, error) {
+	for _, candidate := range a.apiKeys {
+		if subtle.ConstantTimeCompare(candidate.key, []byte(key)) == 1 {
+			principal := candidate.principal
+			return &principal, nil
+		}
+	}
+	return nil, ErrInvalidCredentials
+}
+
+// AuthenticateToken verifies a signed JWT and returns its subject and role.
+// A token issued to a user account is only accepted while the user is
+// active and still has the role the token was issued with, so deactivating,
+// deleting or demoting a user revokes their tokens at once.
+func (a *Authenticator) AuthenticateToken(ctx context.Context, token string) (*Principal, error) {
+	if a.hmacSecret == nil && a.rsaKey == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	var claims Claims
+	if _, err := a.parser.ParseWithClaims(token, &claims, a.verificationKey); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if claims.Subject == "" || !ValidRole(claims.Role) {
+		return nil, ErrInvalidCredentials
+	}
+	if claims.UserID != 0 {
+		if err := a.checkUser(ctx, claims.UserID, claims.Role); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Principal{
+		Subject: claims.Subject,
+		Role:    claims.Role,
+		Method:  MethodJWT,
+		UserID:  claims.UserID,
+	}, nil
+}
+
+// checkUser rejects tokens of users that were deleted, deactivated or
+// given another role since the token was issued.
+func (a *Authenticator) checkUser(ctx context.Context, userID int, role string) error {
+	user, err := a.users.GetByID(ctx, userID)
+	if apperror.CodeOf(err) == apperror.CodeNotFound {
+		return ErrInvalidCredentials
+	}
+	if err != nil {
+		return err
+	}
+	if !user.Active || user.Role != role {
+		return ErrInvalidCredentials
+	}
+	return nil
+}
+
+func (a *Authenticator) verificationKey(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return a.hmacSecret, nil
+	case jwt.SigningMethodRS256.Alg():
+		return a.rsaKey, nil
+	default:
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+}
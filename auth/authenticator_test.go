@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"category-crud/apperror"
+	"category-crud/config"
+	"category-crud/model"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "test-secret-of-at-least-32-bytes!"
+
+// stubUsers serves users from a map; err, when set, fails every lookup.
+type stubUsers struct {
+	users map[int]model.User
+	err   error
+}
+
+func (s *stubUsers) GetByID(ctx context.Context, id int) (*model.User, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	user, ok := s.users[id]
+	if !ok {
+		return nil, apperror.NotFound("user not found")
+	}
+	return &user, nil
+}
+
+func testConfig() config.Template {
+	var cfg config.Template
+	cfg.Auth.JWT.HS256Secret = testSecret
+	return cfg
+}
+
+func TestAuthenticateTokenChecksUser(t *testing.T) {
+	users := &stubUsers{users: map[int]model.User{
+		1: {ID: 1, Role: RoleManager, Active: true},
+		2: {ID: 2, Role: RoleCashier, Active: false},
+		3: {ID: 3, Role: RoleCashier, Active: true},
+	}}
+	authenticator, err := NewAuthenticator(testConfig(), users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer, err := NewIssuer(testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		userID  int
+		role    string
+		wantErr bool
+	}{
+		{"active user", 1, RoleManager, false},
+		{"deactivated user", 2, RoleCashier, true},
+		{"demoted user", 3, RoleManager, true},
+		{"promoted user", 3, RoleAdmin, true},
+		{"deleted user", 4, RoleCashier, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _, err := issuer.Issue(tt.userID, tt.role)
+			if err != nil {
+				t.Fatal(err)
+			}
+			principal, err := authenticator.AuthenticateToken(context.Background(), token)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidCredentials) {
+					t.Errorf("want ErrInvalidCredentials, got %+v, %v", principal, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AuthenticateToken: %v", err)
+			}
+			if principal.UserID != tt.userID || principal.Role != tt.role {
+				t.Errorf("got %+v", principal)
+			}
+		})
+	}
+}
+
+func TestAuthenticateTokenWithoutUser(t *testing.T) {
+	// tokens minted elsewhere carry no uid and are trusted as signed
+	authenticator, err := NewAuthenticator(testConfig(), &stubUsers{err: errors.New("must not be called")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		Role: RoleAdmin,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "backoffice",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	principal, err := authenticator.AuthenticateToken(context.Background(), token)
+	if err != nil {
+		t.Fatalf("AuthenticateToken: %v", err)
+	}
+	if principal.Subject != "backoffice" || principal.Role != RoleAdmin || principal.UserID != 0 {
+		t.Errorf("got %+v", principal)
+	}
+}
+
+func TestAuthenticateTokenLookupFailure(t *testing.T) {
+	lookupErr := errors.New("database is down")
+	authenticator, err := NewAuthenticator(testConfig(), &stubUsers{err: lookupErr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer, err := NewIssuer(testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _, err := issuer.Issue(1, RoleCashier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// an outage is a server error, not a reason to log the user out
+	if _, err := authenticator.AuthenticateToken(context.Background(), token); !errors.Is(err, lookupErr) {
+		t.Errorf("want the lookup error, got %v", err)
+	}
+}
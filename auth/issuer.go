@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"category-crud/apperror"
+	"category-crud/config"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const defaultTokenTTL = 8 * time.Hour
+
+var ErrTokenSigningDisabled = apperror.New(apperror.CodeInternal, "login is not available: no jwt signing key is configured", nil)
+
+// Issuer signs tokens for user accounts with the HS256 secret, or the
+// RS256 private key when no secret is configured.
+type Issuer struct {
+	method   jwt.SigningMethod
+	key      interface{}
+	issuer   string
+	audience string
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+func NewIssuer(config config.Template) (*Issuer, error) {
+	jwtConfig := config.Auth.JWT
+	issuer := &Issuer{
+		issuer:   jwtConfig.Issuer,
+		audience: jwtConfig.Audience,
+		ttl:      jwtConfig.TokenTTL,
+		now:      time.Now,
+	}
+	if issuer.ttl <= 0 {
+		issuer.ttl = defaultTokenTTL
+	}
+
+	switch {
+	case jwtConfig.HS256Secret != "":
+		issuer.method = jwt.SigningMethodHS256
+		issuer.key = []byte(jwtConfig.HS256Secret)
+	case jwtConfig.RS256PrivateKey != "":
+		key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(jwtConfig.RS256PrivateKey))
+		if err != nil {
+			return nil, fmt.Errorf("parse rs256 private key: %w", err)
+		}
+		issuer.method = jwt.SigningMethodRS256
+		issuer.key = key
+	}
+
+	return issuer, nil
+}
+
+// Issue returns a signed token for the user and when it expires.
+func (i *Issuer) Issue(userID int, role string) (string, time.Time, error) {
+	if i.key == nil {
+		return "", time.Time{}, ErrTokenSigningDisabled
+	}
+
+	now := i.now()
+	expiresAt := now.Add(i.ttl)
+	claims := Claims{
+		Role:   role,
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.Itoa(userID),
+			Issuer:    i.issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+	if i.audience != "" {
+		claims.Audience = jwt.ClaimStrings{i.audience}
+	}
+
+	token, err := jwt.NewWithClaims(i.method, claims).SignedString(i.key)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
@@ -1,14 +1,27 @@
 package config
 
+import "time"
+
 type Template struct {
 	App struct {
-		Env   string `mapstructure:"env"`
-		Debug bool   `mapstructure:"debug"`
+		Env           string `mapstructure:"env"`
+		Debug         bool   `mapstructure:"debug"`
+		MigrateOnBoot bool   `mapstructure:"migrate_on_boot"`
+		// LogLevel is debug, info, warn or error.
+		LogLevel string `mapstructure:"log_level"`
+		// LogFormat is json or text.
+		LogFormat string `mapstructure:"log_format"`
 	} `mapstructure:"app"`
 	Server struct {
-		Port string `mapstructure:"port"`
+		Port              string        `mapstructure:"port"`
+		ReadTimeout       time.Duration `mapstructure:"read_timeout"`
+		ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"`
+		WriteTimeout      time.Duration `mapstructure:"write_timeout"`
+		IdleTimeout       time.Duration `mapstructure:"idle_timeout"`
+		ShutdownTimeout   time.Duration `mapstructure:"shutdown_timeout"`
 	} `mapstructure:"server"`
 	DB struct {
+		Driver           string `mapstructure:"driver"`
 		Host             string `mapstructure:"host"`
 		Port             int    `mapstructure:"port"`
 		User             string `mapstructure:"user"`
@@ -16,5 +29,46 @@ type Template struct {
 		ConnectionString string `mapstructure:"connection_string"`
 		MaxOpenConns     int    `mapstructure:"max_open_connections"`
 		MaxIdleConns     int    `mapstructure:"max_idle_connections:"`
+		// QueryTimeout bounds every repository call; 0 disables it.
+		QueryTimeout time.Duration `mapstructure:"query_timeout"`
 	} `mapstructure:"db"`
+	Auth struct {
+		Enabled bool     `mapstructure:"enabled"`
+		APIKeys []APIKey `mapstructure:"api_keys"`
+		JWT     struct {
+			// HS256Secret enables HS256 tokens signed with a shared secret.
+			HS256Secret string `mapstructure:"hs256_secret"`
+			// RS256PublicKey is a PEM encoded key that enables RS256 tokens.
+			RS256PublicKey string `mapstructure:"rs256_public_key"`
+			// RS256PrivateKey signs login tokens when no HS256 secret is set.
+			RS256PrivateKey string        `mapstructure:"rs256_private_key"`
+			Issuer          string        `mapstructure:"issuer"`
+			Audience        string        `mapstructure:"audience"`
+			TokenTTL        time.Duration `mapstructure:"token_ttl"`
+		} `mapstructure:"jwt"`
+	} `mapstructure:"auth"`
+	Metrics struct {
+		Enabled bool `mapstructure:"enabled"`
+	} `mapstructure:"metrics"`
+	Checkout struct {
+		IdempotencyTTL time.Duration `mapstructure:"idempotency_ttl"`
+		VoidWindow     time.Duration `mapstructure:"void_window"`
+	} `mapstructure:"checkout"`
+	Tax struct {
+		// PriceMode is inclusive (prices contain the tax) or exclusive.
+		PriceMode string `mapstructure:"price_mode"`
+		// Rounding is half_up, down or up, applied to the tax of each line.
+		Rounding string `mapstructure:"rounding"`
+	} `mapstructure:"tax"`
+	Store struct {
+		// Currency is the ISO 4217 code every amount is kept in.
+		Currency string `mapstructure:"currency"`
+	} `mapstructure:"store"`
+}
+
+// APIKey is a static credential, typically used by other services.
+type APIKey struct {
+	Name string `mapstructure:"name"`
+	Key  string `mapstructure:"key"`
+	Role string `mapstructure:"role"`
 }
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// snapshotBatchSize bounds how many transaction_details rows one backfill
+// statement locks.
+const snapshotBatchSize = 1000
+
+// backfillSnapshotsQuery fills unit_price and product_name on the next
+// batch of details after id $1 written before checkout recorded them, and
+// returns how many it filled and the last id it reached. The unit price is
+// derived from what was charged, zero for a line of no quantity; the name
+// is the product's current one, the best that is left to know, or empty
+// when the product was deleted outright before soft delete existed.
+const backfillSnapshotsQuery = `
+WITH batch AS (
+    SELECT td.id, p.name
+    FROM transaction_details td
+    LEFT JOIN products p ON p.id = td.product_id
+    WHERE td.id > $1
+      AND (td.unit_price IS NULL OR td.product_name IS NULL)
+    ORDER BY td.id
+    LIMIT $2
+), filled AS (
+    UPDATE transaction_details td
+    SET unit_price   = COALESCE(td.unit_price, td.subtotal / NULLIF(td.quantity, 0), 0),
+        product_name = COALESCE(td.product_name, batch.name, '')
+    FROM batch
+    WHERE td.id = batch.id
+    RETURNING td.id
+)
+SELECT COUNT(*), COALESCE(MAX(id), 0) FROM filled`
+
+// BackfillSnapshots fills the price and name snapshots of old transaction
+// details in batches, walking them in id order, and returns the number of
+// rows updated. It can be stopped and rerun safely.
+func BackfillSnapshots(ctx context.Context, db *sql.DB) (int64, error) {
+	var total, lastID int64
+	for {
+		var rows int64
+		err := db.QueryRowContext(ctx, backfillSnapshotsQuery, lastID, snapshotBatchSize).Scan(&rows, &lastID)
+		if err != nil {
+			return total, err
+		}
+		total += rows
+		if rows < snapshotBatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package db_test
+
+import (
+	"category-crud/db"
+	"category-crud/internal/testdb"
+	"context"
+	"fmt"
+	"testing"
+)
+
+// TestBackfillSnapshotsOrphans puts more details of hard-deleted products
+// than fit in a batch ahead of ordinary ones, so a backfill that keeps
+// re-selecting rows it cannot fill would stop before reaching the rest.
+func TestBackfillSnapshotsOrphans(t *testing.T) {
+	conn, _ := testdb.Open(t)
+	ctx := context.Background()
+
+	orphans := db.SnapshotBatchSize + 500
+	statements := []string{
+		// products could be deleted outright before soft delete existed
+		`ALTER TABLE transaction_details DROP CONSTRAINT transaction_details_product_id_fkey`,
+		`INSERT INTO products (id, name, price, stock) VALUES (1, 'Kopi', 8000, 10)`,
+		`INSERT INTO transactions (id, total_amount) VALUES (1, 0)`,
+		fmt.Sprintf(`INSERT INTO transaction_details (transaction_id, product_id, quantity, subtotal, total_amount)
+			SELECT 1, 999, 2, 10000, 10000 FROM generate_series(1, %d)`, orphans),
+		`INSERT INTO transaction_details (transaction_id, product_id, quantity, subtotal, total_amount)
+			VALUES (1, 1, 3, 24000, 24000), (1, 1, 0, 0, 0)`,
+		// a detail snapshotted at checkout keeps its values
+		`INSERT INTO transaction_details (transaction_id, product_id, product_name, unit_price, quantity, subtotal, total_amount)
+			VALUES (1, 1, 'Kopi Lama', 7000, 1, 7000, 7000)`,
+	}
+	for _, statement := range statements {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+
+	updated, err := db.BackfillSnapshots(ctx, conn)
+	if err != nil {
+		t.Fatalf("BackfillSnapshots: %v", err)
+	}
+	if want := int64(orphans + 2); updated != want {
+		t.Errorf("want %d details updated, got %d", want, updated)
+	}
+
+	var missing int
+	err = conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM transaction_details WHERE unit_price IS NULL OR product_name IS NULL`).Scan(&missing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if missing != 0 {
+		t.Errorf("%d details left without snapshots", missing)
+	}
+
+	rows, err := conn.QueryContext(ctx, `SELECT DISTINCT product_id, quantity, product_name, unit_price FROM transaction_details ORDER BY product_id, quantity`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	type snapshot struct {
+		productID, quantity int
+		name                string
+		unitPrice           int64
+	}
+	var got []snapshot
+	for rows.Next() {
+		var row snapshot
+		if err := rows.Scan(&row.productID, &row.quantity, &row.name, &row.unitPrice); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, row)
+	}
+	want := []snapshot{
+		{1, 0, "Kopi", 0},
+		{1, 1, "Kopi Lama", 7000},
+		{1, 3, "Kopi", 8000},
+		{999, 2, "", 5000},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	// a rerun has nothing left to do
+	if updated, err := db.BackfillSnapshots(ctx, conn); err != nil || updated != 0 {
+		t.Errorf("rerun: want 0 updated, got %d, %v", updated, err)
+	}
+}
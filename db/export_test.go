@@ -0,0 +1,5 @@
+package db
+
+// SnapshotBatchSize exposes the backfill batch size to the db_test
+// package, which cannot import this one's unexported names.
+const SnapshotBatchSize = snapshotBatchSize
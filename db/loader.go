@@ -2,31 +2,71 @@ package db
 
 import (
 	"category-crud/config"
+	"context"
 	"database/sql"
-	"log"
+	"log/slog"
 
 	"github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
 	_ "github.com/lib/pq"
 )
 
+const dialect = "postgres"
+
 func Configure(config config.Template) (*sql.DB, *goqu.Database, error) {
-	const dialect = "postgres"
-	db, err := sql.Open(dialect, config.DB.ConnectionString)
+	db, err := Open(config)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	if err := db.Ping(); err != nil {
-		return nil, nil, err
+	if config.App.MigrateOnBoot {
+		if err := migrateOnBoot(db); err != nil {
+			db.Close()
+			return nil, nil, err
+		}
 	}
 
-	if config.App.Debug {
-		log.Default().Println("Connected to database")
+	return db, goqu.New(dialect, db), nil
+
+}
+
+// Open connects to the database without touching the schema.
+func Open(config config.Template) (*sql.DB, error) {
+	db, err := sql.Open(dialect, config.DB.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
 	}
 
+	slog.Debug("connected to database")
+
 	db.SetMaxOpenConns(config.DB.MaxOpenConns)
 	db.SetMaxIdleConns(config.DB.MaxIdleConns)
 
-	return db, goqu.New(dialect, db), nil
+	return db, nil
+}
+
+func migrateOnBoot(db *sql.DB) error {
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(context.Background())
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range applied {
+		slog.Info("applied migration", slog.Int64("version", migration.Version), slog.String("name", migration.Name))
+	}
+	if len(applied) == 0 {
+		slog.Debug("database schema is up to date")
+	}
 
+	return nil
 }
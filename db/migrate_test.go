@@ -0,0 +1,120 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles)
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+
+	for i, migration := range migrations {
+		if want := int64(i + 1); migration.Version != want {
+			t.Fatalf("migration %d_%s: want version %d, versions must start at 1 without gaps", migration.Version, migration.Name, want)
+		}
+		if migration.Name == "" {
+			t.Errorf("migration %d has no name", migration.Version)
+		}
+		if strings.TrimSpace(migration.Up) == "" {
+			t.Errorf("migration %d_%s has an empty up script", migration.Version, migration.Name)
+		}
+		if strings.TrimSpace(migration.Down) == "" {
+			t.Errorf("migration %d_%s has no down script", migration.Version, migration.Name)
+		}
+	}
+
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, ".up.sql") && !strings.HasSuffix(name, ".down.sql") {
+			t.Errorf("migrations/%s is neither an up nor a down script and would be ignored", name)
+		}
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	file := func(content string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(content)}
+	}
+
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []int64
+		wantErr string
+	}{
+		{
+			name: "ordered by version",
+			files: fstest.MapFS{
+				"migrations/0002_b.up.sql":   file("SELECT 2"),
+				"migrations/0002_b.down.sql": file("SELECT -2"),
+				"migrations/0001_a.up.sql":   file("SELECT 1"),
+				"migrations/0001_a.down.sql": file("SELECT -1"),
+				"migrations/README.md":       file("ignored"),
+			},
+			want: []int64{1, 2},
+		},
+		{
+			name: "missing name",
+			files: fstest.MapFS{
+				"migrations/0001.up.sql": file("SELECT 1"),
+			},
+			wantErr: "expected <version>_<name>",
+		},
+		{
+			name: "invalid version",
+			files: fstest.MapFS{
+				"migrations/v1_a.up.sql": file("SELECT 1"),
+			},
+			wantErr: "invalid version",
+		},
+		{
+			name: "version used twice",
+			files: fstest.MapFS{
+				"migrations/0001_a.up.sql": file("SELECT 1"),
+				"migrations/0001_b.up.sql": file("SELECT 1"),
+			},
+			wantErr: "migration version 1 used by",
+		},
+		{
+			name: "down without up",
+			files: fstest.MapFS{
+				"migrations/0001_a.down.sql": file("SELECT -1"),
+			},
+			wantErr: "has no up script",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := loadMigrations(tt.files)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("want error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadMigrations: %v", err)
+			}
+			if len(migrations) != len(tt.want) {
+				t.Fatalf("want %d migrations, got %d", len(tt.want), len(migrations))
+			}
+			for i, version := range tt.want {
+				if migrations[i].Version != version {
+					t.Errorf("migrations[%d]: want version %d, got %d", i, version, migrations[i].Version)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS products (
+    id    SERIAL PRIMARY KEY,
+    name  VARCHAR(255) NOT NULL,
+    price INTEGER      NOT NULL DEFAULT 0,
+    stock INTEGER      NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS product_categories (
+    product_id  INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    category_id INTEGER NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    PRIMARY KEY (product_id, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_categories_category_id ON product_categories (category_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS refund_details;
+DROP TABLE IF EXISTS refunds;
+ALTER TABLE transactions DROP COLUMN IF EXISTS voided_at;
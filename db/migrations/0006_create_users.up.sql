@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS users (
+    id            SERIAL PRIMARY KEY,
+    username      VARCHAR(64)  NOT NULL UNIQUE,
+    name          VARCHAR(255) NOT NULL DEFAULT '',
+    password_hash TEXT         NOT NULL,
+    role          VARCHAR(16)  NOT NULL CHECK (role IN ('admin', 'manager', 'cashier')),
+    active        BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS cashier_id INTEGER REFERENCES users (id);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_cashier_id ON transactions (cashier_id);
@@ -0,0 +1,6 @@
+-- soft-deleted rows become live again
+DROP INDEX IF EXISTS idx_products_active;
+DROP INDEX IF EXISTS idx_categories_active;
+
+ALTER TABLE products DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE categories DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,5 @@
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_categories_active ON categories (id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_products_active ON products (id) WHERE deleted_at IS NULL;
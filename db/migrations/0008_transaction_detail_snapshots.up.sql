@@ -0,0 +1,4 @@
+-- filled at checkout; rows written before this migration are filled by
+-- the `backfill snapshots` command
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS unit_price INTEGER;
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS product_name VARCHAR(255);
@@ -0,0 +1,7 @@
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS promotion_id;
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS discount_amount;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS promotion_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS discount_amount;
+
+DROP TABLE IF EXISTS promotions;
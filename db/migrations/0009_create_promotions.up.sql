@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS promotions (
+    id           SERIAL PRIMARY KEY,
+    name         VARCHAR(255) NOT NULL,
+    type         VARCHAR(16)  NOT NULL CHECK (type IN ('percentage', 'fixed', 'buy_x_get_y', 'basket')),
+    value        INTEGER      NOT NULL DEFAULT 0 CHECK (value >= 0),
+    product_id   INTEGER REFERENCES products (id),
+    category_id  INTEGER REFERENCES categories (id),
+    buy_quantity INTEGER      NOT NULL DEFAULT 0 CHECK (buy_quantity >= 0),
+    get_quantity INTEGER      NOT NULL DEFAULT 0 CHECK (get_quantity >= 0),
+    min_subtotal INTEGER      NOT NULL DEFAULT 0 CHECK (min_subtotal >= 0),
+    starts_at    TIMESTAMPTZ,
+    ends_at      TIMESTAMPTZ,
+    active       BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    deleted_at   TIMESTAMPTZ,
+    CHECK (product_id IS NULL OR category_id IS NULL),
+    CHECK (starts_at IS NULL OR ends_at IS NULL OR starts_at < ends_at)
+);
+
+-- checkout reads the live promotions
+CREATE INDEX IF NOT EXISTS idx_promotions_active ON promotions (id) WHERE deleted_at IS NULL;
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS discount_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS promotion_id INTEGER REFERENCES promotions (id);
+
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS discount_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS promotion_id INTEGER REFERENCES promotions (id);
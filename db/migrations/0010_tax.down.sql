@@ -0,0 +1,15 @@
+ALTER TABLE refund_details DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE refunds DROP COLUMN IF EXISTS tax_amount;
+
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS total_amount;
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS tax_rate_bps;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS tax_inclusive;
+ALTER TABLE transactions DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS subtotal_amount;
+
+ALTER TABLE categories DROP COLUMN IF EXISTS tax_class_id;
+ALTER TABLE products DROP COLUMN IF EXISTS tax_class_id;
+
+DROP TABLE IF EXISTS tax_classes;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS tax_classes (
+    id         SERIAL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL UNIQUE,
+    -- basis points: 1100 is 11%
+    rate_bps   INTEGER      NOT NULL CHECK (rate_bps BETWEEN 0 AND 10000),
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tax_class_id INTEGER REFERENCES tax_classes (id);
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS tax_class_id INTEGER REFERENCES tax_classes (id);
+
+-- sales made before taxes were tracked carry no tax
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS subtotal_amount INTEGER;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE transactions SET subtotal_amount = total_amount + discount_amount WHERE subtotal_amount IS NULL;
+ALTER TABLE transactions ALTER COLUMN subtotal_amount SET NOT NULL;
+
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS tax_rate_bps INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS tax_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS total_amount INTEGER;
+UPDATE transaction_details SET total_amount = subtotal - discount_amount WHERE total_amount IS NULL;
+ALTER TABLE transaction_details ALTER COLUMN total_amount SET NOT NULL;
+
+ALTER TABLE refunds ADD COLUMN IF NOT EXISTS tax_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE refund_details ADD COLUMN IF NOT EXISTS tax_amount INTEGER NOT NULL DEFAULT 0;
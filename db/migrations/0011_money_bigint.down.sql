@@ -0,0 +1,27 @@
+-- fails if an amount no longer fits in INTEGER
+ALTER TABLE promotions
+    ALTER COLUMN min_subtotal TYPE INTEGER,
+    ALTER COLUMN value TYPE INTEGER;
+
+ALTER TABLE refund_details
+    ALTER COLUMN tax_amount TYPE INTEGER,
+    ALTER COLUMN amount TYPE INTEGER;
+
+ALTER TABLE refunds
+    ALTER COLUMN tax_amount TYPE INTEGER,
+    ALTER COLUMN total_amount TYPE INTEGER;
+
+ALTER TABLE transaction_details
+    ALTER COLUMN total_amount TYPE INTEGER,
+    ALTER COLUMN tax_amount TYPE INTEGER,
+    ALTER COLUMN discount_amount TYPE INTEGER,
+    ALTER COLUMN subtotal TYPE INTEGER,
+    ALTER COLUMN unit_price TYPE INTEGER;
+
+ALTER TABLE transactions
+    ALTER COLUMN total_amount TYPE INTEGER,
+    ALTER COLUMN tax_amount TYPE INTEGER,
+    ALTER COLUMN discount_amount TYPE INTEGER,
+    ALTER COLUMN subtotal_amount TYPE INTEGER;
+
+ALTER TABLE products ALTER COLUMN price TYPE INTEGER;
@@ -0,0 +1,27 @@
+-- amounts are int64 minor units of the store currency
+ALTER TABLE products ALTER COLUMN price TYPE BIGINT;
+
+ALTER TABLE transactions
+    ALTER COLUMN subtotal_amount TYPE BIGINT,
+    ALTER COLUMN discount_amount TYPE BIGINT,
+    ALTER COLUMN tax_amount TYPE BIGINT,
+    ALTER COLUMN total_amount TYPE BIGINT;
+
+ALTER TABLE transaction_details
+    ALTER COLUMN unit_price TYPE BIGINT,
+    ALTER COLUMN subtotal TYPE BIGINT,
+    ALTER COLUMN discount_amount TYPE BIGINT,
+    ALTER COLUMN tax_amount TYPE BIGINT,
+    ALTER COLUMN total_amount TYPE BIGINT;
+
+ALTER TABLE refunds
+    ALTER COLUMN total_amount TYPE BIGINT,
+    ALTER COLUMN tax_amount TYPE BIGINT;
+
+ALTER TABLE refund_details
+    ALTER COLUMN amount TYPE BIGINT,
+    ALTER COLUMN tax_amount TYPE BIGINT;
+
+ALTER TABLE promotions
+    ALTER COLUMN value TYPE BIGINT,
+    ALTER COLUMN min_subtotal TYPE BIGINT;
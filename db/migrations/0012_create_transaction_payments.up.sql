@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS transaction_payments (
+    id             SERIAL PRIMARY KEY,
+    transaction_id INTEGER      NOT NULL REFERENCES transactions (id) ON DELETE CASCADE,
+    method         VARCHAR(16)  NOT NULL CHECK (method IN ('cash', 'card', 'qris', 'ewallet')),
+    -- amount is the part of the total paid: tendered minus change
+    amount         BIGINT       NOT NULL CHECK (amount >= 0),
+    tendered       BIGINT       NOT NULL CHECK (tendered > 0),
+    change_amount  BIGINT       NOT NULL DEFAULT 0 CHECK (change_amount >= 0),
+    reference      VARCHAR(255),
+    CHECK (amount + change_amount = tendered)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_payments_transaction_id ON transaction_payments (transaction_id);
@@ -0,0 +1,3 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS shift_id;
+
+DROP TABLE IF EXISTS shifts;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS shifts (
+    id            SERIAL PRIMARY KEY,
+    cashier_id    INTEGER     REFERENCES users (id),
+    opening_float BIGINT      NOT NULL CHECK (opening_float >= 0),
+    opened_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    closed_at     TIMESTAMPTZ,
+    note          TEXT        NOT NULL DEFAULT '',
+    -- the reconciliation, set when the shift closes
+    cash_sales    BIGINT,
+    cash_refunds  BIGINT,
+    expected_cash BIGINT,
+    counted_cash  BIGINT CHECK (counted_cash >= 0),
+    variance      BIGINT
+);
+
+-- one open shift per cashier; sales without a cashier share one drawer
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shifts_open ON shifts ((COALESCE(cashier_id, 0))) WHERE closed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_shifts_opened_at ON shifts (opened_at);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS shift_id INTEGER REFERENCES shifts (id);
+CREATE INDEX IF NOT EXISTS idx_transactions_shift_id ON transactions (shift_id);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_idempotency_keys_scope;
+
+-- a key may have been used by several cashiers; keep the newest
+DELETE FROM idempotency_keys a USING idempotency_keys b WHERE a.key = b.key AND a.id < b.id;
+
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS id;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS cashier_id;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (key);
@@ -0,0 +1,9 @@
+-- Keys are scoped to the cashier who sent them, so two cashiers using the
+-- same key never see each other's sale. Sales without a cashier share one
+-- scope, as they share one shift.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS cashier_id INTEGER REFERENCES users (id) ON DELETE CASCADE;
+UPDATE idempotency_keys k SET cashier_id = t.cashier_id FROM transactions t WHERE t.id = k.transaction_id;
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS id BIGSERIAL PRIMARY KEY;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_idempotency_keys_scope ON idempotency_keys ((COALESCE(cashier_id, 0)), key);
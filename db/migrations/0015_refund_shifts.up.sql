@@ -0,0 +1,8 @@
+-- Cash for a refund comes out of the drawer of the shift open for whoever
+-- makes it, which need not be the shift the sale was rung up in. Earlier
+-- refunds stay charged to their sale's shift, as they were reconciled.
+ALTER TABLE refunds ADD COLUMN IF NOT EXISTS cashier_id INTEGER REFERENCES users (id);
+ALTER TABLE refunds ADD COLUMN IF NOT EXISTS shift_id INTEGER REFERENCES shifts (id);
+UPDATE refunds r SET shift_id = t.shift_id FROM transactions t WHERE t.id = r.transaction_id AND r.shift_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_refunds_shift_id ON refunds (shift_id);
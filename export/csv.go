@@ -0,0 +1,113 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	csvTimeLayout = "2006-01-02 15:04:05"
+	// csvFlushRows is how many rows are buffered before they are sent.
+	csvFlushRows = 100
+)
+
+// utf8BOM makes Excel read the file as UTF-8 instead of the system code
+// page.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+type csvWriter struct {
+	out      io.Writer
+	csv      *csv.Writer
+	started  bool
+	sheets   int
+	buffered int
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{out: w, csv: csv.NewWriter(w)}
+}
+
+// Sheet separates every table after the first with a blank line and a
+// title row, as CSV has no sheets.
+func (w *csvWriter) Sheet(name string, columns []Column) error {
+	if !w.started {
+		if _, err := w.out.Write(utf8BOM); err != nil {
+			return err
+		}
+		w.started = true
+	}
+	if w.sheets > 0 {
+		if err := w.csv.Write(nil); err != nil {
+			return err
+		}
+		if err := w.csv.Write([]string{name}); err != nil {
+			return err
+		}
+	}
+	w.sheets++
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Title
+	}
+	return w.csv.Write(header)
+}
+
+func (w *csvWriter) Row(values ...any) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = formatCSV(cellValue(value))
+	}
+	if err := w.csv.Write(record); err != nil {
+		return err
+	}
+
+	w.buffered++
+	if w.buffered >= csvFlushRows {
+		w.buffered = 0
+		w.csv.Flush()
+		return w.csv.Error()
+	}
+	return nil
+}
+
+func (w *csvWriter) Close() error {
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+// formatCSV writes plain numbers without grouping, amounts with the
+// decimals of their currency and times without a zone offset so
+// spreadsheets recognise them.
+func formatCSV(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return escapeFormula(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	case Amount:
+		return v.String()
+	case time.Time:
+		return v.Format(csvTimeLayout)
+	}
+	return ""
+}
+
+// escapeFormula stops spreadsheets from running text that looks like a
+// formula, such as a product named "=HYPERLINK(...)", by prefixing it with
+// an apostrophe. Numbers are not text and are written as they are.
+func escapeFormula(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
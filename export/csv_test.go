@@ -0,0 +1,116 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCSVWriter(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	at := time.Date(2026, 3, 8, 9, 5, 7, 0, jakarta)
+	quantity := 3
+	var noQuantity *int
+	var noTime *time.Time
+
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := []func() error{
+		func() error {
+			return w.Sheet("Transactions", []Column{{"Name", Text}, {"Qty", Integer}, {"Total", Money}, {"Avg", Decimal}, {"At", Time}})
+		},
+		func() error { return w.Row("Kopi, susu", &quantity, Amount{Minor: 2400050, Exponent: 2}, 12.5, at) },
+		func() error {
+			return w.Row("Teh \"manis\"", noQuantity, Amount{Minor: -5, Exponent: 2}, float64(0), noTime)
+		},
+		func() error { return w.Row("=HYPERLINK(\"http://x\")", 1, Amount{Minor: 15000}, 1.25, &at) },
+		func() error { return w.Sheet("Summary", []Column{{"Metric", Text}, {"Value", Number}}) },
+		func() error { return w.Row("+1", -2) },
+		func() error { return w.Close() },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := buf.Bytes()
+	if !bytes.HasPrefix(out, utf8BOM) {
+		t.Fatalf("missing byte order mark: %q", out)
+	}
+	reader := csv.NewReader(bytes.NewReader(out[len(utf8BOM):]))
+	reader.FieldsPerRecord = -1
+	got, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"Name", "Qty", "Total", "Avg", "At"},
+		{"Kopi, susu", "3", "24000.50", "12.50", "2026-03-08 09:05:07"},
+		{"Teh \"manis\"", "", "-0.05", "0.00", ""},
+		{"'=HYPERLINK(\"http://x\")", "1", "15000", "1.25", "2026-03-08 09:05:07"},
+		// the blank separator line is skipped by the reader
+		{"Summary"},
+		{"Metric", "Value"},
+		{"'+1", "-2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want\n%q\ngot\n%q", want, got)
+	}
+	if !strings.Contains(string(out), "\n\nSummary\n") {
+		t.Errorf("want a blank line before the second table, got %q", out)
+	}
+}
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"", ""},
+		{"Kopi", "Kopi"},
+		{"=1+1", "'=1+1"},
+		{"+62 812", "'+62 812"},
+		{"-5", "'-5"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tcmd", "'\tcmd"},
+		{"\rcmd", "'\rcmd"},
+		{"a=b", "a=b"},
+		{"'quoted", "'quoted"},
+	}
+	for _, tt := range tests {
+		if got := escapeFormula(tt.text); got != tt.want {
+			t.Errorf("escapeFormula(%q): want %q, got %q", tt.text, tt.want, got)
+		}
+	}
+}
+
+func TestAmount(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		want   string
+		float  float64
+	}{
+		{Amount{Minor: 15000}, "15000", 15000},
+		{Amount{Minor: -15000}, "-15000", -15000},
+		{Amount{Minor: 1250, Exponent: 2}, "12.50", 12.5},
+		{Amount{Minor: 5, Exponent: 2}, "0.05", 0.05},
+		{Amount{Minor: -5, Exponent: 2}, "-0.05", -0.05},
+		{Amount{Minor: 1, Exponent: 3}, "0.001", 0.001},
+		{Amount{Minor: 9223372036854775807, Exponent: 2}, "92233720368547758.07", 92233720368547758.07},
+	}
+	for _, tt := range tests {
+		if got := tt.amount.String(); got != tt.want {
+			t.Errorf("%+v: want %s, got %s", tt.amount, tt.want, got)
+		}
+		if got := tt.amount.Float(); got != tt.float {
+			t.Errorf("%+v: want %v, got %v", tt.amount, tt.float, got)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Package export writes tables as CSV or XLSX for spreadsheet import.
+package export
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+const (
+	ContentTypeCSV  = "text/csv"
+	ContentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// ParseFormat accepts json, csv and xlsx.
+func ParseFormat(raw string) (Format, error) {
+	switch format := Format(raw); format {
+	case FormatJSON, FormatCSV, FormatXLSX:
+		return format, nil
+	}
+	return "", fmt.Errorf("unsupported format %q, use json, csv or xlsx", raw)
+}
+
+// ContentType is the media type of a file in this format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return ContentTypeCSV + "; charset=utf-8"
+	case FormatXLSX:
+		return ContentTypeXLSX
+	}
+	return "application/json"
+}
+
+// Kind tells spreadsheet formats how to display a column.
+type Kind int
+
+const (
+	Text Kind = iota
+	Integer
+	// Money is an Amount shown with thousands separators and the
+	// decimals of its currency.
+	Money
+	Decimal
+	Time
+	// Number is a column mixing integers and decimals.
+	Number
+)
+
+type Column struct {
+	Title string
+	Kind  Kind
+}
+
+// Amount is money in minor units of a currency whose minor unit has
+// Exponent decimals. It is written in major units: 1250 with exponent 2
+// is 12.50.
+type Amount struct {
+	Minor    int64
+	Exponent int
+}
+
+// String is the exact amount in major units, without grouping.
+func (a Amount) String() string {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(a.Exponent)), nil)
+	return new(big.Rat).SetFrac(big.NewInt(a.Minor), scale).FloatString(a.Exponent)
+}
+
+// Float is the amount in major units, as spreadsheets store numbers.
+func (a Amount) Float() float64 {
+	return float64(a.Minor) / math.Pow10(a.Exponent)
+}
+
+// Writer writes one or more tables. Row values may be string, int,
+// int64, float64, Amount, time.Time, or *int and *time.Time where nil is
+// an empty cell.
+// Times are written as the wall clock of their location, without offset.
+// CSV text that a spreadsheet would read as a formula is prefixed with an
+// apostrophe.
+type Writer interface {
+	// Sheet starts a new table and writes its header row.
+	Sheet(name string, columns []Column) error
+	Row(values ...any) error
+	// Close flushes everything written so far. It does not close the
+	// underlying io.Writer.
+	Close() error
+}
+
+// NewWriter returns a Writer for a spreadsheet format.
+func NewWriter(format Format, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatXLSX:
+		return newXLSXWriter(w), nil
+	}
+	return nil, fmt.Errorf("format %q is not a spreadsheet format", format)
+}
+
+// cellValue dereferences optional values. It returns nil for an empty
+// cell.
+func cellValue(value any) any {
+	switch v := value.(type) {
+	case *int:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return *v
+	}
+	return value
+}
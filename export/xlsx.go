@@ -0,0 +1,174 @@
+package export
+
+import (
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Built-in number formats of the spreadsheet format.
+const (
+	numFmtGeneral = 0
+	numFmtInteger = 1 // 0
+	numFmtDecimal = 2 // 0.00
+)
+
+const xlsxTimeFormat = "yyyy-mm-dd hh:mm:ss"
+
+// xlsxWriter streams rows into a temporary file managed by excelize, so
+// large exports do not sit in memory. The workbook is zipped and written
+// out on Close.
+type xlsxWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	styles []int
+	// amountStyles maps currency exponents to the style of their amounts.
+	amountStyles map[int]int
+	sheets       int
+	row          int
+}
+
+func newXLSXWriter(w io.Writer) *xlsxWriter {
+	return &xlsxWriter{out: w, file: excelize.NewFile(), amountStyles: map[int]int{}}
+}
+
+func (w *xlsxWriter) Sheet(name string, columns []Column) error {
+	if err := w.flushSheet(); err != nil {
+		return err
+	}
+
+	// the first sheet replaces the default one
+	if w.sheets == 0 {
+		if err := w.file.SetSheetName(w.file.GetSheetName(0), name); err != nil {
+			return err
+		}
+	} else if _, err := w.file.NewSheet(name); err != nil {
+		return err
+	}
+
+	stream, err := w.file.NewStreamWriter(name)
+	if err != nil {
+		return err
+	}
+	w.stream = stream
+	w.sheets++
+
+	headerStyle, err := w.file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	header := make([]any, len(columns))
+	w.styles = make([]int, len(columns))
+	for i, column := range columns {
+		header[i] = excelize.Cell{StyleID: headerStyle, Value: column.Title}
+		if w.styles[i], err = w.columnStyle(column.Kind); err != nil {
+			return err
+		}
+		if err := stream.SetColWidth(i+1, i+1, columnWidth(column)); err != nil {
+			return err
+		}
+	}
+	if err := stream.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
+
+	w.row = 1
+	return stream.SetRow("A1", header)
+}
+
+func (w *xlsxWriter) Row(values ...any) error {
+	cells := make([]any, len(values))
+	for i, value := range values {
+		value = cellValue(value)
+		if value == nil {
+			continue
+		}
+		style := 0
+		if i < len(w.styles) {
+			style = w.styles[i]
+		}
+		if amount, ok := value.(Amount); ok {
+			var err error
+			if style, err = w.amountStyle(amount.Exponent); err != nil {
+				return err
+			}
+			value = amount.Float()
+		}
+		cells[i] = excelize.Cell{StyleID: style, Value: value}
+	}
+
+	w.row++
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+	return w.stream.SetRow(cell, cells)
+}
+
+func (w *xlsxWriter) Close() error {
+	defer w.file.Close()
+
+	if err := w.flushSheet(); err != nil {
+		return err
+	}
+	return w.file.Write(w.out)
+}
+
+func (w *xlsxWriter) flushSheet() error {
+	if w.stream == nil {
+		return nil
+	}
+	err := w.stream.Flush()
+	w.stream = nil
+	return err
+}
+
+func (w *xlsxWriter) columnStyle(kind Kind) (int, error) {
+	style := excelize.Style{NumFmt: numFmtGeneral}
+	switch kind {
+	case Integer:
+		style.NumFmt = numFmtInteger
+	case Decimal:
+		style.NumFmt = numFmtDecimal
+	case Time:
+		format := xlsxTimeFormat
+		style.CustomNumFmt = &format
+	case Text, Number, Money:
+		// amounts are styled per cell by their currency
+		return 0, nil
+	}
+	return w.file.NewStyle(&style)
+}
+
+// amountStyle shows amounts with thousands separators and exponent
+// decimals, e.g. #,##0.00 for cents.
+func (w *xlsxWriter) amountStyle(exponent int) (int, error) {
+	if style, ok := w.amountStyles[exponent]; ok {
+		return style, nil
+	}
+	format := "#,##0"
+	if exponent > 0 {
+		format += "." + strings.Repeat("0", exponent)
+	}
+	style, err := w.file.NewStyle(&excelize.Style{CustomNumFmt: &format})
+	if err != nil {
+		return 0, err
+	}
+	w.amountStyles[exponent] = style
+	return style, nil
+}
+
+func columnWidth(column Column) float64 {
+	width := float64(len(column.Title)) + 2
+	switch column.Kind {
+	case Time:
+		width = max(width, 20)
+	case Text:
+		width = max(width, 24)
+	default:
+		width = max(width, 12)
+	}
+	return width
+}
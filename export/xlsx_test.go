@@ -0,0 +1,84 @@
+package export
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestXLSXWriter(t *testing.T) {
+	at := time.Date(2026, 3, 8, 9, 5, 7, 0, time.FixedZone("WIB", 7*60*60))
+	quantity := 3
+	var noQuantity *int
+	var noTime *time.Time
+
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatXLSX, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := []func() error{
+		func() error {
+			return w.Sheet("Transactions", []Column{{"Name", Text}, {"Qty", Integer}, {"Total", Money}, {"At", Time}})
+		},
+		func() error { return w.Row("Kopi", &quantity, Amount{Minor: 24000}, at) },
+		func() error { return w.Row("=1+1", noQuantity, Amount{Minor: 150000050, Exponent: 2}, noTime) },
+		func() error { return w.Sheet("Summary", []Column{{"Metric", Text}, {"Value", Number}}) },
+		func() error { return w.Row("Average basket", 12.5) },
+		func() error { return w.Row("Average basket value", Amount{Minor: 1234567, Exponent: 2}) },
+		func() error { return w.Close() },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if got, want := file.GetSheetList(), []string{"Transactions", "Summary"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want sheets %v, got %v", want, got)
+	}
+
+	tests := []struct {
+		sheet string
+		want  [][]string
+	}{
+		{"Transactions", [][]string{
+			{"Name", "Qty", "Total", "At"},
+			{"Kopi", "3", "24,000", "2026-03-08 09:05:07"},
+			// text is stored as text, so it is shown rather than evaluated
+			{"=1+1", "", "1,500,000.50"},
+		}},
+		{"Summary", [][]string{
+			{"Metric", "Value"},
+			{"Average basket", "12.5"},
+			// amounts get their currency's decimals in a mixed column
+			{"Average basket value", "12,345.67"},
+		}},
+	}
+	for _, tt := range tests {
+		got, err := file.GetRows(tt.sheet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: want\n%q\ngot\n%q", tt.sheet, tt.want, got)
+		}
+	}
+
+	// an empty cell is left out rather than written as a blank string
+	if cellType, err := file.GetCellType("Transactions", "B3"); err != nil || cellType != excelize.CellTypeUnset {
+		t.Errorf("want B3 unset, got %v, %v", cellType, err)
+	}
+	if formula, err := file.GetCellFormula("Transactions", "A3"); err != nil || formula != "" {
+		t.Errorf("want A3 without a formula, got %q, %v", formula, err)
+	}
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"category-crud/auth"
+	"net/http"
+	"strings"
+)
+
+// AuthMiddleware guards routes with API keys (X-API-Key header) or
+// bearer JWTs. A nil authenticator disables authentication.
+type AuthMiddleware struct {
+	authenticator *auth.Authenticator
+}
+
+func NewAuthMiddleware(authenticator *auth.Authenticator) *AuthMiddleware {
+	return &AuthMiddleware{authenticator: authenticator}
+}
+
+// Require only lets callers with one of roles through. The caller is
+// available to the handler via auth.FromContext.
+func (m *AuthMiddleware) Require(roles ...string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		if m.authenticator == nil {
+			return next
+		}
+
+		return func(w http.ResponseWriter, r *http.Request) {
+			principal, err := m.authenticate(r)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+				writeError(w, r, err)
+				return
+			}
+			if !principal.HasRole(roles...) {
+				writeError(w, r, auth.ErrForbidden)
+				return
+			}
+
+			next(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		}
+	}
+}
+
+func (m *AuthMiddleware) authenticate(r *http.Request) (*auth.Principal, error) {
+	if header := r.Header.Get("Authorization"); header != "" {
+		scheme, token, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			return nil, auth.ErrInvalidCredentials
+		}
+		return m.authenticator.AuthenticateToken(r.Context(), strings.TrimSpace(token))
+	}
+
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return m.authenticator.AuthenticateAPIKey(key)
+	}
+
+	return nil, auth.ErrMissingCredentials
+}
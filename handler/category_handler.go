@@ -24,18 +24,29 @@ func NewCategoryHandler(service *service.CategoryService) *CategoryHandler {
 // @Tags categories
 // @Accept json
 // @Produce json
+// @Param include_deleted query bool false "Also list soft-deleted categories"
 // @Success 200 {array} model.Category
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 400 {object} ErrorResponse "Invalid query parameter"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /api/categories [get]
 func (h *CategoryHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	products, err := h.service.GetAll()
+	includeDeleted, err := queryIncludeDeleted(r.URL.Query())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeBadRequest(w, r, err.Error())
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(products)
+	products, err := h.service.GetAll(r.Context(), includeDeleted)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, products)
 }
 
 // CreateCategory godoc
@@ -46,25 +57,29 @@ func (h *CategoryHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param category body model.Category true "Category object"
 // @Success 201 {object} model.Category
-// @Failure 400 {string} string "Bad Request"
+// @Failure 400 {object} ErrorResponse "Invalid request body"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 422 {object} ErrorResponse "Validation failed; details lists each invalid field"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /api/categories [post]
 func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var category model.Category
 	err := json.NewDecoder(r.Body).Decode(&category)
 	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid request body")
 		return
 	}
 
-	err = h.service.Create(&category)
+	err = h.service.Create(r.Context(), &category)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(category)
+	writeJSON(w, http.StatusCreated, category)
 }
 
 // GetByID godoc
@@ -74,26 +89,37 @@ func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Category ID"
+// @Param include_deleted query bool false "Also return the category when it is soft-deleted"
 // @Success 200 {object} model.Category
-// @Failure 400 {object} map[string]string "Invalid category ID"
-// @Failure 404 {object} map[string]string "Category not found"
+// @Failure 400 {object} ErrorResponse "Invalid category ID"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 404 {object} ErrorResponse "Category not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /api/categories/{id} [get]
 func (h *CategoryHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid Category ID", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid Category ID")
+		return
+	}
+
+	includeDeleted, err := queryIncludeDeleted(r.URL.Query())
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
 		return
 	}
 
-	product, err := h.service.GetByID(id)
+	product, err := h.service.GetByID(r.Context(), id, includeDeleted)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(product)
+	writeJSON(w, http.StatusOK, product)
 }
 
 // Update godoc
@@ -105,63 +131,103 @@ func (h *CategoryHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 // @Param id path int true "Category ID"
 // @Param category body model.Category true "Category object"
 // @Success 200 {object} model.Category
-// @Failure 400 {object} map[string]string "Invalid category ID or request body"
-// @Failure 404 {object} map[string]string "Category not found"
+// @Failure 400 {object} ErrorResponse "Invalid category ID or request body"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 404 {object} ErrorResponse "Category not found"
+// @Failure 422 {object} ErrorResponse "Validation failed; details lists each invalid field"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /api/categories/{id} [put]
 func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid Category ID", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid Category ID")
 		return
 	}
 
 	var category model.Category
 	err = json.NewDecoder(r.Body).Decode(&category)
 	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid request body")
 		return
 	}
 
 	category.ID = id
-	err = h.service.Update(&category)
+	err = h.service.Update(r.Context(), &category)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(category)
+	writeJSON(w, http.StatusOK, category)
 }
 
 // Delete godoc
 // @Summary Delete category
-// @Description Delete a category by ID
+// @Description Soft-delete a category by ID. It is hidden from listings and product categories until restored.
 // @Tags categories
 // @Accept json
 // @Produce json
 // @Param id path int true "Category ID"
-// @Success 200 {object} map[string]string "Category deleted successfully"
-// @Failure 400 {object} map[string]string "Invalid category ID"
-// @Failure 404 {object} map[string]string "Category not found"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Success 200 {object} MessageResponse "Category deleted successfully"
+// @Failure 400 {object} ErrorResponse "Invalid category ID"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 404 {object} ErrorResponse "Category not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /api/categories/{id} [delete]
 func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid Category ID")
 		return
 	}
 
-	err = h.service.Delete(id)
+	err = h.service.Delete(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Category deleted successfully",
+	writeJSON(w, http.StatusOK, MessageResponse{
+		Message: "Category deleted successfully",
 	})
 }
+
+// Restore godoc
+// @Summary Restore category
+// @Description Undo the soft delete of a category
+// @Tags categories
+// @Produce json
+// @Param id path int true "Category ID"
+// @Success 200 {object} model.Category "Restored category"
+// @Failure 400 {object} ErrorResponse "Invalid category ID"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 404 {object} ErrorResponse "Category not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/categories/{id}/restore [post]
+func (h *CategoryHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeBadRequest(w, r, "Invalid Category ID")
+		return
+	}
+
+	category, err := h.service.Restore(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, category)
+}
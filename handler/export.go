@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"category-crud/export"
+	"category-crud/model"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// negotiateFormat picks the response format from ?format=, then from the
+// Accept header, and defaults to JSON.
+func negotiateFormat(r *http.Request) (export.Format, error) {
+	if raw := r.URL.Query().Get("format"); raw != "" {
+		return export.ParseFormat(raw)
+	}
+
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, export.ContentTypeCSV):
+		return export.FormatCSV, nil
+	case strings.Contains(accept, export.ContentTypeXLSX):
+		return export.FormatXLSX, nil
+	}
+	return export.FormatJSON, nil
+}
+
+// startExport sends the download headers and returns the table writer.
+// The server's write timeout is lifted since a large export can take
+// longer than any JSON response.
+func startExport(w http.ResponseWriter, format export.Format, name string) (export.Writer, error) {
+	// not every ResponseWriter supports deadlines; the export still works
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102"), format)
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	return export.NewWriter(format, w)
+}
+
+// abortExport is called once part of a download has been sent and the
+// rest cannot be. Aborting the connection makes the client see a failed
+// download instead of a silently truncated file.
+func abortExport(r *http.Request, err error) {
+	slog.ErrorContext(r.Context(), "export failed", "error", err)
+	panic(http.ErrAbortHandler)
+}
+
+// amount converts money to the major units exports are written in.
+func amount(m model.Money) export.Amount {
+	return export.Amount{Minor: m.Amount, Exponent: m.Exponent()}
+}
+
+var transactionLineColumns = []export.Column{
+	{Title: "Transaction ID", Kind: export.Integer},
+	{Title: "Created At", Kind: export.Time},
+	{Title: "Voided At", Kind: export.Time},
+	{Title: "Cashier ID", Kind: export.Integer},
+	{Title: "Transaction Total", Kind: export.Money},
+	{Title: "Line ID", Kind: export.Integer},
+	{Title: "Product ID", Kind: export.Integer},
+	{Title: "Product Name", Kind: export.Text},
+	{Title: "Unit Price", Kind: export.Money},
+	{Title: "Quantity", Kind: export.Integer},
+	{Title: "Subtotal", Kind: export.Money},
+	{Title: "Discount", Kind: export.Money},
+	{Title: "Tax", Kind: export.Money},
+	{Title: "Line Total", Kind: export.Money},
+}
+
+// writeTransactionLine writes one line with its times on the wall clock
+// of loc.
+func writeTransactionLine(out export.Writer, line *model.TransactionLine, loc *time.Location) error {
+	var voidedAt *time.Time
+	if line.VoidedAt != nil {
+		local := line.VoidedAt.In(loc)
+		voidedAt = &local
+	}
+	return out.Row(
+		line.TransactionID,
+		line.CreatedAt.In(loc),
+		voidedAt,
+		line.CashierID,
+		amount(line.TotalAmount),
+		line.DetailID,
+		line.ProductID,
+		line.ProductName,
+		amount(line.UnitPrice),
+		line.Quantity,
+		amount(line.Subtotal),
+		amount(line.DiscountAmount),
+		amount(line.TaxAmount),
+		amount(line.LineTotal),
+	)
+}
+
+var productSalesColumns = []export.Column{
+	{Title: "Rank", Kind: export.Integer},
+	{Title: "Product ID", Kind: export.Integer},
+	{Title: "Product Name", Kind: export.Text},
+	{Title: "Quantity", Kind: export.Integer},
+	{Title: "Revenue", Kind: export.Money},
+}
+
+// writeReportExport renders a report as a summary followed by one table
+// per breakdown.
+func writeReportExport(out export.Writer, report *model.Report) error {
+	err := out.Sheet("Summary", []export.Column{
+		{Title: "Metric", Kind: export.Text},
+		{Title: "Value", Kind: export.Number},
+	})
+	if err != nil {
+		return err
+	}
+	summary := []struct {
+		metric string
+		value  any
+	}{
+		{"Currency", model.DefaultCurrency()},
+		{"Total Revenue", amount(report.TotalRevenue)},
+		{"Total Discounts", amount(report.TotalDiscounts)},
+		{"Gross Sales", amount(report.GrossSales)},
+		{"Total Refunds", amount(report.TotalRefunds)},
+		{"Net Revenue", amount(report.NetRevenue)},
+		{"Total Tax", amount(report.TotalTax)},
+		{"Tax Refunded", amount(report.TaxRefunded)},
+		{"Net Tax", amount(report.NetTax)},
+		{"Transactions", report.TotalTransaks},
+		{"Items Sold", report.ItemsSold},
+		{"Average Basket Size", report.AverageBasketSize},
+		{"Average Basket Value", amount(report.AverageBasketValue)},
+	}
+	for _, row := range summary {
+		if err := out.Row(row.metric, row.value); err != nil {
+			return err
+		}
+	}
+
+	for _, ranking := range []struct {
+		name  string
+		sales []model.ProductSales
+	}{
+		{"Top by Quantity", report.TopByQuantity},
+		{"Top by Revenue", report.TopByRevenue},
+	} {
+		if err := out.Sheet(ranking.name, productSalesColumns); err != nil {
+			return err
+		}
+		for i, sales := range ranking.sales {
+			if err := out.Row(i+1, sales.ProductID, sales.ProductName, sales.Quantity, amount(sales.Revenue)); err != nil {
+				return err
+			}
+		}
+	}
+
+	err = out.Sheet("Categories", []export.Column{
+		{Title: "Category ID", Kind: export.Integer},
+		{Title: "Category Name", Kind: export.Text},
+		{Title: "Quantity", Kind: export.Integer},
+		{Title: "Revenue", Kind: export.Money},
+	})
+	if err != nil {
+		return err
+	}
+	for _, sales := range report.Categories {
+		if err := out.Row(sales.CategoryID, sales.CategoryName, sales.Quantity, amount(sales.Revenue)); err != nil {
+			return err
+		}
+	}
+
+	err = out.Sheet("Cashiers", []export.Column{
+		{Title: "Cashier ID", Kind: export.Integer},
+		{Title: "Cashier Name", Kind: export.Text},
+		{Title: "Transactions", Kind: export.Integer},
+		{Title: "Revenue", Kind: export.Money},
+	})
+	if err != nil {
+		return err
+	}
+	for _, sales := range report.Cashiers {
+		if err := out.Row(sales.CashierID, sales.CashierName, sales.TotalTransaksi, amount(sales.TotalRevenue)); err != nil {
+			return err
+		}
+	}
+
+	err = out.Sheet("Payments", []export.Column{
+		{Title: "Method", Kind: export.Text},
+		{Title: "Payments", Kind: export.Integer},
+		{Title: "Amount", Kind: export.Money},
+		{Title: "Tendered", Kind: export.Money},
+		{Title: "Change", Kind: export.Money},
+	})
+	if err != nil {
+		return err
+	}
+	for _, sales := range report.Payments {
+		if err := out.Row(sales.Method, sales.Payments, amount(sales.Amount), amount(sales.Tendered), amount(sales.Change)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeTimeseriesExport renders one row per bucket, with bucket starts on
+// the series' time zone wall clock.
+func writeTimeseriesExport(out export.Writer, series *model.Timeseries) error {
+	err := out.Sheet("Sales", []export.Column{
+		{Title: "Bucket Start", Kind: export.Time},
+		{Title: "Revenue", Kind: export.Money},
+		{Title: "Transactions", Kind: export.Integer},
+		{Title: "Items Sold", Kind: export.Integer},
+	})
+	if err != nil {
+		return err
+	}
+	for _, bucket := range series.Buckets {
+		if err := out.Row(bucket.Start, amount(bucket.TotalRevenue), bucket.TotalTransaksi, bucket.ItemsSold); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeExport renders an aggregate that is already in memory.
+func writeExport(w http.ResponseWriter, r *http.Request, format export.Format, name string, render func(export.Writer) error) {
+	out, err := startExport(w, format, name)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := render(out); err != nil {
+		abortExport(r, err)
+	}
+	if err := out.Close(); err != nil {
+		abortExport(r, err)
+	}
+}
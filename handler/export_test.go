@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"bytes"
+	"category-crud/export"
+	"category-crud/model"
+	"encoding/csv"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWriteReportExportSummary(t *testing.T) {
+	if err := model.SetDefaultCurrency("USD"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { model.SetDefaultCurrency("IDR") })
+
+	report := &model.Report{
+		TotalRevenue:       model.NewMoney(123456),
+		TotalDiscounts:     model.NewMoney(500),
+		GrossSales:         model.NewMoney(123956),
+		TotalRefunds:       model.NewMoney(1999),
+		NetRevenue:         model.NewMoney(121457),
+		TotalTax:           model.NewMoney(12235),
+		TaxRefunded:        model.NewMoney(198),
+		NetTax:             model.NewMoney(12037),
+		TotalTransaks:      3,
+		ItemsSold:          7,
+		AverageBasketSize:  7.0 / 3,
+		AverageBasketValue: model.NewMoney(41152),
+		Payments: []model.PaymentSales{
+			{Method: model.PaymentCash, Payments: 2, Amount: model.NewMoney(80000), Tendered: model.NewMoney(100000), Change: model.NewMoney(20000)},
+		},
+	}
+
+	var buf bytes.Buffer
+	out, err := export.NewWriter(export.FormatCSV, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeReportExport(out, report); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(buf.Bytes(), []byte{0xEF, 0xBB, 0xBF})))
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// amounts are in dollars like the basket size is in items, not cents
+	wantSummary := [][]string{
+		{"Metric", "Value"},
+		{"Currency", "USD"},
+		{"Total Revenue", "1234.56"},
+		{"Total Discounts", "5.00"},
+		{"Gross Sales", "1239.56"},
+		{"Total Refunds", "19.99"},
+		{"Net Revenue", "1214.57"},
+		{"Total Tax", "122.35"},
+		{"Tax Refunded", "1.98"},
+		{"Net Tax", "120.37"},
+		{"Transactions", "3"},
+		{"Items Sold", "7"},
+		{"Average Basket Size", "2.33"},
+		{"Average Basket Value", "411.52"},
+	}
+	if len(rows) < len(wantSummary) || !reflect.DeepEqual(rows[:len(wantSummary)], wantSummary) {
+		t.Fatalf("want summary\n%q\ngot\n%q", wantSummary, rows)
+	}
+	if got, want := rows[len(rows)-1], []string{"cash", "2", "800.00", "1000.00", "200.00"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want payments row %q, got %q", want, got)
+	}
+}
+
+// TestWriteTransactionLineTimes checks that exported times follow the
+// requested zone rather than the server's.
+func TestWriteTransactionLineTimes(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Skipf("time zone Asia/Jakarta unavailable: %v", err)
+	}
+	createdAt := time.Date(2026, 3, 8, 17, 30, 0, 0, time.UTC)
+	voidedAt := createdAt.Add(5 * time.Minute)
+	line := &model.TransactionLine{TransactionID: 1, CreatedAt: createdAt, VoidedAt: &voidedAt, DetailID: 1, ProductName: "Kopi", Quantity: 1}
+
+	tests := []struct {
+		name    string
+		loc     *time.Location
+		created string
+		voided  string
+	}{
+		{"UTC", time.UTC, "2026-03-08 17:30:00", "2026-03-08 17:35:00"},
+		{"a zone ahead of UTC", loc, "2026-03-09 00:30:00", "2026-03-09 00:35:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			out, err := export.NewWriter(export.FormatCSV, &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := out.Sheet("Transactions", transactionLineColumns); err != nil {
+				t.Fatal(err)
+			}
+			if err := writeTransactionLine(out, line, tt.loc); err != nil {
+				t.Fatal(err)
+			}
+			if err := out.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			rows, err := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(buf.Bytes(), []byte{0xEF, 0xBB, 0xBF}))).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != 2 {
+				t.Fatalf("want a header and one line, got %q", rows)
+			}
+			if got := rows[1][1:3]; got[0] != tt.created || got[1] != tt.voided {
+				t.Errorf("want created %s and voided %s, got %q", tt.created, tt.voided, got)
+			}
+		})
+	}
+}
@@ -3,5 +3,13 @@ package handler
 type HandlerGroup struct {
 	Product     *ProductHandler
 	Category    *CategoryHandler
+	Promotion   *PromotionHandler
+	Shift       *ShiftHandler
+	TaxClass    *TaxClassHandler
 	Transaction *TransactionHandler
+	User        *UserHandler
+	Health      *HealthHandler
+	Auth        *AuthMiddleware
+	// Metrics is nil when metrics are disabled.
+	Metrics *MetricsHandler
 }
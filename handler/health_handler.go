@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"category-crud/model"
+	"category-crud/service"
+	"net/http"
+)
+
+type HealthHandler struct {
+	service *service.HealthService
+}
+
+func NewHealthHandler(service *service.HealthService) *HealthHandler {
+	return &HealthHandler{service: service}
+}
+
+// Live godoc
+// @Summary Liveness probe
+// @Description Reports that the process is up and serving HTTP
+// @Tags health
+// @Produce json
+// @Success 200 {object} model.HealthCheck "Alive"
+// @Router /healthz [get]
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, model.HealthCheck{Status: model.HealthStatusOK})
+}
+
+// Ready godoc
+// @Summary Readiness probe
+// @Description Pings the database and reports the migration status. Fails while the server is shutting down.
+// @Tags health
+// @Produce json
+// @Success 200 {object} model.Readiness "Ready to serve traffic"
+// @Failure 503 {object} model.Readiness "Database unreachable, migrations pending or shutting down"
+// @Router /readyz [get]
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	readiness, ready := h.service.Readiness(r.Context())
+	if !ready {
+		writeJSON(w, http.StatusServiceUnavailable, readiness)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, readiness)
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"category-crud/metrics"
+	"net/http"
+	"time"
+)
+
+type MetricsHandler struct {
+	metrics *metrics.Metrics
+}
+
+func NewMetricsHandler(metrics *metrics.Metrics) *MetricsHandler {
+	return &MetricsHandler{metrics: metrics}
+}
+
+// Serve exposes the metrics in the Prometheus text format.
+func (h *MetricsHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	h.metrics.Handler().ServeHTTP(w, r)
+}
+
+// Instrument counts requests and observes their latency per route
+// template, so path parameters do not blow up label cardinality.
+func (h *MetricsHandler) Instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+
+		h.metrics.ObserveRequest(r.Method, routeTemplate(r), recorder.status, time.Since(start))
+	})
+}
@@ -5,7 +5,9 @@ import (
 	"category-crud/model/dto"
 	"category-crud/service"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/gorilla/mux"
@@ -21,63 +23,119 @@ func NewProductHandler(service *service.ProductService) *ProductHandler {
 
 // GetAll godoc
 // @Summary Get all products
-// @Description Retrieve a list of all products
+// @Description Retrieve a page of products. Use either page (offset pagination) or cursor (keyset pagination, taken from next_cursor).
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param name query string false "Filter products by name (case-insensitive search)"
 // @Param ids query []int false "Filter products by IDs (comma-separated)" collectionFormat(csv)
-// @Success 200 {array} model.Product "List of products"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Param category_id query []int false "Filter products by category IDs (comma-separated)" collectionFormat(csv)
+// @Param min_price query int false "Minimum price in minor units (inclusive)"
+// @Param max_price query int false "Maximum price in minor units (inclusive)"
+// @Param in_stock query bool false "Only products with (true) or without (false) stock"
+// @Param include_deleted query bool false "Also list soft-deleted products"
+// @Param sort query string false "Sort fields, prefix with - for descending (id, name, price, stock)" example(price,-stock,name)
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param page query int false "Page number, starting at 1"
+// @Param cursor query string false "Cursor from a previous next_cursor"
+// @Success 200 {object} dto.ProductPage "Page of products"
+// @Failure 400 {object} ErrorResponse "Invalid query parameter"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /api/products [get]
 func (h *ProductHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	r.URL.Query().Get("name")
-	filter := dto.ProductFilterRequest{
-		Name: r.URL.Query().Get("name"),
+	filter, err := parseProductFilter(r.URL.Query())
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
 	}
 
-	products, err := h.service.GetAll(&filter)
+	products, err := h.service.GetAll(r.Context(), filter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
 	// Return empty array instead of null if no products
-	if products == nil {
-		products = []model.Product{}
+	if products.Data == nil {
+		products.Data = []model.Product{}
+	}
+
+	writeJSON(w, http.StatusOK, products)
+}
+
+func parseProductFilter(query url.Values) (*dto.ProductFilterRequest, error) {
+	filter := dto.ProductFilterRequest{
+		Name:   query.Get("name"),
+		Cursor: query.Get("cursor"),
+	}
+
+	var err error
+	if filter.IDs, err = queryIntList(query, "ids"); err != nil {
+		return nil, err
+	}
+	if filter.CategoryIDs, err = queryIntList(query, "category_id"); err != nil {
+		return nil, err
+	}
+	if filter.MinPrice, err = queryMoney(query, "min_price"); err != nil {
+		return nil, err
+	}
+	if filter.MaxPrice, err = queryMoney(query, "max_price"); err != nil {
+		return nil, err
+	}
+	if filter.InStock, err = queryBool(query, "in_stock"); err != nil {
+		return nil, err
+	}
+	if filter.IncludeDeleted, err = queryIncludeDeleted(query); err != nil {
+		return nil, err
+	}
+	if filter.Sort, err = querySort(query, "sort", dto.ProductSortFields); err != nil {
+		return nil, err
+	}
+
+	if filter.Limit, filter.Page, err = queryPage(query); err != nil {
+		return nil, err
+	}
+	if filter.Page > 0 && filter.Cursor != "" {
+		return nil, errors.New("page and cursor cannot be combined")
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(products)
+	return &filter, nil
 }
 
 // Create godoc
 // @Summary Create product
-// @Description Create a new product
+// @Description Create a new product. price is {"amount": minor units, "currency": code} in the store currency; a bare number is read as minor units of the store currency.
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param product body dto.ProductRequest true "Product object"
 // @Success 201 {object} dto.ProductRequest "Product created successfully"
-// @Failure 400 {object} map[string]string "Invalid request body"
+// @Failure 400 {object} ErrorResponse "Invalid request body"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 422 {object} ErrorResponse "Validation failed; details lists each invalid field"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /api/products [post]
 func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var productCreateRequest dto.ProductRequest
 	err := json.NewDecoder(r.Body).Decode(&productCreateRequest)
 	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid request body")
 		return
 	}
 
-	err = h.service.Create(&productCreateRequest)
+	err = h.service.Create(r.Context(), &productCreateRequest)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(productCreateRequest)
+	writeJSON(w, http.StatusCreated, productCreateRequest)
 }
 
 // GetByID godoc
@@ -87,94 +145,143 @@ func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Product ID"
+// @Param include_deleted query bool false "Also return the product when it is soft-deleted"
 // @Success 200 {object} model.Product "Product found"
-// @Failure 400 {object} map[string]string "Invalid product ID"
-// @Failure 404 {object} map[string]string "Product not found"
+// @Failure 400 {object} ErrorResponse "Invalid product ID"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 404 {object} ErrorResponse "Product not found"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /api/products/{id} [get]
 func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid product ID")
 		return
 	}
 
-	product, err := h.service.GetByID(id)
+	includeDeleted, err := queryIncludeDeleted(r.URL.Query())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeBadRequest(w, r, err.Error())
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(product)
+	product, err := h.service.GetByID(r.Context(), id, includeDeleted)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, product)
 }
 
 // Update godoc
 // @Summary Update product
-// @Description Update an existing product by ID
+// @Description Update an existing product by ID. price is an amount object or a bare number of minor units, in the store currency.
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param id path int true "Product ID"
 // @Param product body model.Product true "Product object"
 // @Success 200 {object} model.Product "Product updated successfully"
-// @Failure 400 {object} map[string]string "Invalid product ID or request body"
-// @Failure 404 {object} map[string]string "Product not found"
+// @Failure 400 {object} ErrorResponse "Invalid product ID or request body"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 404 {object} ErrorResponse "Product not found"
+// @Failure 422 {object} ErrorResponse "Validation failed; details lists each invalid field"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /api/products/{id} [put]
 func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid product ID")
 		return
 	}
 
 	var product dto.ProductRequest
 	err = json.NewDecoder(r.Body).Decode(&product)
 	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid request body")
 		return
 	}
 
 	product.ID = id
-	err = h.service.Update(&product)
+	err = h.service.Update(r.Context(), &product)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(product)
+	writeJSON(w, http.StatusOK, product)
 }
 
 // Delete godoc
 // @Summary Delete product
-// @Description Delete a product by ID
+// @Description Soft-delete a product by ID. It disappears from listings and checkout but stays in transaction history and can be restored.
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param id path int true "Product ID"
-// @Success 200 {object} map[string]string "Product deleted successfully"
-// @Failure 400 {object} map[string]string "Invalid product ID"
-// @Failure 404 {object} map[string]string "Product not found"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Success 200 {object} MessageResponse "Product deleted successfully"
+// @Failure 400 {object} ErrorResponse "Invalid product ID"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 404 {object} ErrorResponse "Product not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /api/products/{id} [delete]
 func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid product ID")
 		return
 	}
 
-	err = h.service.Delete(id)
+	err = h.service.Delete(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Product deleted successfully",
+	writeJSON(w, http.StatusOK, MessageResponse{
+		Message: "Product deleted successfully",
 	})
 }
+
+// Restore godoc
+// @Summary Restore product
+// @Description Undo the soft delete of a product
+// @Tags products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} model.Product "Restored product"
+// @Failure 400 {object} ErrorResponse "Invalid product ID"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 404 {object} ErrorResponse "Product not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/products/{id}/restore [post]
+func (h *ProductHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeBadRequest(w, r, "Invalid product ID")
+		return
+	}
+
+	product, err := h.service.Restore(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, product)
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"category-crud/model/dto"
+	"category-crud/service"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type PromotionHandler struct {
+	service *service.PromotionService
+}
+
+func NewPromotionHandler(service *service.PromotionService) *PromotionHandler {
+	return &PromotionHandler{service: service}
+}
+
+// GetAll godoc
+// @Summary Get all promotions
+// @Description Retrieve every promotion that has not been deleted, including inactive and expired ones
+// @Tags promotions
+// @Produce json
+// @Success 200 {array} model.Promotion
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/promotions [get]
+func (h *PromotionHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	promotions, err := h.service.GetAll(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, promotions)
+}
+
+// Create godoc
+// @Summary Create a new promotion
+// @Description Create a promotion applied at checkout. percentage takes value percent off each unit, fixed takes value off each unit, buy_x_get_y gives get_quantity units free for every buy_quantity+get_quantity units of a product, and basket takes value off the matching lines. product_id or category_id narrows the matching lines, min_subtotal sets the threshold they must reach, and starts_at/ends_at bound the campaign.
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Param promotion body dto.PromotionRequest true "Promotion object"
+// @Success 201 {object} model.Promotion
+// @Failure 400 {object} ErrorResponse "Invalid request body"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 422 {object} ErrorResponse "Validation failed; details lists each invalid field"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/promotions [post]
+func (h *PromotionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.PromotionRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+
+	promotion, err := h.service.Create(r.Context(), &req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, promotion)
+}
+
+// GetByID godoc
+// @Summary Get promotion by ID
+// @Description Get a single promotion by its ID
+// @Tags promotions
+// @Produce json
+// @Param id path int true "Promotion ID"
+// @Success 200 {object} model.Promotion
+// @Failure 400 {object} ErrorResponse "Invalid promotion ID"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 404 {object} ErrorResponse "Promotion not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/promotions/{id} [get]
+func (h *PromotionHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeBadRequest(w, r, "Invalid Promotion ID")
+		return
+	}
+
+	promotion, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, promotion)
+}
+
+// Update godoc
+// @Summary Update promotion
+// @Description Replace a promotion. Sales already made keep the discounts they got.
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Param id path int true "Promotion ID"
+// @Param promotion body dto.PromotionRequest true "Promotion object"
+// @Success 200 {object} model.Promotion
+// @Failure 400 {object} ErrorResponse "Invalid promotion ID or request body"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 404 {object} ErrorResponse "Promotion not found"
+// @Failure 422 {object} ErrorResponse "Validation failed; details lists each invalid field"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/promotions/{id} [put]
+func (h *PromotionHandler) Update(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeBadRequest(w, r, "Invalid Promotion ID")
+		return
+	}
+
+	var req dto.PromotionRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+
+	req.ID = id
+	promotion, err := h.service.Update(r.Context(), &req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, promotion)
+}
+
+// Delete godoc
+// @Summary Delete promotion
+// @Description Soft-delete a promotion. It stops applying at checkout; past sales keep referencing it.
+// @Tags promotions
+// @Produce json
+// @Param id path int true "Promotion ID"
+// @Success 200 {object} MessageResponse "Promotion deleted successfully"
+// @Failure 400 {object} ErrorResponse "Invalid promotion ID"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 404 {object} ErrorResponse "Promotion not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/promotions/{id} [delete]
+func (h *PromotionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeBadRequest(w, r, "Invalid Promotion ID")
+		return
+	}
+
+	err = h.service.Delete(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, MessageResponse{
+		Message: "Promotion deleted successfully",
+	})
+}
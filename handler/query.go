@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"category-crud/model"
+	"category-crud/model/dto"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// queryInt parses an optional integer query parameter.
+func queryInt(query url.Values, key string) (*int, error) {
+	raw := query.Get(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %q is not an integer", key, raw)
+	}
+	return &value, nil
+}
+
+// queryMoney parses an optional amount in minor units of the store
+// currency.
+func queryMoney(query url.Values, key string) (*model.Money, error) {
+	raw := query.Get(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	amount, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %q is not an integer", key, raw)
+	}
+	value := model.NewMoney(amount)
+	return &value, nil
+}
+
+// queryBool parses an optional boolean query parameter.
+func queryBool(query url.Values, key string) (*bool, error) {
+	raw := query.Get(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %q is not a boolean", key, raw)
+	}
+	return &value, nil
+}
+
+// queryIncludeDeleted parses the include_deleted flag of product and
+// category reads.
+func queryIncludeDeleted(query url.Values) (bool, error) {
+	value, err := queryBool(query, "include_deleted")
+	if err != nil || value == nil {
+		return false, err
+	}
+	return *value, nil
+}
+
+// queryIntList accepts both repeated (?id=1&id=2) and comma separated
+// (?id=1,2) forms.
+func queryIntList(query url.Values, key string) ([]int, error) {
+	var values []int
+	for _, raw := range query[key] {
+		for _, part := range strings.Split(raw, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			value, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %q is not an integer", key, part)
+			}
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+// querySort parses "price,-stock,name" into sort fields; a leading "-"
+// means descending.
+func querySort(query url.Values, key string, allowed []string) ([]dto.SortField, error) {
+	raw := query.Get(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	var fields []dto.SortField
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field := dto.SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if !slices.Contains(allowed, field.Field) {
+			return nil, fmt.Errorf("invalid %s: cannot sort by %q", key, field.Field)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// queryDate parses an optional YYYY-MM-DD query parameter as local
+// midnight.
+func queryDate(query url.Values, key string) (*time.Time, error) {
+	return queryDateIn(query, key, time.Local)
+}
+
+// queryDateIn parses an optional YYYY-MM-DD query parameter as midnight
+// in loc.
+func queryDateIn(query url.Values, key string, loc *time.Location) (*time.Time, error) {
+	raw := query.Get(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := time.ParseInLocation(dateLayout, raw, loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %q, use YYYY-MM-DD", key, raw)
+	}
+	return &value, nil
+}
+
+// queryPage parses the limit and page parameters shared by list
+// endpoints. Zero values mean "not given".
+func queryPage(query url.Values) (limit int, page int, err error) {
+	limitValue, err := queryInt(query, "limit")
+	if err != nil {
+		return 0, 0, err
+	}
+	if limitValue != nil {
+		if *limitValue < 1 {
+			return 0, 0, fmt.Errorf("invalid limit: must be at least 1")
+		}
+		limit = *limitValue
+	}
+
+	pageValue, err := queryInt(query, "page")
+	if err != nil {
+		return 0, 0, err
+	}
+	if pageValue != nil {
+		if *pageValue < 1 {
+			return 0, 0, fmt.Errorf("invalid page: must be at least 1")
+		}
+		page = *pageValue
+	}
+
+	return limit, page, nil
+}
+
+// queryTop parses the optional length of report rankings. Zero means
+// "not given".
+func queryTop(query url.Values) (int, error) {
+	top, err := queryInt(query, "top")
+	if err != nil || top == nil {
+		return 0, err
+	}
+	if *top < 1 {
+		return 0, fmt.Errorf("invalid top: must be at least 1")
+	}
+	return *top, nil
+}
+
+// queryLocation parses an optional IANA time zone name such as
+// Asia/Jakarta. It defaults to UTC.
+func queryLocation(query url.Values, key string) (*time.Location, error) {
+	raw := query.Get(key)
+	if raw == "" {
+		return time.UTC, nil
+	}
+	// "Local" would mean the server's zone, which the database cannot
+	// resolve
+	if raw == "Local" {
+		return nil, fmt.Errorf("invalid %s: %q is not a time zone", key, raw)
+	}
+
+	loc, err := time.LoadLocation(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %q is not a time zone", key, raw)
+	}
+	return loc, nil
+}
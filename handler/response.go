@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"category-crud/apperror"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// ErrorResponse is the envelope of every error returned by the API.
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Code    string      `json:"code" example:"not_found"`
+	Message string      `json:"message" example:"produk tidak ditemukan"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// MessageResponse is returned by endpoints that have no resource to show.
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// statusClientClosedRequest is the de facto status (from nginx) for
+// requests the client abandoned.
+const statusClientClosedRequest = 499
+
+var statusByCode = map[apperror.Code]int{
+	apperror.CodeBadRequest:        http.StatusBadRequest,
+	apperror.CodeUnauthorized:      http.StatusUnauthorized,
+	apperror.CodeForbidden:         http.StatusForbidden,
+	apperror.CodeNotFound:          http.StatusNotFound,
+	apperror.CodeValidation:        http.StatusUnprocessableEntity,
+	apperror.CodeUnprocessable:     http.StatusUnprocessableEntity,
+	apperror.CodeConflict:          http.StatusConflict,
+	apperror.CodeInsufficientStock: http.StatusConflict,
+	apperror.CodeTimeout:           http.StatusGatewayTimeout,
+	apperror.CodeInternal:          http.StatusInternalServerError,
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeError renders err as an ErrorResponse. Untyped errors are logged
+// with the request ID and reported as a generic internal error so no
+// internals leak.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	if r.Context().Err() != nil {
+		// the client went away and its queries were canceled with it
+		slog.InfoContext(r.Context(), "request canceled by client", slog.Any("error", err))
+		w.WriteHeader(statusClientClosedRequest)
+		return
+	}
+
+	appErr := apperror.As(err)
+	if appErr == nil && apperror.IsTimeout(err) {
+		appErr = apperror.Wrap(err, apperror.CodeTimeout, "the database did not answer in time")
+	}
+	if appErr == nil {
+		slog.ErrorContext(r.Context(), "internal error", slog.Any("error", err))
+		appErr = apperror.New(apperror.CodeInternal, "internal server error", nil)
+	}
+
+	status, ok := statusByCode[appErr.Code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	if appErr.Err != nil {
+		level := slog.LevelDebug
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		slog.Log(r.Context(), level, appErr.Message, slog.Any("error", appErr.Err))
+	}
+
+	writeJSON(w, status, ErrorResponse{Error: ErrorBody{
+		Code:    string(appErr.Code),
+		Message: appErr.Message,
+		Details: appErr.Details,
+	}})
+}
+
+func writeBadRequest(w http.ResponseWriter, r *http.Request, message string) {
+	writeError(w, r, apperror.BadRequest(message))
+}
+
+// NotFound answers requests that match no route.
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, apperror.NotFound("route not found"))
+}
+
+// MethodNotAllowed answers requests whose route exists for other methods.
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: ErrorBody{
+		Code:    "method_not_allowed",
+		Message: "method not allowed",
+	}})
+}
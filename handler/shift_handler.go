@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"category-crud/auth"
+	"category-crud/model"
+	"category-crud/model/dto"
+	"category-crud/service"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type ShiftHandler struct {
+	service *service.ShiftService
+}
+
+func NewShiftHandler(service *service.ShiftService) *ShiftHandler {
+	return &ShiftHandler{service: service}
+}
+
+// GetAll godoc
+// @Summary Get shifts
+// @Description List shifts, most recently opened first
+// @Tags shifts
+// @Produce json
+// @Param cashier_id query int false "Only shifts of this cashier"
+// @Param open query bool false "Only open (true) or closed (false) shifts"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param page query int false "Page number (default 1)"
+// @Success 200 {object} dto.ShiftPage
+// @Failure 400 {object} ErrorResponse "Invalid query parameter"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/shifts [get]
+func (h *ShiftHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseShiftFilter(r.URL.Query())
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+
+	page, err := h.service.GetAll(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+func parseShiftFilter(query url.Values) (*dto.ShiftFilterRequest, error) {
+	var filter dto.ShiftFilterRequest
+	var err error
+
+	if filter.CashierID, err = queryInt(query, "cashier_id"); err != nil {
+		return nil, err
+	}
+	if filter.Open, err = queryBool(query, "open"); err != nil {
+		return nil, err
+	}
+	if filter.Limit, filter.Page, err = queryPage(query); err != nil {
+		return nil, err
+	}
+
+	return &filter, nil
+}
+
+// Open godoc
+// @Summary Open a shift
+// @Description Open a cash drawer shift for the requesting user with the opening float counted into the drawer. Checkouts they make while it is open are tied to it. A user can only have one shift open; requests made with an API key share one shift.
+// @Tags shifts
+// @Accept json
+// @Produce json
+// @Param request body model.OpenShiftRequest true "Opening float"
+// @Success 201 {object} model.Shift
+// @Failure 400 {object} ErrorResponse "Invalid request body"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 409 {object} ErrorResponse "A shift is already open"
+// @Failure 422 {object} ErrorResponse "Validation failed; details lists each invalid field"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/shifts [post]
+func (h *ShiftHandler) Open(w http.ResponseWriter, r *http.Request) {
+	var req model.OpenShiftRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+
+	shift, err := h.service.Open(r.Context(), &req, cashierOf(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, shift)
+}
+
+// GetByID godoc
+// @Summary Get shift by ID
+// @Description Get a single shift; closed shifts include their reconciliation
+// @Tags shifts
+// @Produce json
+// @Param id path int true "Shift ID"
+// @Success 200 {object} model.Shift
+// @Failure 400 {object} ErrorResponse "Invalid shift ID"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 404 {object} ErrorResponse "Shift not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/shifts/{id} [get]
+func (h *ShiftHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeBadRequest(w, r, "Invalid Shift ID")
+		return
+	}
+
+	shift, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, shift)
+}
+
+// Close godoc
+// @Summary Close a shift
+// @Description Close a shift with the cash counted in the drawer. The response reconciles it: expected cash is the opening float plus what the shift's sales took in cash, after change, minus what refunds of those sales paid back in cash (refunds go back to cash first, up to what the sale was paid in cash). The variance is counted minus expected, negative when cash is missing. Cashiers can only close their own shift.
+// @Tags shifts
+// @Accept json
+// @Produce json
+// @Param id path int true "Shift ID"
+// @Param request body model.CloseShiftRequest true "Counted cash"
+// @Success 200 {object} model.Shift
+// @Failure 400 {object} ErrorResponse "Invalid shift ID or request body"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed or shift of another cashier"
+// @Failure 404 {object} ErrorResponse "Shift not found"
+// @Failure 409 {object} ErrorResponse "Shift already closed"
+// @Failure 422 {object} ErrorResponse "Validation failed; details lists each invalid field"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/shifts/{id}/close [post]
+func (h *ShiftHandler) Close(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeBadRequest(w, r, "Invalid Shift ID")
+		return
+	}
+
+	var req model.CloseShiftRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+
+	ownOnly := auth.FromContext(r.Context()).HasRole(auth.RoleCashier)
+	shift, err := h.service.Close(r.Context(), id, &req, cashierOf(r), ownOnly)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, shift)
+}
+
+// GetReport godoc
+// @Summary Shift report
+// @Description Sales report of one shift, open or closed: the same totals, best-sellers and breakdowns as the date range report, over the sales tied to the shift and the refunds of those sales. CSV puts the tables one after another; XLSX has one sheet per table. Exported amounts are in major units of the store currency, which the summary names.
+// @Tags shifts
+// @Produce json
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param id path int true "Shift ID"
+// @Param top query int false "Length of each best-seller list (default 5, max 50)"
+// @Param format query string false "Response format; overrides the Accept header (text/csv or the xlsx media type)" Enums(json, csv, xlsx)
+// @Success 200 {object} model.Report "Report"
+// @Failure 400 {object} ErrorResponse "Invalid shift ID or query parameter"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 404 {object} ErrorResponse "Shift not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/shifts/{id}/report [get]
+func (h *ShiftHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeBadRequest(w, r, "Invalid Shift ID")
+		return
+	}
+	top, err := queryTop(r.URL.Query())
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+	format, err := negotiateFormat(r)
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+
+	report, err := h.service.GetReport(r.Context(), id, top)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeReport(w, r, format, report)
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"category-crud/model"
+	"category-crud/service"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type TaxClassHandler struct {
+	service *service.TaxClassService
+}
+
+func NewTaxClassHandler(service *service.TaxClassService) *TaxClassHandler {
+	return &TaxClassHandler{service: service}
+}
+
+// GetAll godoc
+// @Summary Get all tax classes
+// @Description Retrieve every tax class
+// @Tags tax-classes
+// @Produce json
+// @Success 200 {array} model.TaxClass
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/tax-classes [get]
+func (h *TaxClassHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	taxClasses, err := h.service.GetAll(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, taxClasses)
+}
+
+// Create godoc
+// @Summary Create a new tax class
+// @Description Create a tax rate, in basis points (1100 is 11%), that products and categories can be assigned to. A product uses its own tax class, else the one of its first category (by id) that has one; otherwise it is not taxed.
+// @Tags tax-classes
+// @Accept json
+// @Produce json
+// @Param taxClass body model.TaxClass true "Tax class object"
+// @Success 201 {object} model.TaxClass
+// @Failure 400 {object} ErrorResponse "Invalid request body"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 409 {object} ErrorResponse "Name already taken"
+// @Failure 422 {object} ErrorResponse "Validation failed; details lists each invalid field"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/tax-classes [post]
+func (h *TaxClassHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var taxClass model.TaxClass
+	err := json.NewDecoder(r.Body).Decode(&taxClass)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+
+	err = h.service.Create(r.Context(), &taxClass)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, taxClass)
+}
+
+// GetByID godoc
+// @Summary Get tax class by ID
+// @Description Get a single tax class by its ID
+// @Tags tax-classes
+// @Produce json
+// @Param id path int true "Tax class ID"
+// @Success 200 {object} model.TaxClass
+// @Failure 400 {object} ErrorResponse "Invalid tax class ID"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 404 {object} ErrorResponse "Tax class not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/tax-classes/{id} [get]
+func (h *TaxClassHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeBadRequest(w, r, "Invalid Tax Class ID")
+		return
+	}
+
+	taxClass, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, taxClass)
+}
+
+// Update godoc
+// @Summary Update tax class
+// @Description Replace a tax class. Sales already made keep the rate they were charged.
+// @Tags tax-classes
+// @Accept json
+// @Produce json
+// @Param id path int true "Tax class ID"
+// @Param taxClass body model.TaxClass true "Tax class object"
+// @Success 200 {object} model.TaxClass
+// @Failure 400 {object} ErrorResponse "Invalid tax class ID or request body"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 404 {object} ErrorResponse "Tax class not found"
+// @Failure 409 {object} ErrorResponse "Name already taken"
+// @Failure 422 {object} ErrorResponse "Validation failed; details lists each invalid field"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/tax-classes/{id} [put]
+func (h *TaxClassHandler) Update(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeBadRequest(w, r, "Invalid Tax Class ID")
+		return
+	}
+
+	var taxClass model.TaxClass
+	err = json.NewDecoder(r.Body).Decode(&taxClass)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+
+	taxClass.ID = id
+	err = h.service.Update(r.Context(), &taxClass)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, taxClass)
+}
+
+// Delete godoc
+// @Summary Delete tax class
+// @Description Delete a tax class that no product or category is assigned to
+// @Tags tax-classes
+// @Produce json
+// @Param id path int true "Tax class ID"
+// @Success 200 {object} MessageResponse "Tax class deleted successfully"
+// @Failure 400 {object} ErrorResponse "Invalid tax class ID"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 404 {object} ErrorResponse "Tax class not found"
+// @Failure 409 {object} ErrorResponse "Tax class still assigned to products or categories"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/tax-classes/{id} [delete]
+func (h *TaxClassHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeBadRequest(w, r, "Invalid Tax Class ID")
+		return
+	}
+
+	err = h.service.Delete(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, MessageResponse{
+		Message: "Tax class deleted successfully",
+	})
+}
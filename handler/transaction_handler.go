@@ -1,12 +1,26 @@
 package handler
 
 import (
+	"category-crud/auth"
+	"category-crud/export"
 	"category-crud/model"
+	"category-crud/model/dto"
 	"category-crud/service"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
 )
 
+const maxIdempotencyKeyLength = 255
+
 type TransactionHandler struct {
 	service *service.TransactionService
 }
@@ -17,72 +31,470 @@ func NewTransactionHandler(service *service.TransactionService) *TransactionHand
 
 // Checkout godoc
 // @Summary Checkout products
-// @Description Checkout selected products
+// @Description Checkout selected products. Running promotions are applied and recorded on each line and on the transaction, then tax is computed per line from the tax class of each product or its category, with prices tax-inclusive or tax-exclusive as configured. Subtotal, discount, tax and total are recorded per line and per transaction as {"amount", "currency"} objects in minor units; a sale whose total does not fit in 64 bits is rejected. payments must cover the total: card, qris and ewallet payments need a reference and may not exceed it, while cash may by less than the smallest cash payment, and the excess is recorded as change. When authenticated with a user token the user is recorded as the cashier, and the sale is tied to the shift they have open, if any.
 // @Tags transaction
 // @Accept json
 // @Produce json
-// @Success 200 {array} model.Transaction "Transaction"
+// @Success 200 {object} model.Transaction "Transaction"
 // @Param request body model.CheckoutRequest true "Checkout payload"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Param Idempotency-Key header string false "Unique key per checkout attempt; retries by the same caller with the same key and payload return the original transaction"
+// @Failure 400 {object} ErrorResponse "Invalid request body or Idempotency-Key"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 409 {object} ErrorResponse "Insufficient stock; details lists the offending items"
+// @Failure 422 {object} ErrorResponse "Invalid items or payments, unknown products (listed in details), payments that do not settle the total, or the Idempotency-Key was used with a different payload"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /api/checkout [post]
 func (h *TransactionHandler) Checkout(w http.ResponseWriter, r *http.Request) {
 	var req model.CheckoutRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		writeBadRequest(w, r, "Idempotency-Key must be at most 255 characters")
+		return
+	}
+
+	transaction, replayed, err := h.service.Checkout(r.Context(), &req, cashierOf(r), idempotencyKey)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+	writeJSON(w, http.StatusOK, transaction)
+}
+
+// cashierOf returns the user making the request. Requests made with an
+// API key or external token are not attributed to anyone.
+func cashierOf(r *http.Request) *int {
+	if principal := auth.FromContext(r.Context()); principal != nil && principal.UserID != 0 {
+		return &principal.UserID
+	}
+	return nil
+}
+
+// GetAll godoc
+// @Summary List transactions
+// @Description List transactions, newest first, with their details and product names. As CSV or XLSX every matching transaction is exported, one row per line item with amounts in major units of the store currency, and pagination is ignored.
+// @Tags transaction
+// @Produce json
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param start_date query string false "Created on or after this date in tz (YYYY-MM-DD)" example(2026-01-01)
+// @Param end_date query string false "Created on or before this date in tz (YYYY-MM-DD)" example(2026-02-01)
+// @Param tz query string false "IANA time zone of the dates and of exported times, defaults to UTC" example(Asia/Jakarta)
+// @Param product_id query []int false "Only transactions containing one of these products (comma-separated)" collectionFormat(csv)
+// @Param min_total query int false "Minimum total in minor units (inclusive)"
+// @Param max_total query int false "Maximum total in minor units (inclusive)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param page query int false "Page number, starting at 1"
+// @Param format query string false "Response format; overrides the Accept header (text/csv or the xlsx media type)" Enums(json, csv, xlsx)
+// @Success 200 {object} dto.TransactionPage "Page of transactions"
+// @Failure 400 {object} ErrorResponse "Invalid query parameter"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/transactions [get]
+func (h *TransactionHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseTransactionFilter(r.URL.Query())
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+	format, err := negotiateFormat(r)
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+
+	if format != export.FormatJSON {
+		h.exportTransactions(w, r, format, filter)
+		return
+	}
+
+	transactions, err := h.service.GetAll(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	transaction, err := h.service.Checkout(req.Items)
+	writeJSON(w, http.StatusOK, transactions)
+}
+
+// exportTransactions streams the matching lines. The download only
+// starts with the first row, so an error before it is still answered
+// with a JSON error.
+func (h *TransactionHandler) exportTransactions(w http.ResponseWriter, r *http.Request, format export.Format, filter *dto.TransactionFilterRequest) {
+	var out export.Writer
+	start := func() error {
+		var err error
+		if out, err = startExport(w, format, "transactions"); err != nil {
+			return err
+		}
+		return out.Sheet("Transactions", transactionLineColumns)
+	}
+
+	err := h.service.ExportTransactions(r.Context(), filter, func(line *model.TransactionLine) error {
+		if out == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return writeTransactionLine(out, line, filter.Location)
+	})
+	if err == nil && out == nil {
+		// nothing matched; still send a file with the header row
+		err = start()
+	}
+	if err != nil {
+		if out == nil {
+			writeError(w, r, err)
+			return
+		}
+		abortExport(r, err)
+	}
+
+	if err := out.Close(); err != nil {
+		abortExport(r, err)
+	}
+}
+
+func parseTransactionFilter(query url.Values) (*dto.TransactionFilterRequest, error) {
+	var filter dto.TransactionFilterRequest
+	var err error
+
+	if filter.Location, err = queryLocation(query, "tz"); err != nil {
+		return nil, err
+	}
+	if filter.StartDate, err = queryDateIn(query, "start_date", filter.Location); err != nil {
+		return nil, err
+	}
+	if filter.EndDate, err = queryDateIn(query, "end_date", filter.Location); err != nil {
+		return nil, err
+	}
+	if filter.EndDate != nil {
+		// end_date is inclusive
+		endOfDay := filter.EndDate.AddDate(0, 0, 1)
+		filter.EndDate = &endOfDay
+	}
+	if filter.ProductIDs, err = queryIntList(query, "product_id"); err != nil {
+		return nil, err
+	}
+	if filter.MinTotal, err = queryMoney(query, "min_total"); err != nil {
+		return nil, err
+	}
+	if filter.MaxTotal, err = queryMoney(query, "max_total"); err != nil {
+		return nil, err
+	}
+	if filter.Limit, filter.Page, err = queryPage(query); err != nil {
+		return nil, err
+	}
+
+	return &filter, nil
+}
+
+// GetByID godoc
+// @Summary Get transaction by ID
+// @Description Get a single transaction with its details and product names
+// @Tags transaction
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Success 200 {object} model.Transaction "Transaction found"
+// @Failure 400 {object} ErrorResponse "Invalid transaction ID"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 404 {object} ErrorResponse "Transaction not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/transactions/{id} [get]
+func (h *TransactionHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeBadRequest(w, r, "Invalid transaction ID")
+		return
+	}
 
+	transaction, err := h.service.GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(transaction)
+	writeJSON(w, http.StatusOK, transaction)
+}
+
+// Refund godoc
+// @Summary Refund a transaction
+// @Description Refund some or all items of a transaction. Without items every remaining quantity is refunded. Amounts are what was charged, after discounts and with tax, and the tax part is recorded. Refunded items are put back in stock. The refund is tied to the shift the caller has open, whose drawer pays out any cash.
+// @Tags transaction
+// @Accept json
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Param request body model.RefundRequest true "Refund payload"
+// @Success 201 {object} model.Refund "Refund document"
+// @Failure 400 {object} ErrorResponse "Invalid transaction ID or request body"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 404 {object} ErrorResponse "Transaction not found"
+// @Failure 409 {object} ErrorResponse "Transaction voided or already fully refunded"
+// @Failure 422 {object} ErrorResponse "Validation failed or one or more refund lines are invalid"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/transactions/{id}/refund [post]
+func (h *TransactionHandler) Refund(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeBadRequest(w, r, "Invalid transaction ID")
+		return
+	}
+
+	var req model.RefundRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+
+	refund, err := h.service.Refund(r.Context(), id, &req, cashierOf(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, refund)
+}
+
+// Void godoc
+// @Summary Void a transaction
+// @Description Cancel a whole transaction shortly after checkout. Every item is put back in stock.
+// @Tags transaction
+// @Accept json
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Param request body model.VoidRequest false "Void payload"
+// @Success 201 {object} model.Refund "Void document"
+// @Failure 400 {object} ErrorResponse "Invalid transaction ID or request body"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 404 {object} ErrorResponse "Transaction not found"
+// @Failure 409 {object} ErrorResponse "Void window expired, transaction voided or already fully refunded"
+// @Failure 422 {object} ErrorResponse "Validation failed; details lists each invalid field"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/transactions/{id}/void [post]
+func (h *TransactionHandler) Void(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeBadRequest(w, r, "Invalid transaction ID")
+		return
+	}
+
+	var req model.VoidRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+
+	refund, err := h.service.Void(r.Context(), id, &req, cashierOf(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, refund)
 }
 
 // Report Transaction Today godoc
 // @Summary Report Transaction Today
-// @Description Report Transaction Today
+// @Description Sales report for today: totals, discounts, tax collected, items sold, basket averages, best-sellers, revenue per category and cashier, and what each payment method took in, before refunds. CSV puts the tables one after another; XLSX has one sheet per table. Exported amounts are in major units of the store currency, which the summary names.
 // @Tags transaction
 // @Produce json
-// @Success 200 {array} model.Report "Report"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param top query int false "Length of each best-seller list (default 5, max 50)"
+// @Param format query string false "Response format; overrides the Accept header (text/csv or the xlsx media type)" Enums(json, csv, xlsx)
+// @Success 200 {object} model.Report "Report"
+// @Failure 400 {object} ErrorResponse "Invalid query parameter"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /api/report/hari-ini [get]
 func (h *TransactionHandler) GetReportToday(w http.ResponseWriter, r *http.Request) {
-	report, err := h.service.GetReport("", "")
+	top, err := queryTop(r.URL.Query())
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+	format, err := negotiateFormat(r)
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+
+	report, err := h.service.GetReport(r.Context(), &dto.ReportRequest{Top: top})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(report)
+	writeReport(w, r, format, report)
 }
 
 // Report Transaction Based on Date godoc
 // @Summary Report Transaction Based on Date
-// @Description Report Transaction Based on Date
+// @Description Sales report for a date range: totals, discounts, tax collected, items sold, basket averages, best-sellers, revenue per category and cashier, and what each payment method took in, before refunds. Both dates are inclusive and default to today. CSV puts the tables one after another; XLSX has one sheet per table. Exported amounts are in major units of the store currency, which the summary names.
 // @Tags transaction
 // @Produce json
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 // @Param start_date query string false "Start date (YYYY-MM-DD)" example(2026-01-01)
 // @Param end_date query string false "End date (YYYY-MM-DD)" example(2026-02-01)
-// @Success 200 {array} model.Report "Report"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Param top query int false "Length of each best-seller list (default 5, max 50)"
+// @Param format query string false "Response format; overrides the Accept header (text/csv or the xlsx media type)" Enums(json, csv, xlsx)
+// @Success 200 {object} model.Report "Report"
+// @Failure 400 {object} ErrorResponse "Invalid date range or query parameter"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /api/report [get]
 func (h *TransactionHandler) GetReport(w http.ResponseWriter, r *http.Request) {
-	startDate := r.URL.Query().Get("start_date")
-	endDate := r.URL.Query().Get("end_date")
+	req, err := parseReportRequest(r.URL.Query())
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+	format, err := negotiateFormat(r)
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
 
-	report, err := h.service.GetReport(startDate, endDate)
+	report, err := h.service.GetReport(r.Context(), req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(report)
+	writeReport(w, r, format, report)
+}
+
+func writeReport(w http.ResponseWriter, r *http.Request, format export.Format, report *model.Report) {
+	if format == export.FormatJSON {
+		writeJSON(w, http.StatusOK, report)
+		return
+	}
+	writeExport(w, r, format, "report", func(out export.Writer) error {
+		return writeReportExport(out, report)
+	})
+}
+
+func parseReportRequest(query url.Values) (*dto.ReportRequest, error) {
+	var req dto.ReportRequest
+	var err error
+
+	if req.StartDate, err = queryDate(query, "start_date"); err != nil {
+		return nil, err
+	}
+	if req.EndDate, err = queryDate(query, "end_date"); err != nil {
+		return nil, err
+	}
+	if req.Top, err = queryTop(query); err != nil {
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+// Report Timeseries godoc
+// @Summary Sales time series
+// @Description Revenue, transaction count and units sold per hour, day, week or month. Buckets follow the wall clock of tz (weeks start on Monday) and intervals without sales are returned with zeros. CSV and XLSX give revenue in major units of the store currency.
+// @Tags transaction
+// @Produce json
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param start_date query string false "Start date in tz (YYYY-MM-DD), defaults to today" example(2026-01-01)
+// @Param end_date query string false "End date in tz (YYYY-MM-DD, inclusive), defaults to today" example(2026-01-31)
+// @Param interval query string false "Bucket size" Enums(hour, day, week, month) default(day)
+// @Param tz query string false "IANA time zone, defaults to UTC" example(Asia/Jakarta)
+// @Param format query string false "Response format; overrides the Accept header (text/csv or the xlsx media type)" Enums(json, csv, xlsx)
+// @Success 200 {object} model.Timeseries "Time series"
+// @Failure 400 {object} ErrorResponse "Invalid query parameter or range too long for the interval"
+// @Failure 401 {object} ErrorResponse "Missing or invalid credentials"
+// @Failure 403 {object} ErrorResponse "Role not allowed"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/report/timeseries [get]
+func (h *TransactionHandler) GetTimeseries(w http.ResponseWriter, r *http.Request) {
+	req, err := parseTimeseriesRequest(r.URL.Query())
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+	format, err := negotiateFormat(r)
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+
+	series, err := h.service.GetTimeseries(r.Context(), req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if format == export.FormatJSON {
+		writeJSON(w, http.StatusOK, series)
+		return
+	}
+	writeExport(w, r, format, "sales-"+series.Interval, func(out export.Writer) error {
+		return writeTimeseriesExport(out, series)
+	})
+}
+
+func parseTimeseriesRequest(query url.Values) (*dto.TimeseriesRequest, error) {
+	req := dto.TimeseriesRequest{
+		Interval: query.Get("interval"),
+	}
+	var err error
+
+	if req.Interval == "" {
+		req.Interval = model.IntervalDay
+	}
+	if !slices.Contains(dto.TimeseriesIntervals, req.Interval) {
+		return nil, fmt.Errorf("invalid interval: %q, use one of %s", req.Interval, strings.Join(dto.TimeseriesIntervals, ", "))
+	}
+	if req.Location, err = queryLocation(query, "tz"); err != nil {
+		return nil, err
+	}
+	if req.StartDate, err = queryDateIn(query, "start_date", req.Location); err != nil {
+		return nil, err
+	}
+	if req.EndDate, err = queryDateIn(query, "end_date", req.Location); err != nil {
+		return nil, err
+	}
+
+	return &req, nil
 }
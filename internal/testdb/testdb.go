@@ -0,0 +1,81 @@
+// Package testdb gives integration tests a migrated Postgres schema of
+// their own.
+package testdb
+
+import (
+	"category-crud/db"
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
+	_ "github.com/lib/pq"
+)
+
+// Env names the DSN of a Postgres server the integration tests may create
+// schemas in. They are skipped when it is unset.
+const Env = "TEST_DATABASE_URL"
+
+// Open migrates a schema of its own on the test database and drops it
+// when the test ends, so tests neither see nor leave behind any data.
+func Open(t *testing.T) (*sql.DB, *goqu.Database) {
+	t.Helper()
+
+	dsn := os.Getenv(Env)
+	if dsn == "" {
+		t.Skipf("%s is not set", Env)
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Errorf("drop schema %s: %v", schema, err)
+		}
+	})
+
+	conn, err := sql.Open("postgres", withSearchPath(dsn, schema))
+	if err != nil {
+		t.Fatalf("open test schema: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	migrator, err := db.NewMigrator(conn)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	return conn, goqu.New("postgres", conn)
+}
+
+// withSearchPath points every connection of dsn, in URL or key=value
+// form, at schema.
+func withSearchPath(dsn, schema string) string {
+	if strings.Contains(dsn, "://") {
+		parsed, err := url.Parse(dsn)
+		if err == nil {
+			query := parsed.Query()
+			query.Set("search_path", schema)
+			parsed.RawQuery = query.Encode()
+			return parsed.String()
+		}
+	}
+	return dsn + " search_path=" + schema
+}
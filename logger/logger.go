@@ -0,0 +1,83 @@
+// Package logger configures the process wide slog logger and carries the
+// request ID through contexts so every record of a request can be
+// correlated.
+package logger
+
+import (
+	"category-crud/config"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+type contextKey struct{}
+
+// Setup builds the logger selected by app.log_level and app.log_format
+// and installs it as the slog and log default.
+func Setup(config config.Template) (*slog.Logger, error) {
+	logger, err := New(os.Stdout, config.App.LogLevel, config.App.LogFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.SetDefault(logger)
+	return logger, nil
+}
+
+// New returns a logger writing to w. level is debug, info, warn or error
+// (default info); format is json or text (default json).
+func New(w io.Writer, level string, format string) (*slog.Logger, error) {
+	var slogLevel slog.Level
+	if level != "" {
+		if err := slogLevel.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("invalid log level %q", level)
+		}
+	}
+	options := &slog.HandlerOptions{Level: slogLevel}
+
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "", "json":
+		handler = slog.NewJSONHandler(w, options)
+	case "text":
+		handler = slog.NewTextHandler(w, options)
+	default:
+		return nil, fmt.Errorf("invalid log format %q, expected json or text", format)
+	}
+
+	return slog.New(contextHandler{handler}), nil
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, requestID)
+}
+
+// RequestID returns the ID of the request ctx belongs to, or "".
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(contextKey{}).(string)
+	return requestID
+}
+
+// contextHandler adds the request_id attribute to records logged with a
+// request context, e.g. slog.ErrorContext(r.Context(), ...).
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
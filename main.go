@@ -1,7 +1,28 @@
 package main
 
-import "category-crud/app"
+import (
+	"category-crud/app"
+	"log"
+	"os"
+	// time zones for report bucketing even where the host has no tzdata
+	_ "time/tzdata"
+)
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			if err := app.Migrate(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "backfill":
+			if err := app.Backfill(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+
 	app.Start()
 }
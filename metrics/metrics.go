@@ -0,0 +1,112 @@
+// Package metrics collects the Prometheus metrics exposed on /metrics.
+// A nil *Metrics is valid and records nothing, which is how metrics are
+// disabled.
+package metrics
+
+import (
+	"category-crud/model"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+
+	checkouts        prometheus.Counter
+	itemsSold        prometheus.Counter
+	revenue          prometheus.Counter
+	checkoutFailures *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by method and route template.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		checkouts: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "checkouts_total",
+			Help: "Completed checkouts, excluding idempotent replays.",
+		}),
+		itemsSold: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "checkout_items_sold_total",
+			Help: "Units sold across all completed checkouts.",
+		}),
+		revenue: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "checkout_revenue_total",
+			Help: "Sum of the total amount of completed checkouts.",
+		}),
+		checkoutFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "checkout_failures_total",
+			Help: "Rejected checkouts by error code, e.g. insufficient_stock.",
+		}, []string{"reason"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.checkouts,
+		m.itemsSold,
+		m.revenue,
+		m.checkoutFailures,
+	)
+
+	return m
+}
+
+// RegisterDB exports the connection pool statistics (go_sql_* metrics)
+// of db.
+func (m *Metrics) RegisterDB(db *sql.DB, name string) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the registry in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+func (m *Metrics) ObserveRequest(method string, route string, status int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.requests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.requestDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+func (m *Metrics) CheckoutSucceeded(transaction *model.Transaction) {
+	if m == nil {
+		return
+	}
+	m.checkouts.Inc()
+	m.revenue.Add(float64(transaction.TotalAmount.Amount))
+	for _, detail := range transaction.Details {
+		m.itemsSold.Add(float64(detail.Quantity))
+	}
+}
+
+func (m *Metrics) CheckoutFailed(reason string) {
+	if m == nil {
+		return
+	}
+	m.checkoutFailures.WithLabelValues(reason).Inc()
+}
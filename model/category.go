@@ -1,7 +1,11 @@
 package model
 
+import "time"
+
 type Category struct {
-	ID          int    `json:"id" db:"id"`
-	Name        string `json:"name" db:"name"`
-	Description string `json:"description" db:"description"`
+	ID          int        `json:"id" db:"id"`
+	Name        string     `json:"name" db:"name" validate:"notblank,max=255"`
+	Description string     `json:"description" db:"description" validate:"max=1000"`
+	TaxClassID  *int       `json:"tax_class_id" db:"tax_class_id" validate:"omitempty,gt=0"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 }
@@ -1,15 +1,50 @@
 package dto
 
+import "category-crud/model"
+
+const (
+	DefaultProductLimit = 20
+	MaxProductLimit     = 100
+)
+
+// ProductSortFields lists the columns GET /api/products can be sorted by.
+var ProductSortFields = []string{"id", "name", "price", "stock"}
+
 type ProductRequest struct {
-	ID         int    `json:"id" db:"id"`
-	Name       string `json:"name" db:"name"`
-	Price      int    `json:"price" db:"price"`
-	Stock      int    `json:"stock" db:"stock"`
-	Categories []int  `json:"categories" db:"-"`
+	ID         int         `json:"id" db:"id"`
+	Name       string      `json:"name" db:"name" validate:"notblank,max=255"`
+	Price      model.Money `json:"price" db:"price" validate:"gte=0"`
+	Stock      int         `json:"stock" db:"stock" validate:"gte=0"`
+	Categories []int       `json:"categories" db:"-" validate:"unique,dive,gt=0"`
+	TaxClassID *int        `json:"tax_class_id" db:"tax_class_id" validate:"omitempty,gt=0"`
+}
+
+type SortField struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
 }
 
 type ProductFilterRequest struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
-	IDs  []int  `json:"ids"`
+	ID          int          `json:"id"`
+	Name        string       `json:"name"`
+	IDs         []int        `json:"ids"`
+	CategoryIDs []int        `json:"category_ids"`
+	MinPrice    *model.Money `json:"min_price"`
+	MaxPrice    *model.Money `json:"max_price"`
+	InStock     *bool        `json:"in_stock"`
+	// IncludeDeleted also lists soft-deleted products.
+	IncludeDeleted bool        `json:"include_deleted"`
+	Sort           []SortField `json:"sort"`
+	Limit          int         `json:"limit"`
+	Page           int         `json:"page"`
+	Cursor         string      `json:"cursor"`
+}
+
+// ProductPage is the envelope returned by GET /api/products.
+type ProductPage struct {
+	Data       []model.Product `json:"data"`
+	Total      int             `json:"total"`
+	Limit      int             `json:"limit"`
+	Page       int             `json:"page,omitempty"`
+	NextCursor string          `json:"next_cursor,omitempty"`
 }
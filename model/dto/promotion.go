@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"category-crud/model"
+	"time"
+)
+
+// PromotionRequest creates or replaces a promotion. Active defaults to
+// true on create and keeps its current value on update when omitted.
+type PromotionRequest struct {
+	ID          int         `json:"id"`
+	Name        string      `json:"name" validate:"notblank,max=255"`
+	Type        string      `json:"type" validate:"oneof=percentage fixed buy_x_get_y basket"`
+	Value       int64       `json:"value" validate:"gte=0"`
+	ProductID   *int        `json:"product_id" validate:"omitempty,gt=0"`
+	CategoryID  *int        `json:"category_id" validate:"omitempty,gt=0"`
+	BuyQuantity int         `json:"buy_quantity" validate:"gte=0"`
+	GetQuantity int         `json:"get_quantity" validate:"gte=0"`
+	MinSubtotal model.Money `json:"min_subtotal" validate:"gte=0"`
+	StartsAt    *time.Time  `json:"starts_at"`
+	EndsAt      *time.Time  `json:"ends_at"`
+	Active      *bool       `json:"active"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+import "category-crud/model"
+
+const (
+	DefaultShiftLimit = 20
+	MaxShiftLimit     = 100
+)
+
+type ShiftFilterRequest struct {
+	CashierID *int `json:"cashier_id"`
+	// Open lists only open (true) or closed (false) shifts.
+	Open  *bool `json:"open"`
+	Limit int   `json:"limit"`
+	Page  int   `json:"page"`
+}
+
+// ShiftPage is the envelope returned by GET /api/shifts.
+type ShiftPage struct {
+	Data  []model.Shift `json:"data"`
+	Total int           `json:"total"`
+	Limit int           `json:"limit"`
+	Page  int           `json:"page"`
+}
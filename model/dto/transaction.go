@@ -0,0 +1,61 @@
+package dto
+
+import (
+	"category-crud/model"
+	"time"
+)
+
+const (
+	DefaultTransactionLimit = 20
+	MaxTransactionLimit     = 100
+
+	DefaultReportTop = 5
+	MaxReportTop     = 50
+
+	// MaxTimeseriesBuckets caps the length of a time series.
+	MaxTimeseriesBuckets = 1000
+)
+
+// TimeseriesIntervals lists the bucket sizes of GET /api/report/timeseries.
+var TimeseriesIntervals = []string{model.IntervalHour, model.IntervalDay, model.IntervalWeek, model.IntervalMonth}
+
+type TransactionFilterRequest struct {
+	// StartDate and EndDate bound created_at as [StartDate, EndDate).
+	StartDate  *time.Time   `json:"start_date"`
+	EndDate    *time.Time   `json:"end_date"`
+	ProductIDs []int        `json:"product_ids"`
+	MinTotal   *model.Money `json:"min_total"`
+	MaxTotal   *model.Money `json:"max_total"`
+	Limit      int          `json:"limit"`
+	Page       int          `json:"page"`
+	// Location is the zone the dates are midnight in and exported times
+	// are written in.
+	Location *time.Location `json:"-"`
+}
+
+// TransactionPage is the envelope returned by GET /api/transactions.
+type TransactionPage struct {
+	Data  []model.Transaction `json:"data"`
+	Total int                 `json:"total"`
+	Limit int                 `json:"limit"`
+	Page  int                 `json:"page"`
+}
+
+// ReportRequest is the query of GET /api/report. Missing dates default
+// to today.
+type ReportRequest struct {
+	StartDate *time.Time `json:"start_date"`
+	// EndDate is inclusive: the whole day is reported.
+	EndDate *time.Time `json:"end_date"`
+	Top     int        `json:"top"`
+}
+
+// TimeseriesRequest is the query of GET /api/report/timeseries. Dates are
+// midnight in Location; missing ones default to today there.
+type TimeseriesRequest struct {
+	StartDate *time.Time `json:"start_date"`
+	// EndDate is inclusive: the whole day is covered.
+	EndDate  *time.Time     `json:"end_date"`
+	Interval string         `json:"interval"`
+	Location *time.Location `json:"-"`
+}
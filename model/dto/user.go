@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"category-crud/model"
+	"time"
+)
+
+// UserRequest creates or updates a user. Password is required on create;
+// on update an empty password keeps the current one.
+type UserRequest struct {
+	ID       int    `json:"id"`
+	Username string `json:"username" validate:"notblank,max=64"`
+	Name     string `json:"name" validate:"max=255"`
+	Password string `json:"password" validate:"omitempty,min=8,max=72"`
+	Role     string `json:"role" validate:"oneof=admin manager cashier"`
+	Active   *bool  `json:"active"`
+}
+
+type LoginRequest struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type LoginResponse struct {
+	Token     string     `json:"token"`
+	TokenType string     `json:"token_type" example:"Bearer"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	User      model.User `json:"user"`
+}
@@ -0,0 +1,31 @@
+package model
+
+const (
+	HealthStatusOK   = "ok"
+	HealthStatusFail = "fail"
+
+	ReadinessReady        = "ready"
+	ReadinessNotReady     = "not_ready"
+	ReadinessShuttingDown = "shutting_down"
+)
+
+type HealthCheck struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// MigrationState compares the schema applied to the database with the
+// migrations embedded in the binary.
+type MigrationState struct {
+	Status  string `json:"status"`
+	Current int64  `json:"current"`
+	Latest  int64  `json:"latest"`
+	Pending int    `json:"pending"`
+	Error   string `json:"error,omitempty"`
+}
+
+type Readiness struct {
+	Status     string          `json:"status"`
+	Database   HealthCheck     `json:"database"`
+	Migrations *MigrationState `json:"migrations,omitempty"`
+}
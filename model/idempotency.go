@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// IdempotencyKey ties a client supplied Idempotency-Key header to the
+// request it was first used with and the transaction it produced.
+type IdempotencyKey struct {
+	Key           string    `json:"key" db:"key"`
+	RequestHash   string    `json:"request_hash" db:"request_hash"`
+	TransactionID int       `json:"transaction_id" db:"transaction_id"`
+	ExpiresAt     time.Time `json:"expires_at" db:"expires_at"`
+}
@@ -0,0 +1,260 @@
+package model
+
+import (
+	"bytes"
+	"category-crud/apperror"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ErrAmountOverflow is returned when an amount does not fit in 64 bits.
+var ErrAmountOverflow = apperror.Unprocessable("amount is too large", nil)
+
+var errCurrencyMismatch = errors.New("amounts in different currencies")
+
+// Rounding modes for amounts that fall between two minor units.
+const (
+	RoundHalfUp = "half_up"
+	RoundDown   = "down"
+	RoundUp     = "up"
+)
+
+// currencyExponents lists the supported ISO 4217 currencies with the
+// number of decimals of their minor unit. IDR is not subdivided in
+// practice, so its minor unit is the rupiah itself.
+var currencyExponents = map[string]int{
+	"IDR": 0,
+	"JPY": 0,
+	"KRW": 0,
+	"VND": 0,
+	"AUD": 2,
+	"CNY": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"MYR": 2,
+	"PHP": 2,
+	"SGD": 2,
+	"THB": 2,
+	"USD": 2,
+}
+
+var defaultCurrency = "IDR"
+
+// SetDefaultCurrency sets the store currency: amounts read from the
+// database or sent as bare numbers are in this currency. It is meant to
+// be called once at boot, before serving.
+func SetDefaultCurrency(code string) error {
+	code = strings.ToUpper(code)
+	if _, ok := currencyExponents[code]; !ok {
+		return fmt.Errorf("unsupported currency %q", code)
+	}
+	defaultCurrency = code
+	return nil
+}
+
+// DefaultCurrency is the store currency, IDR unless configured.
+func DefaultCurrency() string {
+	return defaultCurrency
+}
+
+// Money is an amount in minor units of an ISO 4217 currency. Arithmetic
+// that can outgrow int64 is checked and fails with ErrAmountOverflow
+// instead of wrapping around. The zero value is zero in the store
+// currency.
+//
+// In JSON it is {"amount": 11100, "currency": "IDR"}; a bare number is
+// accepted as an amount in the store currency. In the database it is a
+// BIGINT column in the store currency.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// NewMoney returns amount minor units of the store currency.
+func NewMoney(amount int64) Money {
+	return Money{Amount: amount, Currency: DefaultCurrency()}
+}
+
+func (m Money) currency() string {
+	if m.Currency == "" {
+		return DefaultCurrency()
+	}
+	return m.Currency
+}
+
+func (m Money) with(amount int64) Money {
+	return Money{Amount: amount, Currency: m.currency()}
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.currency() != other.currency() {
+		return Money{}, errCurrencyMismatch
+	}
+	if (other.Amount > 0 && m.Amount > math.MaxInt64-other.Amount) ||
+		(other.Amount < 0 && m.Amount < math.MinInt64-other.Amount) {
+		return Money{}, ErrAmountOverflow
+	}
+	return m.with(m.Amount + other.Amount), nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if m.currency() != other.currency() {
+		return Money{}, errCurrencyMismatch
+	}
+	if (other.Amount < 0 && m.Amount > math.MaxInt64+other.Amount) ||
+		(other.Amount > 0 && m.Amount < math.MinInt64+other.Amount) {
+		return Money{}, ErrAmountOverflow
+	}
+	return m.with(m.Amount - other.Amount), nil
+}
+
+// Mul multiplies by a quantity.
+func (m Money) Mul(n int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(n))
+	if !product.IsInt64() {
+		return Money{}, ErrAmountOverflow
+	}
+	return m.with(product.Int64()), nil
+}
+
+// Fraction returns num/den of the amount, rounded with rounding (half up
+// by default; rounding is symmetric around zero). num must be between 0
+// and den, so the result never outgrows the amount.
+func (m Money) Fraction(num, den int64, rounding string) Money {
+	if den <= 0 || num < 0 || num > den {
+		panic(fmt.Sprintf("model: fraction %d/%d out of range", num, den))
+	}
+
+	numerator := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(num))
+	negative := numerator.Sign() < 0
+	numerator.Abs(numerator)
+	quotient, remainder := new(big.Int).QuoRem(numerator, big.NewInt(den), new(big.Int))
+	if remainder.Sign() != 0 {
+		switch rounding {
+		case RoundDown:
+		case RoundUp:
+			quotient.Add(quotient, big.NewInt(1))
+		default:
+			if remainder.Lsh(remainder, 1).Cmp(big.NewInt(den)) >= 0 {
+				quotient.Add(quotient, big.NewInt(1))
+			}
+		}
+	}
+	if negative {
+		quotient.Neg(quotient)
+	}
+	return m.with(quotient.Int64())
+}
+
+// Min returns the smaller of two amounts of the same currency. It panics
+// when the currencies differ, since neither amount could be returned.
+func (m Money) Min(other Money) Money {
+	if m.currency() != other.currency() {
+		panic(errCurrencyMismatch)
+	}
+	if other.Amount < m.Amount {
+		return m.with(other.Amount)
+	}
+	return m.with(m.Amount)
+}
+
+// Sum adds amounts up, failing on overflow.
+func Sum(amounts ...Money) (Money, error) {
+	total := NewMoney(0)
+	for _, amount := range amounts {
+		var err error
+		if total, err = total.Add(amount); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// Exponent is the number of decimals of the currency's minor unit.
+func (m Money) Exponent() int {
+	return currencyExponents[m.currency()]
+}
+
+// String formats the amount in major units, e.g. "USD 12.50".
+func (m Money) String() string {
+	exponent := m.Exponent()
+	if exponent == 0 {
+		return fmt.Sprintf("%s %d", m.currency(), m.Amount)
+	}
+	value := new(big.Rat).SetFrac(big.NewInt(m.Amount), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil))
+	return fmt.Sprintf("%s %s", m.currency(), value.FloatString(exponent))
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	type plain Money
+	return json.Marshal(plain(m.with(m.Amount)))
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] != '{' {
+		var amount int64
+		if err := json.Unmarshal(data, &amount); err != nil {
+			return err
+		}
+		*m = NewMoney(amount)
+		return nil
+	}
+
+	type plain Money
+	var decoded plain
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	decoded.Currency = strings.ToUpper(decoded.Currency)
+	if decoded.Currency == "" {
+		decoded.Currency = DefaultCurrency()
+	}
+	if decoded.Currency != DefaultCurrency() {
+		return fmt.Errorf("amounts must be in %s, got %s", DefaultCurrency(), decoded.Currency)
+	}
+	*m = Money(decoded)
+	return nil
+}
+
+// Value stores the amount; the currency is the store's.
+func (m Money) Value() (driver.Value, error) {
+	return m.Amount, nil
+}
+
+// Scan reads an integer column or a NUMERIC aggregate such as SUM, which
+// Postgres widens so it cannot overflow; a sum beyond int64 is reported
+// as ErrAmountOverflow. NULL reads as zero.
+func (m *Money) Scan(src interface{}) error {
+	var text string
+	switch v := src.(type) {
+	case nil:
+		*m = NewMoney(0)
+		return nil
+	case int64:
+		*m = NewMoney(v)
+		return nil
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	default:
+		return fmt.Errorf("scan money: unsupported type %T", src)
+	}
+
+	amount, err := strconv.ParseInt(text, 10, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return ErrAmountOverflow
+	}
+	if err != nil {
+		return fmt.Errorf("scan money: %w", err)
+	}
+	*m = NewMoney(amount)
+	return nil
+}
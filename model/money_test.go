@@ -0,0 +1,237 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func usd(amount int64) Money { return Money{Amount: amount, Currency: "USD"} }
+
+func TestMoneyArithmetic(t *testing.T) {
+	tests := []struct {
+		name    string
+		op      func() (Money, error)
+		want    int64
+		wantErr error
+	}{
+		{"add", func() (Money, error) { return NewMoney(7).Add(NewMoney(5)) }, 12, nil},
+		{"add negative", func() (Money, error) { return NewMoney(7).Add(NewMoney(-9)) }, -2, nil},
+		{"add up to max", func() (Money, error) { return NewMoney(math.MaxInt64 - 1).Add(NewMoney(1)) }, math.MaxInt64, nil},
+		{"add past max", func() (Money, error) { return NewMoney(math.MaxInt64).Add(NewMoney(1)) }, 0, ErrAmountOverflow},
+		{"add past min", func() (Money, error) { return NewMoney(math.MinInt64).Add(NewMoney(-1)) }, 0, ErrAmountOverflow},
+		{"add other currency", func() (Money, error) { return NewMoney(1).Add(usd(1)) }, 0, errCurrencyMismatch},
+		{"add zero value", func() (Money, error) { return Money{}.Add(NewMoney(3)) }, 3, nil},
+		{"sub", func() (Money, error) { return NewMoney(7).Sub(NewMoney(9)) }, -2, nil},
+		{"sub down to min", func() (Money, error) { return NewMoney(math.MinInt64 + 1).Sub(NewMoney(1)) }, math.MinInt64, nil},
+		{"sub past min", func() (Money, error) { return NewMoney(math.MinInt64).Sub(NewMoney(1)) }, 0, ErrAmountOverflow},
+		{"sub negative past max", func() (Money, error) { return NewMoney(math.MaxInt64).Sub(NewMoney(-1)) }, 0, ErrAmountOverflow},
+		{"sub other currency", func() (Money, error) { return usd(1).Sub(NewMoney(1)) }, 0, errCurrencyMismatch},
+		{"mul", func() (Money, error) { return NewMoney(8000).Mul(3) }, 24000, nil},
+		{"mul by zero", func() (Money, error) { return NewMoney(math.MaxInt64).Mul(0) }, 0, nil},
+		{"mul negative", func() (Money, error) { return NewMoney(-8000).Mul(3) }, -24000, nil},
+		{"mul up to max", func() (Money, error) { return NewMoney(math.MaxInt64).Mul(1) }, math.MaxInt64, nil},
+		{"mul past max", func() (Money, error) { return NewMoney(math.MaxInt64/2 + 1).Mul(2) }, 0, ErrAmountOverflow},
+		{"mul past min", func() (Money, error) { return NewMoney(math.MinInt64).Mul(-1) }, 0, ErrAmountOverflow},
+		{"sum", func() (Money, error) { return Sum(NewMoney(1), NewMoney(2), NewMoney(3)) }, 6, nil},
+		{"sum past max", func() (Money, error) { return Sum(NewMoney(math.MaxInt64), NewMoney(-1), NewMoney(2)) }, 0, ErrAmountOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("want error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if got.Amount != tt.want || got.Currency != DefaultCurrency() {
+				t.Errorf("want %d %s, got %+v", tt.want, DefaultCurrency(), got)
+			}
+		})
+	}
+}
+
+func TestMoneyFraction(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		num, den int64
+		rounding string
+		want     int64
+	}{
+		{"exact", 1000, 11, 100, RoundHalfUp, 110},
+		{"half up at a half", 5, 1, 2, RoundHalfUp, 3},
+		{"half up below a half", 1049, 1, 100, RoundHalfUp, 10},
+		{"half up at a half of a hundred", 1050, 1, 100, RoundHalfUp, 11},
+		{"down at a half", 5, 1, 2, RoundDown, 2},
+		{"up just above", 1001, 1, 100, RoundUp, 11},
+		{"up exact", 1000, 1, 100, RoundUp, 10},
+		{"default is half up", 5, 1, 2, "", 3},
+		{"negative half up", -5, 1, 2, RoundHalfUp, -3},
+		{"negative down", -5, 1, 2, RoundDown, -2},
+		{"negative up", -5, 1, 2, RoundUp, -3},
+		{"zero fraction", 999, 0, 7, RoundUp, 0},
+		{"whole", 999, 7, 7, RoundDown, 999},
+		{"no intermediate overflow", math.MaxInt64, 9999, 10000, RoundDown, 9222449699651090329},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewMoney(tt.amount).Fraction(tt.num, tt.den, tt.rounding); got.Amount != tt.want {
+				t.Errorf("want %d, got %d", tt.want, got.Amount)
+			}
+		})
+	}
+}
+
+func TestMoneyFractionOutOfRange(t *testing.T) {
+	for _, fraction := range [][2]int64{{1, 0}, {1, -2}, {-1, 2}, {3, 2}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("fraction %d/%d did not panic", fraction[0], fraction[1])
+				}
+			}()
+			NewMoney(100).Fraction(fraction[0], fraction[1], RoundHalfUp)
+		}()
+	}
+}
+
+func TestMoneyMin(t *testing.T) {
+	tests := []struct {
+		name        string
+		left, right Money
+		want        Money
+	}{
+		{"left smaller", NewMoney(100), NewMoney(200), NewMoney(100)},
+		{"right smaller", NewMoney(200), NewMoney(-5), NewMoney(-5)},
+		{"equal", NewMoney(7), NewMoney(7), NewMoney(7)},
+		{"unset currency is the default", Money{Amount: 300}, NewMoney(250), NewMoney(250)},
+		{"same other currency", Money{Amount: 1, Currency: "USD"}, Money{Amount: 2, Currency: "USD"}, Money{Amount: 1, Currency: "USD"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.left.Min(tt.right); got != tt.want {
+				t.Errorf("want %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMoneyMinCurrencyMismatch(t *testing.T) {
+	defer func() {
+		if got := recover(); got != errCurrencyMismatch {
+			t.Errorf("want a currency mismatch panic, got %v", got)
+		}
+	}()
+	NewMoney(100).Min(Money{Amount: 50, Currency: "USD"})
+}
+
+func TestMoneyScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     any
+		want    int64
+		wantErr bool
+	}{
+		{"null", nil, 0, false},
+		{"bigint", int64(-42), -42, false},
+		{"numeric sum", []byte("123456789"), 123456789, false},
+		{"text", "77", 77, false},
+		{"max", []byte("9223372036854775807"), math.MaxInt64, false},
+		{"sum past int64", []byte("9223372036854775808"), 0, true},
+		{"fractional", []byte("1.5"), 0, true},
+		{"float", 1.5, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMoney(99)
+			err := m.Scan(tt.src)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("want an error, got %+v", m)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			if m.Amount != tt.want || m.Currency != DefaultCurrency() {
+				t.Errorf("want %d %s, got %+v", tt.want, DefaultCurrency(), m)
+			}
+		})
+	}
+
+	var m Money
+	if err := m.Scan([]byte("9223372036854775808")); !errors.Is(err, ErrAmountOverflow) {
+		t.Errorf("want ErrAmountOverflow, got %v", err)
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    int64
+		wantErr bool
+	}{
+		{"bare number", `11100`, 11100, false},
+		{"negative bare number", ` -5 `, -5, false},
+		{"object", `{"amount": 11100, "currency": "IDR"}`, 11100, false},
+		{"lowercase currency", `{"amount": 1, "currency": "idr"}`, 1, false},
+		{"object without currency", `{"amount": 7}`, 7, false},
+		{"other currency", `{"amount": 1, "currency": "USD"}`, 0, true},
+		{"fractional", `1.5`, 0, true},
+		{"string", `"100"`, 0, true},
+		{"past int64", `9223372036854775808`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Money
+			err := json.Unmarshal([]byte(tt.data), &m)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("want an error, got %+v", m)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if m.Amount != tt.want || m.Currency != DefaultCurrency() {
+				t.Errorf("want %d %s, got %+v", tt.want, DefaultCurrency(), m)
+			}
+		})
+	}
+
+	// the zero value is written with the store currency
+	data, err := json.Marshal(Money{Amount: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"amount":5,"currency":"IDR"}`; string(data) != want {
+		t.Errorf("want %s, got %s", want, data)
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		money    Money
+		want     string
+		exponent int
+	}{
+		{NewMoney(11100), "IDR 11100", 0},
+		{Money{Amount: 1250, Currency: "USD"}, "USD 12.50", 2},
+		{Money{Amount: -5, Currency: "USD"}, "USD -0.05", 2},
+		{Money{Amount: 500, Currency: "JPY"}, "JPY 500", 0},
+	}
+	for _, tt := range tests {
+		if got := tt.money.String(); got != tt.want {
+			t.Errorf("want %s, got %s", tt.want, got)
+		}
+		if got := tt.money.Exponent(); got != tt.exponent {
+			t.Errorf("%s: want exponent %d, got %d", tt.want, tt.exponent, got)
+		}
+	}
+}
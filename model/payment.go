@@ -0,0 +1,47 @@
+package model
+
+// Payment methods.
+const (
+	// PaymentCash is the only method that gives change.
+	PaymentCash    = "cash"
+	PaymentCard    = "card"
+	PaymentQRIS    = "qris"
+	PaymentEWallet = "ewallet"
+)
+
+// PaymentMethods lists every payment method in the order reports show
+// them.
+var PaymentMethods = []string{PaymentCash, PaymentCard, PaymentQRIS, PaymentEWallet}
+
+// PaymentRequest is one tender offered at checkout. For cash Amount is
+// what the customer handed over; card, QRIS and e-wallet payments are
+// charged exactly Amount and carry the Reference of the slip or transfer.
+type PaymentRequest struct {
+	Method    string `json:"method" validate:"required,oneof=cash card qris ewallet"`
+	Amount    Money  `json:"amount" validate:"gt=0"`
+	Reference string `json:"reference" validate:"max=255"`
+}
+
+// Payment is a tender recorded on a transaction. Tendered is what was
+// handed over, Change what was given back (cash only) and Amount the
+// difference, the part of the total it paid.
+type Payment struct {
+	ID            int     `json:"id" db:"id"`
+	TransactionID int     `json:"transaction_id" db:"transaction_id"`
+	Method        string  `json:"method" db:"method"`
+	Amount        Money   `json:"amount" db:"amount"`
+	Tendered      Money   `json:"tendered" db:"tendered"`
+	Change        Money   `json:"change" db:"change_amount"`
+	Reference     *string `json:"reference" db:"reference"`
+}
+
+// PaymentSales is one row of the per-payment-method breakdown. Amount is
+// what the method paid towards sales; for cash it is what stayed in the
+// drawer, Tendered minus Change.
+type PaymentSales struct {
+	Method   string `json:"method" db:"method"`
+	Payments int    `json:"payments" db:"payments"`
+	Amount   Money  `json:"amount" db:"amount"`
+	Tendered Money  `json:"tendered" db:"tendered"`
+	Change   Money  `json:"change" db:"change_amount"`
+}
@@ -1,9 +1,13 @@
 package model
 
+import "time"
+
 type Product struct {
 	ID         int        `json:"id"`
 	Name       string     `json:"name"`
-	Price      int        `json:"price"`
+	Price      Money      `json:"price"`
 	Stock      int        `json:"stock"`
+	TaxClassID *int       `json:"tax_class_id" db:"tax_class_id"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 	Categories []Category `json:"categories"`
 }
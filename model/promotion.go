@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// Promotion types.
+const (
+	// PromotionPercentage takes Value percent off every matching unit.
+	PromotionPercentage = "percentage"
+	// PromotionFixed takes Value off every matching unit, down to zero.
+	PromotionFixed = "fixed"
+	// PromotionBuyXGetY gives GetQuantity units free for every
+	// BuyQuantity+GetQuantity units of a matching product.
+	PromotionBuyXGetY = "buy_x_get_y"
+	// PromotionBasket takes Value off the matching part of the basket.
+	PromotionBasket = "basket"
+)
+
+// Promotion is a discount applied at checkout. It matches the lines of
+// ProductID, of the products in CategoryID, or every line when neither is
+// set, and only when those lines add up to at least MinSubtotal. It runs
+// while active and within [StartsAt, EndsAt); open ends are unbounded.
+// Value is a percentage or an amount in minor units, depending on Type.
+type Promotion struct {
+	ID          int        `json:"id" db:"id"`
+	Name        string     `json:"name" db:"name"`
+	Type        string     `json:"type" db:"type"`
+	Value       int64      `json:"value" db:"value"`
+	ProductID   *int       `json:"product_id" db:"product_id"`
+	CategoryID  *int       `json:"category_id" db:"category_id"`
+	BuyQuantity int        `json:"buy_quantity" db:"buy_quantity"`
+	GetQuantity int        `json:"get_quantity" db:"get_quantity"`
+	MinSubtotal Money      `json:"min_subtotal" db:"min_subtotal"`
+	StartsAt    *time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at" db:"ends_at"`
+	Active      bool       `json:"active" db:"active"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+// RunsAt reports whether the promotion applies to a sale made at t.
+func (p *Promotion) RunsAt(t time.Time) bool {
+	if !p.Active || p.DeletedAt != nil {
+		return false
+	}
+	if p.StartsAt != nil && t.Before(*p.StartsAt) {
+		return false
+	}
+	return p.EndsAt == nil || t.Before(*p.EndsAt)
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPromotionRunsAt(t *testing.T) {
+	now := time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC)
+	before := now.Add(-time.Hour)
+	after := now.Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		promotion Promotion
+		want      bool
+	}{
+		{"open ended", Promotion{Active: true}, true},
+		{"inactive", Promotion{Active: false}, false},
+		{"deleted", Promotion{Active: true, DeletedAt: &before}, false},
+		{"within its window", Promotion{Active: true, StartsAt: &before, EndsAt: &after}, true},
+		{"not started", Promotion{Active: true, StartsAt: &after}, false},
+		{"starts now", Promotion{Active: true, StartsAt: &now}, true},
+		{"expired", Promotion{Active: true, EndsAt: &before}, false},
+		{"ends now", Promotion{Active: true, EndsAt: &now}, false},
+		{"inactive within its window", Promotion{StartsAt: &before, EndsAt: &after}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.promotion.RunsAt(now); got != tt.want {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
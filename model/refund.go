@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+const (
+	RefundTypeRefund = "refund"
+	RefundTypeVoid   = "void"
+)
+
+// Refund is the document recorded when goods from a transaction are
+// returned (refund) or the whole sale is cancelled shortly after it was
+// made (void). CashierID is the user who made it and ShiftID the shift
+// they had open, if any, whose drawer any cash is paid out of.
+type Refund struct {
+	ID            int            `json:"id" db:"id"`
+	TransactionID int            `json:"transaction_id" db:"transaction_id"`
+	CashierID     *int           `json:"cashier_id" db:"cashier_id"`
+	ShiftID       *int           `json:"shift_id" db:"shift_id"`
+	Type          string         `json:"type" db:"type"`
+	Reason        string         `json:"reason" db:"reason"`
+	TotalAmount   Money          `json:"total_amount" db:"total_amount"`
+	TaxAmount     Money          `json:"tax_amount" db:"tax_amount"`
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+	Details       []RefundDetail `json:"details" db:"-"`
+}
+
+type RefundDetail struct {
+	ID                  int   `json:"id" db:"id"`
+	RefundID            int   `json:"refund_id" db:"refund_id"`
+	TransactionDetailID int   `json:"transaction_detail_id" db:"transaction_detail_id"`
+	ProductID           int   `json:"product_id" db:"product_id"`
+	Quantity            int   `json:"quantity" db:"quantity"`
+	Amount              Money `json:"amount" db:"amount"`
+	// TaxAmount is the part of Amount that was tax.
+	TaxAmount Money `json:"tax_amount" db:"tax_amount"`
+}
+
+type RefundItem struct {
+	TransactionDetailID int `json:"transaction_detail_id" validate:"gt=0"`
+	Quantity            int `json:"quantity" validate:"gt=0"`
+}
+
+// RefundRequest refunds the listed lines; with no items every remaining
+// quantity of the transaction is refunded.
+type RefundRequest struct {
+	Items  []RefundItem `json:"items" validate:"unique=TransactionDetailID,dive"`
+	Reason string       `json:"reason" validate:"max=500"`
+}
+
+type VoidRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// Shift is a stint at the cash drawer. It opens with an OpeningFloat of
+// change in the drawer and closes with the cash counted in it. Checkouts
+// and refunds made by CashierID while it is open are tied to it; those
+// without a cashier share the shift opened without one. Reconciliation is
+// set once the shift is closed.
+type Shift struct {
+	ID           int        `json:"id" db:"id"`
+	CashierID    *int       `json:"cashier_id" db:"cashier_id"`
+	OpeningFloat Money      `json:"opening_float" db:"opening_float"`
+	OpenedAt     time.Time  `json:"opened_at" db:"opened_at"`
+	ClosedAt     *time.Time `json:"closed_at" db:"closed_at"`
+	// Note is left when closing, e.g. to explain a variance.
+	Note           string               `json:"note" db:"note"`
+	Reconciliation *ShiftReconciliation `json:"reconciliation" db:"-"`
+}
+
+// ShiftReconciliation compares the cash a drawer should hold with what
+// was counted when its shift closed. ExpectedCash is OpeningFloat plus
+// CashSales minus CashRefunds, and Variance is CountedCash minus
+// ExpectedCash, negative when cash is missing.
+type ShiftReconciliation struct {
+	OpeningFloat Money `json:"opening_float"`
+	// CashSales is what the shift's sales took in cash, after change.
+	CashSales Money `json:"cash_sales"`
+	// CashRefunds is what refunds of the shift's sales paid back in cash
+	// before it closed.
+	CashRefunds  Money `json:"cash_refunds"`
+	ExpectedCash Money `json:"expected_cash"`
+	CountedCash  Money `json:"counted_cash"`
+	Variance     Money `json:"variance"`
+}
+
+type OpenShiftRequest struct {
+	OpeningFloat Money `json:"opening_float" validate:"gte=0"`
+}
+
+type CloseShiftRequest struct {
+	CountedCash Money  `json:"counted_cash" validate:"gte=0"`
+	Note        string `json:"note" validate:"max=500"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// TaxClass is a tax rate that can be assigned to products or categories.
+type TaxClass struct {
+	ID   int    `json:"id" db:"id"`
+	Name string `json:"name" db:"name" validate:"notblank,max=255"`
+	// RateBps is the rate in basis points: 1100 is 11%.
+	RateBps   int       `json:"rate_bps" db:"rate_bps" validate:"gte=0,lte=10000"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Price modes.
+const (
+	// PriceModeInclusive means product prices already contain the tax.
+	PriceModeInclusive = "inclusive"
+	// PriceModeExclusive means the tax is added on top of product prices.
+	PriceModeExclusive = "exclusive"
+)
+
+// TaxPolicy is how checkout computes tax. Rounding is one of the Round
+// modes and applies to the tax of each line.
+type TaxPolicy struct {
+	Inclusive bool
+	Rounding  string
+}
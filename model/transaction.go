@@ -2,37 +2,192 @@ package model
 
 import "time"
 
+// Transaction is a sale. SubtotalAmount is the sum of the detail
+// subtotals, DiscountAmount and TaxAmount the sums of the detail discounts
+// and taxes, and TotalAmount, the grand total, the sum of the detail
+// totals. With TaxInclusive prices already contained the tax. PromotionID
+// is the basket promotion applied, if any. Payments add up to TotalAmount.
+// ShiftID is the cashier's shift that was open at checkout, if any.
 type Transaction struct {
-	ID          int                 `json:"id" db:"id"`
-	TotalAmount int                 `json:"total_amount" db:"total_amount"`
-	CreatedAt   time.Time           `json:"created_at" db:"created_at"`
-	Details     []TransactionDetail `json:"details" db:"-"`
+	ID             int                 `json:"id" db:"id"`
+	SubtotalAmount Money               `json:"subtotal_amount" db:"subtotal_amount"`
+	DiscountAmount Money               `json:"discount_amount" db:"discount_amount"`
+	TaxAmount      Money               `json:"tax_amount" db:"tax_amount"`
+	TotalAmount    Money               `json:"total_amount" db:"total_amount"`
+	TaxInclusive   bool                `json:"tax_inclusive" db:"tax_inclusive"`
+	PromotionID    *int                `json:"promotion_id" db:"promotion_id"`
+	CreatedAt      time.Time           `json:"created_at" db:"created_at"`
+	VoidedAt       *time.Time          `json:"voided_at,omitempty" db:"voided_at"`
+	CashierID      *int                `json:"cashier_id" db:"cashier_id"`
+	ShiftID        *int                `json:"shift_id" db:"shift_id"`
+	Details        []TransactionDetail `json:"details" db:"-"`
+	Payments       []Payment           `json:"payments" db:"-"`
 }
 
+// TransactionDetail is one line of a sale. ProductName, UnitPrice and
+// TaxRateBps are snapshots taken at checkout, so later changes to the
+// product do not rewrite history. Subtotal is before discounts;
+// DiscountAmount holds the line promotion (PromotionID) plus the line's
+// share of a basket promotion. TotalAmount is what was charged for the
+// line, tax included.
 type TransactionDetail struct {
-	ID            int    `json:"id" db:"id"`
-	TransactionID int    `json:"transaction_id" db:"transaction_id"`
-	ProductID     int    `json:"product_id" db:"product_id"`
-	ProductName   string `json:"product_name,omitempty" db:"product_name"`
-	Quantity      int    `json:"quantity" db:"quantity"`
-	Subtotal      int    `json:"subtotal" db:"subtotal"`
+	ID             int    `json:"id" db:"id"`
+	TransactionID  int    `json:"transaction_id" db:"transaction_id"`
+	ProductID      int    `json:"product_id" db:"product_id"`
+	ProductName    string `json:"product_name" db:"product_name"`
+	UnitPrice      Money  `json:"unit_price" db:"unit_price"`
+	Quantity       int    `json:"quantity" db:"quantity"`
+	Subtotal       Money  `json:"subtotal" db:"subtotal"`
+	DiscountAmount Money  `json:"discount_amount" db:"discount_amount"`
+	PromotionID    *int   `json:"promotion_id" db:"promotion_id"`
+	TaxRateBps     int    `json:"tax_rate_bps" db:"tax_rate_bps"`
+	TaxAmount      Money  `json:"tax_amount" db:"tax_amount"`
+	TotalAmount    Money  `json:"total_amount" db:"total_amount"`
+}
+
+// Net is the line subtotal after discounts, before exclusive tax. The
+// discount never exceeds the subtotal, so this cannot overflow.
+func (d *TransactionDetail) Net() Money {
+	return Money{Amount: d.Subtotal.Amount - d.DiscountAmount.Amount, Currency: d.Subtotal.Currency}
+}
+
+// TransactionLine is one transaction detail together with its
+// transaction, the row shape of transaction exports.
+type TransactionLine struct {
+	TransactionID  int        `json:"transaction_id" db:"transaction_id"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	VoidedAt       *time.Time `json:"voided_at" db:"voided_at"`
+	CashierID      *int       `json:"cashier_id" db:"cashier_id"`
+	TotalAmount    Money      `json:"total_amount" db:"total_amount"`
+	DetailID       int        `json:"detail_id" db:"detail_id"`
+	ProductID      int        `json:"product_id" db:"product_id"`
+	ProductName    string     `json:"product_name" db:"product_name"`
+	UnitPrice      Money      `json:"unit_price" db:"unit_price"`
+	Quantity       int        `json:"quantity" db:"quantity"`
+	Subtotal       Money      `json:"subtotal" db:"subtotal"`
+	DiscountAmount Money      `json:"discount_amount" db:"discount_amount"`
+	TaxAmount      Money      `json:"tax_amount" db:"tax_amount"`
+	LineTotal      Money      `json:"line_total" db:"line_total"`
 }
 
 type CheckoutItem struct {
-	ProductID int `json:"product_id"`
-	Quantity  int `json:"quantity"`
+	ProductID int `json:"product_id" validate:"gt=0"`
+	Quantity  int `json:"quantity" validate:"gt=0"`
 }
 
+// CheckoutRequest is a sale to ring up. Payments must cover the total;
+// any excess is given back as change from the cash payments. A sale that
+// comes to nothing needs no payments, which is why an empty list is only
+// rejected once the total is known.
 type CheckoutRequest struct {
-	Items []CheckoutItem `json:"items"`
+	Items    []CheckoutItem   `json:"items" validate:"required,min=1,unique=ProductID,dive"`
+	Payments []PaymentRequest `json:"payments" validate:"dive"`
 }
+
 type ProductTerlaris struct {
 	Nama       string `json:"nama" db:"product_name"`
 	QtyTerjual int    `json:"qty_terjual" db:"total_qty"`
 }
 
+// ReportFilter selects the sales a report aggregates.
+type ReportFilter struct {
+	// StartDate and EndDate bound created_at as [StartDate, EndDate).
+	StartDate time.Time
+	EndDate   time.Time
+	// ShiftID, when set, replaces the date range: the report covers the
+	// sales and refunds made during that shift.
+	ShiftID *int
+	// Top is the length of each best-seller list.
+	Top int
+}
+
 type Report struct {
-	TotalRevenue    int             `json:"total_revenue" db:"total_revenue"`
-	TotalTransaks   int             `json:"total_transaksi" db:"total_transaksi"`
+	TotalRevenue Money `json:"total_revenue" db:"total_revenue"`
+	// TotalDiscounts is what promotions took off; TotalRevenue is net of it
+	// and includes tax.
+	TotalDiscounts Money `json:"total_discounts" db:"total_discounts"`
+	GrossSales     Money `json:"gross_sales" db:"-"`
+	TotalRefunds   Money `json:"total_refunds" db:"-"`
+	NetRevenue     Money `json:"net_revenue" db:"-"`
+	// TotalTax is the tax charged on the sales, TaxRefunded the part of it
+	// given back by refunds and NetTax what remains.
+	TotalTax      Money `json:"total_tax" db:"total_tax"`
+	TaxRefunded   Money `json:"tax_refunded" db:"-"`
+	NetTax        Money `json:"net_tax" db:"-"`
+	TotalTransaks int   `json:"total_transaksi" db:"total_transaksi"`
+	ItemsSold     int   `json:"items_sold" db:"-"`
+	// AverageBasketSize is the number of items per transaction and
+	// AverageBasketValue the revenue per transaction.
+	AverageBasketSize  float64 `json:"average_basket_size" db:"-"`
+	AverageBasketValue Money   `json:"average_basket_value" db:"-"`
+	// ProductTerlaris is the first entry of TopByQuantity.
 	ProductTerlaris ProductTerlaris `json:"product_terlaris"`
+	TopByQuantity   []ProductSales  `json:"top_by_quantity" db:"-"`
+	TopByRevenue    []ProductSales  `json:"top_by_revenue" db:"-"`
+	Categories      []CategorySales `json:"categories" db:"-"`
+	Cashiers        []CashierSales  `json:"cashiers" db:"-"`
+	// Payments has one row per payment method, in PaymentMethods order.
+	// Refunds are not deducted.
+	Payments []PaymentSales `json:"payments" db:"-"`
+}
+
+// ProductSales is one best-seller entry. ProductName is the name recorded
+// on the product's most recent sale in the range. Revenue is what was
+// charged, after discounts and with tax, here and in CategorySales.
+type ProductSales struct {
+	ProductID   int    `json:"product_id" db:"product_id"`
+	ProductName string `json:"product_name" db:"product_name"`
+	Quantity    int    `json:"quantity" db:"quantity"`
+	Revenue     Money  `json:"revenue" db:"revenue"`
+}
+
+// CategorySales is one row of the per-category breakdown. A product in
+// several categories counts towards each of them, so the rows can add up
+// to more than the total. Uncategorised products have a nil CategoryID.
+type CategorySales struct {
+	CategoryID   *int   `json:"category_id" db:"category_id"`
+	CategoryName string `json:"category_name" db:"category_name"`
+	Quantity     int    `json:"quantity" db:"quantity"`
+	Revenue      Money  `json:"revenue" db:"revenue"`
+}
+
+// CashierSales is one row of the per-cashier breakdown. Sales without a
+// cashier (e.g. made with an API key) have a nil CashierID.
+type CashierSales struct {
+	CashierID      *int   `json:"cashier_id" db:"cashier_id"`
+	CashierName    string `json:"cashier_name" db:"cashier_name"`
+	TotalRevenue   Money  `json:"total_revenue" db:"total_revenue"`
+	TotalTransaksi int    `json:"total_transaksi" db:"total_transaksi"`
+}
+
+// Timeseries bucket sizes.
+const (
+	IntervalHour  = "hour"
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// TimeseriesFilter selects the sales a time series covers. Buckets start
+// on Location's wall clock; weeks start on Monday.
+type TimeseriesFilter struct {
+	// StartDate and EndDate bound created_at as [StartDate, EndDate).
+	StartDate time.Time
+	EndDate   time.Time
+	Interval  string
+	Location  *time.Location
+}
+
+type Timeseries struct {
+	Interval string        `json:"interval"`
+	Timezone string        `json:"timezone"`
+	Buckets  []SalesBucket `json:"buckets"`
+}
+
+// SalesBucket aggregates the sales of one interval starting at Start.
+type SalesBucket struct {
+	Start          time.Time `json:"start" db:"bucket"`
+	TotalRevenue   Money     `json:"total_revenue" db:"total_revenue"`
+	TotalTransaksi int       `json:"total_transaksi" db:"total_transaksi"`
+	ItemsSold      int       `json:"items_sold" db:"items_sold"`
 }
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+type User struct {
+	ID           int       `json:"id" db:"id"`
+	Username     string    `json:"username" db:"username"`
+	Name         string    `json:"name" db:"name"`
+	Role         string    `json:"role" db:"role"`
+	Active       bool      `json:"active" db:"active"`
+	PasswordHash string    `json:"-" db:"password_hash"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
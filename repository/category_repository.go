@@ -1,74 +1,108 @@
 package repository
 
 import (
+	"category-crud/apperror"
 	"category-crud/model"
+	"context"
 	"database/sql"
-	"errors"
+	"time"
 
 	"github.com/doug-martin/goqu/v9"
 )
 
-type CategoryRepository struct {
+var ErrCategoryNotFound = apperror.NotFound("kategori tidak ditemukan")
+
+type categoryRepository struct {
 	db      *sql.DB
 	builder *goqu.Database
+	timeout time.Duration
 }
 
-func NewCategoryRepository(db *sql.DB, builder *goqu.Database) *CategoryRepository {
-	return &CategoryRepository{
+func NewCategoryRepository(db *sql.DB, builder *goqu.Database, timeout time.Duration) CategoryRepository {
+	return &categoryRepository{
 		db:      db,
 		builder: builder,
+		timeout: timeout,
 	}
 }
 
-func (repo *CategoryRepository) GetAll() ([]model.Category, error) {
+func (repo *categoryRepository) GetAll(ctx context.Context, includeDeleted bool) ([]model.Category, error) {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	query := repo.builder.From("categories").
+		Select("id", "name", "description", "tax_class_id", "deleted_at").
+		Order(goqu.I("id").Asc())
+	if !includeDeleted {
+		query = query.Where(goqu.I("deleted_at").IsNull())
+	}
+
 	var categories []model.Category
-	err := repo.builder.From("categories").
-		Select("id", "name", "description").
-		ScanStructs(&categories)
+	err := query.ScanStructsContext(ctx, &categories)
 	if err != nil {
 		return nil, err
 	}
 	return categories, nil
 }
 
-func (repo *CategoryRepository) Create(category *model.Category) error {
+func (repo *categoryRepository) Create(ctx context.Context, category *model.Category) error {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
 	_, err := repo.builder.Insert("categories").Rows(
 		goqu.Record{
-			"name":        category.Name,
-			"description": category.Description,
+			"name":         category.Name,
+			"description":  category.Description,
+			"tax_class_id": category.TaxClassID,
 		},
-	).Returning("id").Executor().ScanStruct(category)
-	return err
+	).Returning("id").Executor().ScanStructContext(ctx, category)
+	return translateError(err)
 }
 
 // GetByID - ambil kategori by ID
-func (repo *CategoryRepository) GetByID(id int) (*model.Category, error) {
-	var category model.Category
-	result, err := repo.builder.From("categories").
-		Select("id", "name", "description").
+func (repo *categoryRepository) GetByID(ctx context.Context, id int, includeDeleted bool) (*model.Category, error) {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	query := repo.builder.From("categories").
+		Select("id", "name", "description", "tax_class_id", "deleted_at").
 		Where(goqu.Ex{
 			"id": id,
-		}).ScanStruct(&category)
-
-	if !result {
-		return nil, errors.New("category tidak ditemukan")
+		})
+	if !includeDeleted {
+		query = query.Where(goqu.I("deleted_at").IsNull())
 	}
+
+	var category model.Category
+	result, err := query.ScanStructContext(ctx, &category)
+
 	if err != nil {
 		return nil, err
 	}
+	if !result {
+		return nil, ErrCategoryNotFound
+	}
 
 	return &category, nil
 }
 
-func (repo *CategoryRepository) Update(category *model.Category) error {
+func (repo *categoryRepository) Update(ctx context.Context, category *model.Category) error {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
 	result, err := repo.builder.Update("categories").Set(
 		goqu.Record{
-			"name":        category.Name,
-			"description": category.Description,
+			"name":         category.Name,
+			"description":  category.Description,
+			"tax_class_id": category.TaxClassID,
 		},
 	).Where(goqu.Ex{
-		"id": category.ID,
-	}).Executor().Exec()
+		"id":         category.ID,
+		"deleted_at": nil,
+	}).Executor().ExecContext(ctx)
+	if err != nil {
+		return translateError(err)
+	}
 
 	rows, err := result.RowsAffected()
 	if err != nil {
@@ -76,27 +110,43 @@ func (repo *CategoryRepository) Update(category *model.Category) error {
 	}
 
 	if rows == 0 {
-		return errors.New("kategori tidak ditemukan")
+		return ErrCategoryNotFound
 	}
 
 	return err
 }
 
-func (repo *CategoryRepository) Delete(id int) error {
-	result, err := repo.builder.Delete("categories").Where(goqu.Ex{
-		"id": id,
-	}).Executor().Exec()
+// Delete soft-deletes the category. Its product links are kept so a
+// restore brings them back.
+func (repo *categoryRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	result, err := repo.builder.Update("categories").
+		Set(goqu.Record{"deleted_at": goqu.L("NOW()")}).
+		Where(goqu.Ex{
+			"id":         id,
+			"deleted_at": nil,
+		}).Executor().ExecContext(ctx)
 	if err != nil {
 		return err
 	}
-	rows, err := result.RowsAffected()
+
+	return expectRow(result, ErrCategoryNotFound)
+}
+
+// Restore undeletes the category. Restoring a live category is a no-op.
+func (repo *categoryRepository) Restore(ctx context.Context, id int) error {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	result, err := repo.builder.Update("categories").
+		Set(goqu.Record{"deleted_at": nil}).
+		Where(goqu.Ex{"id": id}).
+		Executor().ExecContext(ctx)
 	if err != nil {
 		return err
 	}
 
-	if rows == 0 {
-		return errors.New("kategori tidak ditemukan")
-	}
-
-	return err
+	return expectRow(result, ErrCategoryNotFound)
 }
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// withQueryTimeout bounds a single repository call by the configured
+// default query timeout. A shorter deadline already on ctx still wins,
+// and a zero timeout leaves ctx untouched.
+func withQueryTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
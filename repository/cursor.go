@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"category-crud/apperror"
+	"category-crud/model"
+	"category-crud/model/dto"
+	"encoding/base64"
+	"encoding/json"
+)
+
+var ErrInvalidCursor = apperror.BadRequest("invalid cursor")
+
+// ProductCursor is the keyset position after which the next page starts.
+// It carries every sortable column so any sort order can resume from it.
+type ProductCursor struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Price int64  `json:"price"`
+	Stock int    `json:"stock"`
+}
+
+func EncodeProductCursor(product model.Product) string {
+	payload, _ := json.Marshal(ProductCursor{
+		ID:    product.ID,
+		Name:  product.Name,
+		Price: product.Price.Amount,
+		Stock: product.Stock,
+	})
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+func DecodeProductCursor(cursor string) (*ProductCursor, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var decoded ProductCursor
+	if err := json.Unmarshal(payload, &decoded); err != nil || decoded.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &decoded, nil
+}
+
+// Value returns the cursor value of a sortable column.
+func (c *ProductCursor) Value(field string) interface{} {
+	switch field {
+	case "name":
+		return c.Name
+	case "price":
+		return c.Price
+	case "stock":
+		return c.Stock
+	default:
+		return c.ID
+	}
+}
+
+// ProductSort returns the requested order with id appended as the final
+// tie-breaker, so keyset pagination is stable.
+func ProductSort(sort []dto.SortField) []dto.SortField {
+	result := make([]dto.SortField, 0, len(sort)+1)
+	for _, field := range sort {
+		result = append(result, field)
+		if field.Field == "id" {
+			return result
+		}
+	}
+	return append(result, dto.SortField{Field: "id"})
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"category-crud/model"
+	"category-crud/model/dto"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestProductCursorRoundTrip(t *testing.T) {
+	product := model.Product{ID: 42, Name: "Kopi \"Tubruk\" / 1L", Price: model.NewMoney(1 << 40), Stock: 7}
+
+	cursor, err := DecodeProductCursor(EncodeProductCursor(product))
+	if err != nil {
+		t.Fatalf("DecodeProductCursor: %v", err)
+	}
+	want := ProductCursor{ID: 42, Name: product.Name, Price: 1 << 40, Stock: 7}
+	if *cursor != want {
+		t.Errorf("want %+v, got %+v", want, *cursor)
+	}
+}
+
+func TestDecodeProductCursorInvalid(t *testing.T) {
+	encode := func(payload string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(payload))
+	}
+	valid := EncodeProductCursor(model.Product{ID: 1, Name: "Kopi"})
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "!!!"},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"id":1}`))},
+		{"not json", encode("id=1")},
+		{"no id", encode(`{"name":"Kopi"}`)},
+		{"zero id", encode(`{"id":0,"name":"Kopi"}`)},
+		{"wrong type", encode(`{"id":"1"}`)},
+		{"truncated", valid[:len(valid)-3]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := DecodeProductCursor(tt.cursor)
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("want ErrInvalidCursor, got %+v, %v", cursor, err)
+			}
+		})
+	}
+}
+
+func TestProductSort(t *testing.T) {
+	tests := []struct {
+		name string
+		sort []dto.SortField
+		want []dto.SortField
+	}{
+		{
+			name: "default",
+			want: []dto.SortField{{Field: "id"}},
+		},
+		{
+			name: "id appended as tie-breaker",
+			sort: []dto.SortField{{Field: "price", Desc: true}, {Field: "name"}},
+			want: []dto.SortField{{Field: "price", Desc: true}, {Field: "name"}, {Field: "id"}},
+		},
+		{
+			name: "explicit id keeps its direction",
+			sort: []dto.SortField{{Field: "stock"}, {Field: "id", Desc: true}},
+			want: []dto.SortField{{Field: "stock"}, {Field: "id", Desc: true}},
+		},
+		{
+			name: "fields after id are unreachable",
+			sort: []dto.SortField{{Field: "id"}, {Field: "name"}},
+			want: []dto.SortField{{Field: "id"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ProductSort(tt.sort); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
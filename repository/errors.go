@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"category-crud/apperror"
+	"category-crud/model"
+	"database/sql"
+	"errors"
+	"sort"
+
+	"github.com/lib/pq"
+)
+
+// ErrIdempotencyKeyReused is returned when an Idempotency-Key is replayed
+// with a payload that differs from the one it was first used with.
+var ErrIdempotencyKeyReused = apperror.Unprocessable("idempotency key was already used with a different request", nil)
+
+const (
+	CheckoutReasonNotFound          = "product_not_found"
+	CheckoutReasonInsufficientStock = "insufficient_stock"
+)
+
+// CheckoutItemError describes a single checkout line that cannot be
+// fulfilled.
+type CheckoutItemError struct {
+	ProductID int    `json:"product_id"`
+	Reason    string `json:"reason"`
+	Requested int    `json:"requested"`
+	Available int    `json:"available"`
+}
+
+// checkoutError rejects a checkout as a whole. Unknown products make the
+// request unprocessable; otherwise it is a stock conflict.
+func checkoutError(items []CheckoutItemError) *apperror.Error {
+	for _, item := range items {
+		if item.Reason == CheckoutReasonNotFound {
+			return apperror.Unprocessable("one or more products do not exist", items)
+		}
+	}
+	return apperror.InsufficientStock("insufficient stock for one or more products", items)
+}
+
+// translateError turns constraint violations reported by Postgres into
+// typed errors and passes everything else through unchanged.
+func translateError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch pqErr.Code {
+	case "23503": // foreign_key_violation
+		return apperror.Wrap(err, apperror.CodeConflict, "data masih direferensikan atau referensi tidak valid")
+	case "23505": // unique_violation
+		return apperror.Wrap(err, apperror.CodeConflict, "data sudah ada")
+	case "23514": // check_violation
+		return apperror.Wrap(err, apperror.CodeValidation, "data tidak valid")
+	}
+	return err
+}
+
+// expectRow returns notFound when an UPDATE or DELETE matched no row.
+func expectRow(result sql.Result, notFound error) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// CheckStock verifies that every requested product exists and has enough
+// stock, summing quantities of repeated product IDs. products must hold
+// the rows as read under lock.
+func CheckStock(items []model.CheckoutItem, products map[int]model.Product) error {
+	requested := make(map[int]int, len(items))
+	for _, item := range items {
+		requested[item.ProductID] += item.Quantity
+	}
+
+	var failures []CheckoutItemError
+	for productID, quantity := range requested {
+		product, ok := products[productID]
+		if !ok {
+			failures = append(failures, CheckoutItemError{
+				ProductID: productID,
+				Reason:    CheckoutReasonNotFound,
+				Requested: quantity,
+			})
+			continue
+		}
+		if product.Stock < quantity {
+			failures = append(failures, CheckoutItemError{
+				ProductID: productID,
+				Reason:    CheckoutReasonInsufficientStock,
+				Requested: quantity,
+				Available: product.Stock,
+			})
+		}
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].ProductID < failures[j].ProductID
+	})
+	return checkoutError(failures)
+}
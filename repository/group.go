@@ -0,0 +1,12 @@
+package repository
+
+type RepositoryGroup struct {
+	Category    CategoryRepository
+	Product     ProductRepository
+	Promotion   PromotionRepository
+	Shift       ShiftRepository
+	TaxClass    TaxClassRepository
+	Transaction TransactionRepository
+	User        UserRepository
+	Health      HealthRepository
+}
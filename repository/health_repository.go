@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"category-crud/db"
+	"category-crud/model"
+	"context"
+	"database/sql"
+)
+
+type healthRepository struct {
+	db       *sql.DB
+	migrator *db.Migrator
+}
+
+func NewHealthRepository(conn *sql.DB, migrator *db.Migrator) HealthRepository {
+	return &healthRepository{
+		db:       conn,
+		migrator: migrator,
+	}
+}
+
+func (repo *healthRepository) Ping(ctx context.Context) error {
+	return repo.db.PingContext(ctx)
+}
+
+func (repo *healthRepository) Migrations(ctx context.Context) (*model.MigrationState, error) {
+	statuses, err := repo.migrator.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &model.MigrationState{Latest: repo.migrator.Latest()}
+	for _, status := range statuses {
+		if status.Applied {
+			state.Current = max(state.Current, status.Version)
+		} else {
+			state.Pending++
+		}
+	}
+
+	return state, nil
+}
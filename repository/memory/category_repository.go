@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"category-crud/model"
+	"category-crud/repository"
+	"context"
+	"sort"
+)
+
+type categoryRepository struct {
+	store *Store
+}
+
+func NewCategoryRepository(store *Store) repository.CategoryRepository {
+	return &categoryRepository{store: store}
+}
+
+func (repo *categoryRepository) GetAll(ctx context.Context, includeDeleted bool) ([]model.Category, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	categories := make([]model.Category, 0, len(repo.store.categories))
+	for _, category := range repo.store.categories {
+		if category.DeletedAt != nil && !includeDeleted {
+			continue
+		}
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].ID < categories[j].ID
+	})
+
+	return categories, nil
+}
+
+func (repo *categoryRepository) Create(ctx context.Context, category *model.Category) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if err := repo.store.checkTaxClass(category.TaxClassID); err != nil {
+		return err
+	}
+
+	category.ID = repo.store.nextID("categories")
+	repo.store.categories[category.ID] = *category
+
+	return nil
+}
+
+func (repo *categoryRepository) GetByID(ctx context.Context, id int, includeDeleted bool) (*model.Category, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	category, ok := repo.store.categories[id]
+	if !ok || (category.DeletedAt != nil && !includeDeleted) {
+		return nil, repository.ErrCategoryNotFound
+	}
+
+	return &category, nil
+}
+
+func (repo *categoryRepository) Update(ctx context.Context, category *model.Category) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if stored, ok := repo.store.categories[category.ID]; !ok || stored.DeletedAt != nil {
+		return repository.ErrCategoryNotFound
+	}
+	if err := repo.store.checkTaxClass(category.TaxClassID); err != nil {
+		return err
+	}
+	repo.store.categories[category.ID] = *category
+
+	return nil
+}
+
+func (repo *categoryRepository) Delete(ctx context.Context, id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	category, ok := repo.store.categories[id]
+	if !ok || category.DeletedAt != nil {
+		return repository.ErrCategoryNotFound
+	}
+	now := repo.store.now()
+	category.DeletedAt = &now
+	repo.store.categories[id] = category
+
+	return nil
+}
+
+func (repo *categoryRepository) Restore(ctx context.Context, id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	category, ok := repo.store.categories[id]
+	if !ok {
+		return repository.ErrCategoryNotFound
+	}
+	category.DeletedAt = nil
+	repo.store.categories[id] = category
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package memory
+
+import (
+	"category-crud/model"
+	"category-crud/repository"
+	"context"
+)
+
+type healthRepository struct{}
+
+func NewHealthRepository() repository.HealthRepository {
+	return &healthRepository{}
+}
+
+func (repo *healthRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (repo *healthRepository) Migrations(ctx context.Context) (*model.MigrationState, error) {
+	return nil, nil
+}
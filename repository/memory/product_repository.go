@@ -0,0 +1,270 @@
+package memory
+
+import (
+	"category-crud/apperror"
+	"category-crud/model"
+	"category-crud/model/dto"
+	"category-crud/repository"
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type productRepository struct {
+	store *Store
+}
+
+func NewProductRepository(store *Store) repository.ProductRepository {
+	return &productRepository{store: store}
+}
+
+func (repo *productRepository) GetAll(ctx context.Context, filter *dto.ProductFilterRequest) (*dto.ProductPage, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	name := strings.ToLower(filter.Name)
+	var products []model.Product
+	for _, product := range repo.store.products {
+		if product.DeletedAt != nil && !filter.IncludeDeleted {
+			continue
+		}
+		if name != "" && !strings.Contains(strings.ToLower(product.Name), name) {
+			continue
+		}
+		if len(filter.IDs) > 0 && !slices.Contains(filter.IDs, product.ID) {
+			continue
+		}
+		if len(filter.CategoryIDs) > 0 && !repo.store.inAnyCategory(product.ID, filter.CategoryIDs) {
+			continue
+		}
+		if filter.MinPrice != nil && product.Price.Amount < filter.MinPrice.Amount {
+			continue
+		}
+		if filter.MaxPrice != nil && product.Price.Amount > filter.MaxPrice.Amount {
+			continue
+		}
+		if filter.InStock != nil && (product.Stock > 0) != *filter.InStock {
+			continue
+		}
+		products = append(products, product)
+	}
+
+	order := repository.ProductSort(filter.Sort)
+	sort.Slice(products, func(i, j int) bool {
+		return compareProducts(order, cursorOf(products[i]), cursorOf(products[j])) < 0
+	})
+
+	page := &dto.ProductPage{
+		Total: len(products),
+		Limit: filter.Limit,
+	}
+
+	if filter.Cursor != "" {
+		cursor, err := repository.DecodeProductCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		start := sort.Search(len(products), func(i int) bool {
+			return compareProducts(order, cursorOf(products[i]), cursor) > 0
+		})
+		products = products[start:]
+	} else {
+		page.Page = max(filter.Page, 1)
+		offset := min((page.Page-1)*filter.Limit, len(products))
+		products = products[offset:]
+	}
+
+	if len(products) > filter.Limit {
+		products = products[:filter.Limit]
+		page.NextCursor = repository.EncodeProductCursor(products[filter.Limit-1])
+	}
+
+	page.Data = make([]model.Product, len(products))
+	for i, product := range products {
+		product.Categories = repo.store.categoriesOf(product.ID)
+		page.Data[i] = product
+	}
+
+	return page, nil
+}
+
+func (repo *productRepository) Create(ctx context.Context, product *dto.ProductRequest) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if err := repo.store.checkCategories(product.Categories); err != nil {
+		return err
+	}
+	if err := repo.store.checkTaxClass(product.TaxClassID); err != nil {
+		return err
+	}
+
+	product.ID = repo.store.nextID("products")
+	repo.store.products[product.ID] = model.Product{
+		ID:         product.ID,
+		Name:       product.Name,
+		Price:      product.Price,
+		Stock:      product.Stock,
+		TaxClassID: product.TaxClassID,
+	}
+	repo.store.productCategories[product.ID] = slices.Clone(product.Categories)
+
+	return nil
+}
+
+func (repo *productRepository) GetByID(ctx context.Context, id int, includeDeleted bool) (*model.Product, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	product, ok := repo.store.products[id]
+	if !ok || (product.DeletedAt != nil && !includeDeleted) {
+		return nil, repository.ErrProductNotFound
+	}
+	product.Categories = repo.store.categoriesOf(id)
+
+	return &product, nil
+}
+
+func (repo *productRepository) Update(ctx context.Context, product *dto.ProductRequest) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if stored, ok := repo.store.products[product.ID]; !ok || stored.DeletedAt != nil {
+		return repository.ErrProductNotFound
+	}
+	if err := repo.store.checkCategories(product.Categories); err != nil {
+		return err
+	}
+	if err := repo.store.checkTaxClass(product.TaxClassID); err != nil {
+		return err
+	}
+
+	repo.store.products[product.ID] = model.Product{
+		ID:         product.ID,
+		Name:       product.Name,
+		Price:      product.Price,
+		Stock:      product.Stock,
+		TaxClassID: product.TaxClassID,
+	}
+	repo.store.productCategories[product.ID] = slices.Clone(product.Categories)
+
+	return nil
+}
+
+func (repo *productRepository) Delete(ctx context.Context, id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	product, ok := repo.store.products[id]
+	if !ok || product.DeletedAt != nil {
+		return repository.ErrProductNotFound
+	}
+	now := repo.store.now()
+	product.DeletedAt = &now
+	repo.store.products[id] = product
+
+	return nil
+}
+
+func (repo *productRepository) Restore(ctx context.Context, id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	product, ok := repo.store.products[id]
+	if !ok {
+		return repository.ErrProductNotFound
+	}
+	product.DeletedAt = nil
+	repo.store.products[id] = product
+
+	return nil
+}
+
+// categoriesOf mirrors the product_categories join: live categories
+// only, id and name, ordered by category name. Callers must hold the lock.
+func (s *Store) categoriesOf(productID int) []model.Category {
+	var categories []model.Category
+	for _, categoryID := range s.productCategories[productID] {
+		if category, ok := s.categories[categoryID]; ok && category.DeletedAt == nil {
+			categories = append(categories, model.Category{
+				ID:   category.ID,
+				Name: category.Name,
+			})
+		}
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].Name < categories[j].Name
+	})
+
+	return categories
+}
+
+// checkCategories enforces the product_categories foreign key.
+// Callers must hold the lock.
+func (s *Store) checkCategories(categoryIDs []int) error {
+	for _, categoryID := range categoryIDs {
+		if _, ok := s.categories[categoryID]; !ok {
+			return apperror.Conflict(fmt.Sprintf("kategori %d tidak ditemukan", categoryID), nil)
+		}
+	}
+	return nil
+}
+
+// liveProducts returns the requested products that are not deleted.
+// Callers must hold the lock.
+func (s *Store) liveProducts(items []model.CheckoutItem) map[int]model.Product {
+	products := make(map[int]model.Product, len(items))
+	for _, item := range items {
+		if product, ok := s.products[item.ProductID]; ok && product.DeletedAt == nil {
+			products[product.ID] = product
+		}
+	}
+	return products
+}
+
+// inAnyCategory reports whether the product is linked to one of
+// categoryIDs. Callers must hold the lock.
+func (s *Store) inAnyCategory(productID int, categoryIDs []int) bool {
+	for _, categoryID := range s.productCategories[productID] {
+		if slices.Contains(categoryIDs, categoryID) {
+			return true
+		}
+	}
+	return false
+}
+
+func cursorOf(product model.Product) *repository.ProductCursor {
+	return &repository.ProductCursor{
+		ID:    product.ID,
+		Name:  product.Name,
+		Price: product.Price.Amount,
+		Stock: product.Stock,
+	}
+}
+
+// compareProducts orders two keyset positions by the given sort fields.
+func compareProducts(order []dto.SortField, a, b *repository.ProductCursor) int {
+	for _, field := range order {
+		var result int
+		switch field.Field {
+		case "name":
+			result = strings.Compare(a.Name, b.Name)
+		case "price":
+			result = cmp.Compare(a.Price, b.Price)
+		case "stock":
+			result = cmp.Compare(a.Stock, b.Stock)
+		default:
+			result = cmp.Compare(a.ID, b.ID)
+		}
+		if field.Desc {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"category-crud/model"
+	"category-crud/model/dto"
+	"context"
+	"slices"
+	"testing"
+)
+
+// TestProductCursorPagesWithTies walks every page of products that share
+// sort keys; the id tie-breaker must keep rows from being skipped or
+// repeated across page boundaries.
+func TestProductCursorPagesWithTies(t *testing.T) {
+	ctx := context.Background()
+	repo := NewProductRepository(NewStore())
+
+	products := []struct {
+		name  string
+		price int64
+		stock int
+	}{
+		{"Kopi", 5000, 1},
+		{"Teh", 3000, 2},
+		{"Kopi", 5000, 1},
+		{"Susu", 5000, 2},
+		{"Teh", 3000, 2},
+		{"Roti", 8000, 1},
+		{"Kopi", 5000, 3},
+	}
+	for _, product := range products {
+		err := repo.Create(ctx, &dto.ProductRequest{Name: product.name, Price: model.NewMoney(product.price), Stock: product.stock})
+		if err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		sort []dto.SortField
+		want []int
+	}{
+		{
+			name: "price desc",
+			sort: []dto.SortField{{Field: "price", Desc: true}},
+			want: []int{6, 1, 3, 4, 7, 2, 5},
+		},
+		{
+			name: "name then stock desc",
+			sort: []dto.SortField{{Field: "name"}, {Field: "stock", Desc: true}},
+			want: []int{7, 1, 3, 6, 4, 2, 5},
+		},
+		{
+			name: "id desc",
+			sort: []dto.SortField{{Field: "id", Desc: true}},
+			want: []int{7, 6, 5, 4, 3, 2, 1},
+		},
+	}
+	for _, tt := range tests {
+		for _, limit := range []int{1, 2, 3, 7} {
+			filter := &dto.ProductFilterRequest{Sort: tt.sort, Limit: limit}
+			var got []int
+			for {
+				page, err := repo.GetAll(ctx, filter)
+				if err != nil {
+					t.Fatalf("%s: GetAll: %v", tt.name, err)
+				}
+				for _, product := range page.Data {
+					got = append(got, product.ID)
+				}
+				if page.NextCursor == "" || len(got) > len(products) {
+					break
+				}
+				filter.Cursor = page.NextCursor
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("%s, limit %d: want %v, got %v", tt.name, limit, tt.want, got)
+			}
+		}
+	}
+}
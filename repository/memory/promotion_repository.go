@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"category-crud/apperror"
+	"category-crud/model"
+	"category-crud/repository"
+	"context"
+	"sort"
+	"time"
+)
+
+type promotionRepository struct {
+	store *Store
+}
+
+// errPromotionTarget mirrors the foreign keys on promotions.product_id and
+// promotions.category_id.
+var errPromotionTarget = apperror.Conflict("data masih direferensikan atau referensi tidak valid", nil)
+
+func NewPromotionRepository(store *Store) repository.PromotionRepository {
+	return &promotionRepository{store: store}
+}
+
+func (repo *promotionRepository) GetAll(ctx context.Context) ([]model.Promotion, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	promotions := make([]model.Promotion, 0, len(repo.store.promotions))
+	for _, promotion := range repo.store.promotions {
+		if promotion.DeletedAt == nil {
+			promotions = append(promotions, promotion)
+		}
+	}
+	sort.Slice(promotions, func(i, j int) bool {
+		return promotions[i].ID < promotions[j].ID
+	})
+
+	return promotions, nil
+}
+
+func (repo *promotionRepository) Create(ctx context.Context, promotion *model.Promotion) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if err := repo.store.checkPromotionTarget(promotion); err != nil {
+		return err
+	}
+
+	now := repo.store.now()
+	promotion.ID = repo.store.nextID("promotions")
+	promotion.CreatedAt = now
+	promotion.UpdatedAt = now
+	promotion.DeletedAt = nil
+	repo.store.promotions[promotion.ID] = *promotion
+
+	return nil
+}
+
+func (repo *promotionRepository) GetByID(ctx context.Context, id int) (*model.Promotion, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	promotion, ok := repo.store.promotions[id]
+	if !ok || promotion.DeletedAt != nil {
+		return nil, repository.ErrPromotionNotFound
+	}
+
+	return &promotion, nil
+}
+
+func (repo *promotionRepository) Update(ctx context.Context, promotion *model.Promotion) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	stored, ok := repo.store.promotions[promotion.ID]
+	if !ok || stored.DeletedAt != nil {
+		return repository.ErrPromotionNotFound
+	}
+	if err := repo.store.checkPromotionTarget(promotion); err != nil {
+		return err
+	}
+
+	promotion.CreatedAt = stored.CreatedAt
+	promotion.UpdatedAt = repo.store.now()
+	promotion.DeletedAt = nil
+	repo.store.promotions[promotion.ID] = *promotion
+
+	return nil
+}
+
+func (repo *promotionRepository) Delete(ctx context.Context, id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	promotion, ok := repo.store.promotions[id]
+	if !ok || promotion.DeletedAt != nil {
+		return repository.ErrPromotionNotFound
+	}
+	now := repo.store.now()
+	promotion.DeletedAt = &now
+	repo.store.promotions[id] = promotion
+
+	return nil
+}
+
+// checkPromotionTarget enforces the foreign keys of a promotion. Callers
+// must hold the lock.
+func (s *Store) checkPromotionTarget(promotion *model.Promotion) error {
+	if promotion.ProductID != nil {
+		if _, ok := s.products[*promotion.ProductID]; !ok {
+			return errPromotionTarget
+		}
+	}
+	if promotion.CategoryID != nil {
+		if _, ok := s.categories[*promotion.CategoryID]; !ok {
+			return errPromotionTarget
+		}
+	}
+	return nil
+}
+
+// runningPromotions returns the promotions that apply to a sale made at
+// now, with the live categories of the given products. Callers must hold
+// the lock.
+func (s *Store) runningPromotions(now time.Time, productIDs []int) ([]model.Promotion, map[int][]int) {
+	var promotions []model.Promotion
+	for _, promotion := range s.promotions {
+		if promotion.RunsAt(now) {
+			promotions = append(promotions, promotion)
+		}
+	}
+
+	categories := make(map[int][]int, len(productIDs))
+	for _, productID := range productIDs {
+		for _, category := range s.categoriesOf(productID) {
+			categories[productID] = append(categories[productID], category.ID)
+		}
+	}
+	return promotions, categories
+}
@@ -0,0 +1,196 @@
+package memory
+
+import (
+	"category-crud/model"
+	"category-crud/model/dto"
+	"category-crud/repository"
+	"context"
+	"sort"
+)
+
+type shiftRepository struct {
+	store *Store
+}
+
+func NewShiftRepository(store *Store) repository.ShiftRepository {
+	return &shiftRepository{store: store}
+}
+
+func (repo *shiftRepository) GetAll(ctx context.Context, filter *dto.ShiftFilterRequest) (*dto.ShiftPage, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	var shifts []model.Shift
+	for _, shift := range repo.store.shifts {
+		if filter.CashierID != nil && (shift.CashierID == nil || *shift.CashierID != *filter.CashierID) {
+			continue
+		}
+		if filter.Open != nil && *filter.Open != (shift.ClosedAt == nil) {
+			continue
+		}
+		shifts = append(shifts, shift)
+	}
+	sort.Slice(shifts, func(i, j int) bool {
+		if !shifts[i].OpenedAt.Equal(shifts[j].OpenedAt) {
+			return shifts[i].OpenedAt.After(shifts[j].OpenedAt)
+		}
+		return shifts[i].ID > shifts[j].ID
+	})
+
+	offset := min((filter.Page-1)*filter.Limit, len(shifts))
+	end := min(offset+filter.Limit, len(shifts))
+	page := &dto.ShiftPage{
+		Data:  []model.Shift{},
+		Total: len(shifts),
+		Limit: filter.Limit,
+		Page:  filter.Page,
+	}
+	for _, shift := range shifts[offset:end] {
+		page.Data = append(page.Data, copyShift(shift))
+	}
+
+	return page, nil
+}
+
+func (repo *shiftRepository) Open(ctx context.Context, shift *model.Shift) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if shift.CashierID != nil {
+		if _, ok := repo.store.users[*shift.CashierID]; !ok {
+			return errCashierNotFound
+		}
+	}
+	if _, ok := repo.store.openShift(shift.CashierID); ok {
+		return repository.ErrShiftAlreadyOpen
+	}
+
+	shift.ID = repo.store.nextID("shifts")
+	shift.OpenedAt = repo.store.now()
+	shift.ClosedAt = nil
+	shift.Reconciliation = nil
+	repo.store.shifts[shift.ID] = *shift
+
+	return nil
+}
+
+func (repo *shiftRepository) GetByID(ctx context.Context, id int) (*model.Shift, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	shift, ok := repo.store.shifts[id]
+	if !ok {
+		return nil, repository.ErrShiftNotFound
+	}
+
+	shift = copyShift(shift)
+	return &shift, nil
+}
+
+func (repo *shiftRepository) Close(ctx context.Context, id int, req *model.CloseShiftRequest) (*model.Shift, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	shift, ok := repo.store.shifts[id]
+	if !ok {
+		return nil, repository.ErrShiftNotFound
+	}
+	if shift.ClosedAt != nil {
+		return nil, repository.ErrShiftClosed
+	}
+
+	var err error
+	cashSales := model.NewMoney(0)
+	for transactionID, transaction := range repo.store.transactions {
+		if transaction.ShiftID == nil || *transaction.ShiftID != id {
+			continue
+		}
+		for _, payment := range repo.store.payments[transactionID] {
+			if payment.Method != model.PaymentCash {
+				continue
+			}
+			if cashSales, err = cashSales.Add(payment.Amount); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// a refund's cash depends on what earlier refunds of the same sale
+	// already paid back, whichever shift made them
+	refunded := make(map[int]bool)
+	for _, refund := range repo.store.refunds {
+		if refund.ShiftID != nil && *refund.ShiftID == id {
+			refunded[refund.TransactionID] = true
+		}
+	}
+	cashPaid := make(map[int]model.Money, len(refunded))
+	for transactionID := range refunded {
+		for _, payment := range repo.store.payments[transactionID] {
+			if payment.Method != model.PaymentCash {
+				continue
+			}
+			if cashPaid[transactionID], err = cashPaid[transactionID].Add(payment.Amount); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var refunds []model.Refund
+	for _, refund := range repo.store.refunds {
+		if refunded[refund.TransactionID] {
+			refunds = append(refunds, refund)
+		}
+	}
+	sort.Slice(refunds, func(i, j int) bool {
+		return refunds[i].ID < refunds[j].ID
+	})
+	shiftRefunds := make([]repository.ShiftRefund, 0, len(refunds))
+	for _, refund := range refunds {
+		shiftRefunds = append(shiftRefunds, repository.ShiftRefund{
+			TransactionID: refund.TransactionID,
+			ShiftID:       refund.ShiftID,
+			TotalAmount:   refund.TotalAmount,
+		})
+	}
+
+	reconciliation, err := repository.ReconcileShift(id, shift.OpeningFloat, cashSales, cashPaid, shiftRefunds, req.CountedCash)
+	if err != nil {
+		return nil, err
+	}
+
+	closedAt := repo.store.now()
+	shift.ClosedAt = &closedAt
+	shift.Note = req.Note
+	shift.Reconciliation = reconciliation
+	repo.store.shifts[id] = shift
+
+	shift = copyShift(shift)
+	return &shift, nil
+}
+
+// openShift returns the shift cashierID has open, mirroring the unique
+// index on open shifts. Callers must hold the lock.
+func (s *Store) openShift(cashierID *int) (model.Shift, bool) {
+	for _, shift := range s.shifts {
+		if shift.ClosedAt != nil {
+			continue
+		}
+		if (shift.CashierID == nil) != (cashierID == nil) {
+			continue
+		}
+		if cashierID == nil || *shift.CashierID == *cashierID {
+			return shift, true
+		}
+	}
+	return model.Shift{}, false
+}
+
+// copyShift keeps callers from writing through to the stored
+// reconciliation.
+func copyShift(shift model.Shift) model.Shift {
+	if shift.Reconciliation != nil {
+		reconciliation := *shift.Reconciliation
+		shift.Reconciliation = &reconciliation
+	}
+	return shift
+}
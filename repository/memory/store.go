@@ -0,0 +1,57 @@
+// Package memory implements the repository interfaces on top of an
+// in-process store so the API can run without a database.
+package memory
+
+import (
+	"category-crud/model"
+	"sync"
+	"time"
+)
+
+// Store holds every table behind a single lock. Repositories built on the
+// same Store see each other's writes, which keeps checkout atomic across
+// products and transactions the same way a database transaction would.
+type Store struct {
+	mu sync.RWMutex
+
+	categories         map[int]model.Category
+	products           map[int]model.Product
+	productCategories  map[int][]int
+	transactions       map[int]model.Transaction
+	transactionDetails map[int][]model.TransactionDetail
+	payments           map[int][]model.Payment
+	idempotencyKeys    map[idempotencyScope]model.IdempotencyKey
+	refunds            map[int]model.Refund
+	shifts             map[int]model.Shift
+	promotions         map[int]model.Promotion
+	taxClasses         map[int]model.TaxClass
+	users              map[int]model.User
+
+	sequences map[string]int
+	now       func() time.Time
+}
+
+func NewStore() *Store {
+	return &Store{
+		categories:         make(map[int]model.Category),
+		products:           make(map[int]model.Product),
+		productCategories:  make(map[int][]int),
+		transactions:       make(map[int]model.Transaction),
+		transactionDetails: make(map[int][]model.TransactionDetail),
+		payments:           make(map[int][]model.Payment),
+		idempotencyKeys:    make(map[idempotencyScope]model.IdempotencyKey),
+		refunds:            make(map[int]model.Refund),
+		shifts:             make(map[int]model.Shift),
+		promotions:         make(map[int]model.Promotion),
+		taxClasses:         make(map[int]model.TaxClass),
+		users:              make(map[int]model.User),
+		sequences:          make(map[string]int),
+		now:                time.Now,
+	}
+}
+
+// nextID mimics a SERIAL column. Callers must hold the write lock.
+func (s *Store) nextID(table string) int {
+	s.sequences[table]++
+	return s.sequences[table]
+}
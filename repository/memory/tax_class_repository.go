@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"category-crud/apperror"
+	"category-crud/model"
+	"category-crud/repository"
+	"context"
+	"slices"
+	"sort"
+)
+
+type taxClassRepository struct {
+	store *Store
+}
+
+// errTaxClassReference mirrors the foreign keys on products.tax_class_id
+// and categories.tax_class_id.
+var errTaxClassReference = apperror.Conflict("data masih direferensikan atau referensi tidak valid", nil)
+
+func NewTaxClassRepository(store *Store) repository.TaxClassRepository {
+	return &taxClassRepository{store: store}
+}
+
+func (repo *taxClassRepository) GetAll(ctx context.Context) ([]model.TaxClass, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	taxClasses := make([]model.TaxClass, 0, len(repo.store.taxClasses))
+	for _, taxClass := range repo.store.taxClasses {
+		taxClasses = append(taxClasses, taxClass)
+	}
+	sort.Slice(taxClasses, func(i, j int) bool {
+		return taxClasses[i].ID < taxClasses[j].ID
+	})
+
+	return taxClasses, nil
+}
+
+func (repo *taxClassRepository) Create(ctx context.Context, taxClass *model.TaxClass) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if repo.store.taxClassNameTaken(taxClass) {
+		return repository.ErrTaxClassNameTaken
+	}
+
+	now := repo.store.now()
+	taxClass.ID = repo.store.nextID("tax_classes")
+	taxClass.CreatedAt = now
+	taxClass.UpdatedAt = now
+	repo.store.taxClasses[taxClass.ID] = *taxClass
+
+	return nil
+}
+
+func (repo *taxClassRepository) GetByID(ctx context.Context, id int) (*model.TaxClass, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	taxClass, ok := repo.store.taxClasses[id]
+	if !ok {
+		return nil, repository.ErrTaxClassNotFound
+	}
+
+	return &taxClass, nil
+}
+
+func (repo *taxClassRepository) Update(ctx context.Context, taxClass *model.TaxClass) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	stored, ok := repo.store.taxClasses[taxClass.ID]
+	if !ok {
+		return repository.ErrTaxClassNotFound
+	}
+	if repo.store.taxClassNameTaken(taxClass) {
+		return repository.ErrTaxClassNameTaken
+	}
+
+	taxClass.CreatedAt = stored.CreatedAt
+	taxClass.UpdatedAt = repo.store.now()
+	repo.store.taxClasses[taxClass.ID] = *taxClass
+
+	return nil
+}
+
+func (repo *taxClassRepository) Delete(ctx context.Context, id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, ok := repo.store.taxClasses[id]; !ok {
+		return repository.ErrTaxClassNotFound
+	}
+	// the foreign keys also hold for soft-deleted rows
+	for _, product := range repo.store.products {
+		if product.TaxClassID != nil && *product.TaxClassID == id {
+			return repository.ErrTaxClassInUse
+		}
+	}
+	for _, category := range repo.store.categories {
+		if category.TaxClassID != nil && *category.TaxClassID == id {
+			return repository.ErrTaxClassInUse
+		}
+	}
+	delete(repo.store.taxClasses, id)
+
+	return nil
+}
+
+// taxClassNameTaken mirrors the unique index on tax_classes.name. Callers
+// must hold the lock.
+func (s *Store) taxClassNameTaken(taxClass *model.TaxClass) bool {
+	for _, stored := range s.taxClasses {
+		if stored.Name == taxClass.Name && stored.ID != taxClass.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTaxClass enforces a tax_class_id foreign key. Callers must hold
+// the lock.
+func (s *Store) checkTaxClass(taxClassID *int) error {
+	if taxClassID != nil {
+		if _, ok := s.taxClasses[*taxClassID]; !ok {
+			return errTaxClassReference
+		}
+	}
+	return nil
+}
+
+// taxRates resolves the tax rate of each product as described on
+// repository.ApplyTax. Callers must hold the lock.
+func (s *Store) taxRates(productIDs []int) map[int]int {
+	rates := make(map[int]int, len(productIDs))
+	for _, productID := range productIDs {
+		if taxClassID := s.products[productID].TaxClassID; taxClassID != nil {
+			rates[productID] = s.taxClasses[*taxClassID].RateBps
+			continue
+		}
+
+		categoryIDs := slices.Clone(s.productCategories[productID])
+		slices.Sort(categoryIDs)
+		for _, categoryID := range categoryIDs {
+			category, ok := s.categories[categoryID]
+			if ok && category.DeletedAt == nil && category.TaxClassID != nil {
+				rates[productID] = s.taxClasses[*category.TaxClassID].RateBps
+				break
+			}
+		}
+	}
+	return rates
+}
@@ -0,0 +1,569 @@
+package memory
+
+import (
+	"category-crud/apperror"
+	"category-crud/model"
+	"category-crud/model/dto"
+	"category-crud/repository"
+	"context"
+	"slices"
+	"sort"
+	"time"
+)
+
+type transactionRepository struct {
+	store *Store
+}
+
+// errCashierNotFound mirrors the foreign key violation on
+// transactions.cashier_id.
+var errCashierNotFound = apperror.Conflict("data masih direferensikan atau referensi tidak valid", nil)
+
+func NewTransactionRepository(store *Store) repository.TransactionRepository {
+	return &transactionRepository{store: store}
+}
+
+func (repo *transactionRepository) CreateTransaction(ctx context.Context, req *model.CheckoutRequest, cashierID *int, idempotencyKey *model.IdempotencyKey, tax model.TaxPolicy) (*model.Transaction, bool, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	var scope idempotencyScope
+	if idempotencyKey != nil {
+		scope = scopeOf(idempotencyKey.Key, cashierID)
+		stored, ok := repo.store.idempotencyKeys[scope]
+		if ok && stored.ExpiresAt.After(repo.store.now()) {
+			if stored.RequestHash != idempotencyKey.RequestHash {
+				return nil, false, repository.ErrIdempotencyKeyReused
+			}
+			return repo.store.transaction(stored.TransactionID), true, nil
+		}
+	}
+
+	items := req.Items
+	if err := repository.CheckStock(items, repo.store.liveProducts(items)); err != nil {
+		return nil, false, err
+	}
+	if cashierID != nil {
+		if _, ok := repo.store.users[*cashierID]; !ok {
+			return nil, false, errCashierNotFound
+		}
+	}
+
+	details, subtotalAmount, err := repository.PriceItems(items, repo.store.products)
+	if err != nil {
+		return nil, false, err
+	}
+
+	stock := make(map[int]int, len(items))
+	for _, item := range items {
+		product := repo.store.products[item.ProductID]
+		if _, seen := stock[product.ID]; !seen {
+			stock[product.ID] = product.Stock
+		}
+		stock[product.ID] -= item.Quantity
+	}
+
+	now := repo.store.now()
+	productIDs := make([]int, 0, len(stock))
+	for productID := range stock {
+		productIDs = append(productIDs, productID)
+	}
+	promotions, categories := repo.store.runningPromotions(now, productIDs)
+	discountAmount, promotionID := repository.ApplyPromotions(details, categories, promotions)
+	taxAmount, totalAmount, err := repository.ApplyTax(details, repo.store.taxRates(productIDs), tax)
+	if err != nil {
+		return nil, false, err
+	}
+	payments, err := repository.SettlePayments(totalAmount, req.Payments)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Nothing is written until every item has been priced, so a failed
+	// checkout leaves the store untouched.
+	transaction := model.Transaction{
+		ID:             repo.store.nextID("transactions"),
+		SubtotalAmount: subtotalAmount,
+		DiscountAmount: discountAmount,
+		TaxAmount:      taxAmount,
+		TotalAmount:    totalAmount,
+		TaxInclusive:   tax.Inclusive,
+		PromotionID:    promotionID,
+		CreatedAt:      now,
+		CashierID:      cashierID,
+	}
+	if shift, ok := repo.store.openShift(cashierID); ok {
+		transaction.ShiftID = &shift.ID
+	}
+	for i := range details {
+		details[i].ID = repo.store.nextID("transaction_details")
+		details[i].TransactionID = transaction.ID
+	}
+	for i := range payments {
+		payments[i].ID = repo.store.nextID("transaction_payments")
+		payments[i].TransactionID = transaction.ID
+	}
+	for productID, remaining := range stock {
+		product := repo.store.products[productID]
+		product.Stock = remaining
+		repo.store.products[productID] = product
+	}
+
+	repo.store.transactions[transaction.ID] = transaction
+	repo.store.transactionDetails[transaction.ID] = details
+	repo.store.payments[transaction.ID] = payments
+
+	if idempotencyKey != nil {
+		repo.store.purgeIdempotencyKeys(now)
+		stored := *idempotencyKey
+		stored.TransactionID = transaction.ID
+		repo.store.idempotencyKeys[scope] = stored
+	}
+
+	return repo.store.transaction(transaction.ID), false, nil
+}
+
+// idempotencyScope is an idempotency key as sent by one cashier, mirroring
+// the unique index on (COALESCE(cashier_id, 0), key).
+type idempotencyScope struct {
+	cashierID int
+	key       string
+}
+
+func scopeOf(key string, cashierID *int) idempotencyScope {
+	scope := idempotencyScope{key: key}
+	if cashierID != nil {
+		scope.cashierID = *cashierID
+	}
+	return scope
+}
+
+// purgeIdempotencyKeys drops expired keys. Callers must hold the write
+// lock.
+func (s *Store) purgeIdempotencyKeys(now time.Time) {
+	for scope, key := range s.idempotencyKeys {
+		if !key.ExpiresAt.After(now) {
+			delete(s.idempotencyKeys, scope)
+		}
+	}
+}
+
+// transaction returns a copy of a stored transaction with its details
+// and payments. Callers must hold the lock.
+func (s *Store) transaction(id int) *model.Transaction {
+	transaction := s.transactions[id]
+	transaction.Details = slices.Clone(s.transactionDetails[id])
+	if transaction.Details == nil {
+		transaction.Details = []model.TransactionDetail{}
+	}
+	transaction.Payments = slices.Clone(s.payments[id])
+	if transaction.Payments == nil {
+		transaction.Payments = []model.Payment{}
+	}
+	return &transaction
+}
+
+func (repo *transactionRepository) GetAll(ctx context.Context, filter *dto.TransactionFilterRequest) (*dto.TransactionPage, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	transactions := repo.store.filterTransactions(filter)
+
+	page := &dto.TransactionPage{
+		Data:  []model.Transaction{},
+		Total: len(transactions),
+		Limit: filter.Limit,
+		Page:  filter.Page,
+	}
+
+	offset := min((filter.Page-1)*filter.Limit, len(transactions))
+	end := min(offset+filter.Limit, len(transactions))
+	for _, transaction := range transactions[offset:end] {
+		page.Data = append(page.Data, *repo.store.transaction(transaction.ID))
+	}
+
+	return page, nil
+}
+
+// filterTransactions returns the transactions matching filter, newest
+// first. Callers must hold the lock.
+func (s *Store) filterTransactions(filter *dto.TransactionFilterRequest) []model.Transaction {
+	var transactions []model.Transaction
+	for id, transaction := range s.transactions {
+		if filter.StartDate != nil && transaction.CreatedAt.Before(*filter.StartDate) {
+			continue
+		}
+		if filter.EndDate != nil && !transaction.CreatedAt.Before(*filter.EndDate) {
+			continue
+		}
+		if filter.MinTotal != nil && transaction.TotalAmount.Amount < filter.MinTotal.Amount {
+			continue
+		}
+		if filter.MaxTotal != nil && transaction.TotalAmount.Amount > filter.MaxTotal.Amount {
+			continue
+		}
+		if len(filter.ProductIDs) > 0 && !s.containsAnyProduct(id, filter.ProductIDs) {
+			continue
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	sort.Slice(transactions, func(i, j int) bool {
+		if !transactions[i].CreatedAt.Equal(transactions[j].CreatedAt) {
+			return transactions[i].CreatedAt.After(transactions[j].CreatedAt)
+		}
+		return transactions[i].ID > transactions[j].ID
+	})
+
+	return transactions
+}
+
+// ExportLines copies the lines under the lock and yields them after
+// releasing it, so a slow client does not block checkouts.
+func (repo *transactionRepository) ExportLines(ctx context.Context, filter *dto.TransactionFilterRequest, yield func(*model.TransactionLine) error) error {
+	repo.store.mu.RLock()
+	var lines []model.TransactionLine
+	for _, transaction := range repo.store.filterTransactions(filter) {
+		for _, detail := range repo.store.transactionDetails[transaction.ID] {
+			lines = append(lines, model.TransactionLine{
+				TransactionID:  transaction.ID,
+				CreatedAt:      transaction.CreatedAt,
+				VoidedAt:       transaction.VoidedAt,
+				CashierID:      transaction.CashierID,
+				TotalAmount:    transaction.TotalAmount,
+				DetailID:       detail.ID,
+				ProductID:      detail.ProductID,
+				ProductName:    detail.ProductName,
+				UnitPrice:      detail.UnitPrice,
+				Quantity:       detail.Quantity,
+				Subtotal:       detail.Subtotal,
+				DiscountAmount: detail.DiscountAmount,
+				TaxAmount:      detail.TaxAmount,
+				LineTotal:      detail.TotalAmount,
+			})
+		}
+	}
+	repo.store.mu.RUnlock()
+
+	for i := range lines {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := yield(&lines[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// containsAnyProduct reports whether a transaction sold one of
+// productIDs. Callers must hold the lock.
+func (s *Store) containsAnyProduct(transactionID int, productIDs []int) bool {
+	for _, detail := range s.transactionDetails[transactionID] {
+		if slices.Contains(productIDs, detail.ProductID) {
+			return true
+		}
+	}
+	return false
+}
+
+func (repo *transactionRepository) GetByID(ctx context.Context, id int) (*model.Transaction, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	if _, ok := repo.store.transactions[id]; !ok {
+		return nil, repository.ErrTransactionNotFound
+	}
+	return repo.store.transaction(id), nil
+}
+
+func (repo *transactionRepository) CreateRefund(ctx context.Context, transactionID int, refundType string, req *model.RefundRequest, cashierID *int) (*model.Refund, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	transaction, ok := repo.store.transactions[transactionID]
+	if !ok {
+		return nil, repository.ErrTransactionNotFound
+	}
+	if transaction.VoidedAt != nil {
+		return nil, repository.ErrTransactionVoided
+	}
+
+	var err error
+	refunded := make(map[int]repository.RefundedLine)
+	for _, refund := range repo.store.refunds {
+		if refund.TransactionID != transactionID {
+			continue
+		}
+		for _, detail := range refund.Details {
+			line := refunded[detail.TransactionDetailID]
+			line.TransactionDetailID = detail.TransactionDetailID
+			line.Quantity += detail.Quantity
+			if line.Amount, err = line.Amount.Add(detail.Amount); err != nil {
+				return nil, err
+			}
+			if line.TaxAmount, err = line.TaxAmount.Add(detail.TaxAmount); err != nil {
+				return nil, err
+			}
+			refunded[detail.TransactionDetailID] = line
+		}
+	}
+
+	lines, err := repository.PlanRefund(repo.store.transactionDetails[transactionID], refunded, req.Items)
+	if err != nil {
+		return nil, err
+	}
+	totalAmount, taxAmount, err := repository.RefundTotals(lines)
+	if err != nil {
+		return nil, err
+	}
+
+	refund := model.Refund{
+		ID:            repo.store.nextID("refunds"),
+		TransactionID: transactionID,
+		CashierID:     cashierID,
+		Type:          refundType,
+		Reason:        req.Reason,
+		TotalAmount:   totalAmount,
+		TaxAmount:     taxAmount,
+		CreatedAt:     repo.store.now(),
+		Details:       lines,
+	}
+	if shift, ok := repo.store.openShift(cashierID); ok {
+		refund.ShiftID = &shift.ID
+	}
+	for i := range refund.Details {
+		refund.Details[i].ID = repo.store.nextID("refund_details")
+		refund.Details[i].RefundID = refund.ID
+
+		product := repo.store.products[refund.Details[i].ProductID]
+		product.Stock += refund.Details[i].Quantity
+		repo.store.products[product.ID] = product
+	}
+	repo.store.refunds[refund.ID] = refund
+
+	if refundType == model.RefundTypeVoid {
+		voidedAt := refund.CreatedAt
+		transaction.VoidedAt = &voidedAt
+		repo.store.transactions[transactionID] = transaction
+	}
+
+	refund.Details = append([]model.RefundDetail(nil), refund.Details...)
+	return &refund, nil
+}
+
+func (repo *transactionRepository) GetReport(ctx context.Context, filter *model.ReportFilter) (*model.Report, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	var report model.Report
+	var sum adder
+	cashiers := make(map[int]*model.CashierSales)
+	products := make(map[int]*model.ProductSales)
+	latest := make(map[int]int) // product id -> id of its most recent detail
+	categories := make(map[int]*model.CategorySales)
+	payments := make(map[string]*model.PaymentSales)
+	for _, transaction := range repo.store.transactions {
+		if !inReport(filter, transaction.ShiftID, transaction.CreatedAt) {
+			continue
+		}
+		sum.add(&report.TotalRevenue, transaction.TotalAmount)
+		sum.add(&report.TotalDiscounts, transaction.DiscountAmount)
+		sum.add(&report.TotalTax, transaction.TaxAmount)
+		report.TotalTransaks++
+
+		cashierKey := 0
+		if transaction.CashierID != nil {
+			cashierKey = *transaction.CashierID
+		}
+		sales, ok := cashiers[cashierKey]
+		if !ok {
+			sales = &model.CashierSales{CashierID: transaction.CashierID}
+			if user, ok := repo.store.users[cashierKey]; ok {
+				sales.CashierName = user.Name
+				if sales.CashierName == "" {
+					sales.CashierName = user.Username
+				}
+			}
+			cashiers[cashierKey] = sales
+		}
+		sum.add(&sales.TotalRevenue, transaction.TotalAmount)
+		sales.TotalTransaksi++
+
+		for _, payment := range repo.store.payments[transaction.ID] {
+			method, ok := payments[payment.Method]
+			if !ok {
+				method = &model.PaymentSales{Method: payment.Method}
+				payments[payment.Method] = method
+			}
+			method.Payments++
+			sum.add(&method.Amount, payment.Amount)
+			sum.add(&method.Tendered, payment.Tendered)
+			sum.add(&method.Change, payment.Change)
+		}
+
+		for _, detail := range repo.store.transactionDetails[transaction.ID] {
+			report.ItemsSold += detail.Quantity
+
+			product, ok := products[detail.ProductID]
+			if !ok {
+				product = &model.ProductSales{ProductID: detail.ProductID}
+				products[detail.ProductID] = product
+			}
+			product.Quantity += detail.Quantity
+			sum.add(&product.Revenue, detail.TotalAmount)
+			if detail.ID > latest[detail.ProductID] {
+				latest[detail.ProductID] = detail.ID
+				product.ProductName = detail.ProductName
+			}
+
+			// like the LEFT JOIN on product_categories: uncategorised
+			// products are grouped under key 0
+			categoryIDs := repo.store.productCategories[detail.ProductID]
+			if len(categoryIDs) == 0 {
+				categoryIDs = []int{0}
+			}
+			for _, categoryID := range categoryIDs {
+				category, ok := categories[categoryID]
+				if !ok {
+					category = &model.CategorySales{}
+					if stored, ok := repo.store.categories[categoryID]; ok {
+						category.CategoryID = &stored.ID
+						category.CategoryName = stored.Name
+					}
+					categories[categoryID] = category
+				}
+				category.Quantity += detail.Quantity
+				sum.add(&category.Revenue, detail.TotalAmount)
+			}
+		}
+	}
+
+	for _, refund := range repo.store.refunds {
+		if inReport(filter, refund.ShiftID, refund.CreatedAt) {
+			sum.add(&report.TotalRefunds, refund.TotalAmount)
+			sum.add(&report.TaxRefunded, refund.TaxAmount)
+		}
+	}
+
+	report.Cashiers = make([]model.CashierSales, 0, len(cashiers))
+	for _, sales := range cashiers {
+		report.Cashiers = append(report.Cashiers, *sales)
+	}
+	sort.Slice(report.Cashiers, func(i, j int) bool {
+		a, b := report.Cashiers[i], report.Cashiers[j]
+		if a.TotalRevenue != b.TotalRevenue {
+			return a.TotalRevenue.Amount > b.TotalRevenue.Amount
+		}
+		return nullsLast(a.CashierID, b.CashierID)
+	})
+
+	for _, sales := range payments {
+		report.Payments = append(report.Payments, *sales)
+	}
+
+	report.Categories = make([]model.CategorySales, 0, len(categories))
+	for _, sales := range categories {
+		report.Categories = append(report.Categories, *sales)
+	}
+	sort.Slice(report.Categories, func(i, j int) bool {
+		a, b := report.Categories[i], report.Categories[j]
+		if a.Revenue != b.Revenue {
+			return a.Revenue.Amount > b.Revenue.Amount
+		}
+		return nullsLast(a.CategoryID, b.CategoryID)
+	})
+
+	ranked := make([]model.ProductSales, 0, len(products))
+	for _, sales := range products {
+		ranked = append(ranked, *sales)
+	}
+	report.TopByQuantity = topProducts(ranked, filter.Top, func(sales model.ProductSales) int64 { return int64(sales.Quantity) })
+	report.TopByRevenue = topProducts(ranked, filter.Top, func(sales model.ProductSales) int64 { return sales.Revenue.Amount })
+
+	if sum.err != nil {
+		return nil, sum.err
+	}
+	if err := repository.FinishReport(&report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// inReport reports whether a report covers a sale or refund made at
+// createdAt during shiftID: those of filter.ShiftID when set, else those
+// made within the date range.
+func inReport(filter *model.ReportFilter, shiftID *int, createdAt time.Time) bool {
+	if filter.ShiftID != nil {
+		return shiftID != nil && *shiftID == *filter.ShiftID
+	}
+	return !createdAt.Before(filter.StartDate) && createdAt.Before(filter.EndDate)
+}
+
+// adder adds amounts up and keeps the first overflow, so a long
+// aggregation only checks once at the end.
+type adder struct {
+	err error
+}
+
+func (a *adder) add(total *model.Money, amount model.Money) {
+	if a.err == nil {
+		*total, a.err = total.Add(amount)
+	}
+}
+
+// topProducts returns the first n products ordered by key descending,
+// then by product id.
+func topProducts(products []model.ProductSales, n int, key func(model.ProductSales) int64) []model.ProductSales {
+	sorted := slices.Clone(products)
+	sort.Slice(sorted, func(i, j int) bool {
+		if key(sorted[i]) != key(sorted[j]) {
+			return key(sorted[i]) > key(sorted[j])
+		}
+		return sorted[i].ProductID < sorted[j].ProductID
+	})
+	return sorted[:min(n, len(sorted))]
+}
+
+// nullsLast orders ids ascending with nil last, like NULLS LAST.
+func nullsLast(a, b *int) bool {
+	if a == nil || b == nil {
+		return b == nil && a != nil
+	}
+	return *a < *b
+}
+
+func (repo *transactionRepository) GetTimeseries(ctx context.Context, filter *model.TimeseriesFilter) (*model.Timeseries, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	var sum adder
+	buckets := make(map[int64]*model.SalesBucket)
+	for _, transaction := range repo.store.transactions {
+		if transaction.CreatedAt.Before(filter.StartDate) || !transaction.CreatedAt.Before(filter.EndDate) {
+			continue
+		}
+
+		start := repository.BucketStart(transaction.CreatedAt, filter.Interval, filter.Location)
+		bucket, ok := buckets[start.Unix()]
+		if !ok {
+			bucket = &model.SalesBucket{Start: start}
+			buckets[start.Unix()] = bucket
+		}
+		sum.add(&bucket.TotalRevenue, transaction.TotalAmount)
+		bucket.TotalTransaksi++
+		for _, detail := range repo.store.transactionDetails[transaction.ID] {
+			bucket.ItemsSold += detail.Quantity
+		}
+	}
+
+	sparse := make([]model.SalesBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		sparse = append(sparse, *bucket)
+	}
+	if sum.err != nil {
+		return nil, sum.err
+	}
+
+	return repository.FillBuckets(filter, sparse), nil
+}
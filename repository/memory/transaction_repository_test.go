@@ -0,0 +1,268 @@
+package memory
+
+import (
+	"category-crud/apperror"
+	"category-crud/model"
+	"category-crud/model/dto"
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCreateTransactionConcurrentStock(t *testing.T) {
+	const (
+		buyers = 50
+		stock  = 17
+	)
+	ctx := context.Background()
+	store := NewStore()
+	products := NewProductRepository(store)
+	transactions := NewTransactionRepository(store)
+
+	product := &dto.ProductRequest{Name: "Kopi", Price: model.NewMoney(8000), Stock: stock}
+	if err := products.Create(ctx, product); err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, buyers)
+	start := make(chan struct{})
+	for i := range buyers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, _, errs[i] = transactions.CreateTransaction(ctx, &model.CheckoutRequest{
+				Items:    []model.CheckoutItem{{ProductID: product.ID, Quantity: 1}},
+				Payments: []model.PaymentRequest{{Method: model.PaymentCash, Amount: model.NewMoney(8000)}},
+			}, nil, nil, model.TaxPolicy{Inclusive: true})
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	succeeded, conflicts := 0, 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case apperror.CodeOf(err) == apperror.CodeInsufficientStock:
+			conflicts++
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if succeeded != stock || conflicts != buyers-stock {
+		t.Errorf("want %d sales and %d stock conflicts, got %d and %d", stock, buyers-stock, succeeded, conflicts)
+	}
+
+	stored, err := products.GetByID(ctx, product.ID, false)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.Stock != 0 {
+		t.Errorf("want stock 0, got %d", stored.Stock)
+	}
+}
+
+func TestCreateTransactionPurgesExpiredKeys(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	products := NewProductRepository(store)
+	transactions := NewTransactionRepository(store)
+
+	product := &dto.ProductRequest{Name: "Kopi", Price: model.NewMoney(8000), Stock: 10}
+	if err := products.Create(ctx, product); err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	checkout := func(key string, expiresAt time.Time) {
+		t.Helper()
+		_, _, err := transactions.CreateTransaction(ctx, &model.CheckoutRequest{
+			Items:    []model.CheckoutItem{{ProductID: product.ID, Quantity: 1}},
+			Payments: []model.PaymentRequest{{Method: model.PaymentCash, Amount: model.NewMoney(8000)}},
+		}, nil, &model.IdempotencyKey{Key: key, RequestHash: key, ExpiresAt: expiresAt}, model.TaxPolicy{Inclusive: true})
+		if err != nil {
+			t.Fatalf("checkout %s: %v", key, err)
+		}
+	}
+
+	now := time.Now()
+	checkout("expired-1", now.Add(-time.Minute))
+	checkout("expired-2", now.Add(-time.Second))
+	checkout("live", now.Add(time.Hour))
+
+	if len(store.idempotencyKeys) != 1 {
+		t.Errorf("want only the live key kept, got %v", store.idempotencyKeys)
+	}
+	if _, ok := store.idempotencyKeys[scopeOf("live", nil)]; !ok {
+		t.Error("live key was purged")
+	}
+}
+
+func TestCreateTransactionSkipsPromotionsNotRunning(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	now := time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	products := NewProductRepository(store)
+	promotions := NewPromotionRepository(store)
+	transactions := NewTransactionRepository(store)
+
+	product := &dto.ProductRequest{Name: "Kopi", Price: model.NewMoney(10000), Stock: 10}
+	if err := products.Create(ctx, product); err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	yesterday, tomorrow := now.AddDate(0, 0, -1), now.AddDate(0, 0, 1)
+	for _, promotion := range []model.Promotion{
+		{Name: "inactive", Type: model.PromotionPercentage, Value: 50},
+		{Name: "expired", Type: model.PromotionPercentage, Value: 40, Active: true, EndsAt: &yesterday},
+		{Name: "upcoming", Type: model.PromotionPercentage, Value: 30, Active: true, StartsAt: &tomorrow},
+		{Name: "running", Type: model.PromotionPercentage, Value: 10, Active: true, StartsAt: &yesterday, EndsAt: &tomorrow},
+	} {
+		if err := promotions.Create(ctx, &promotion); err != nil {
+			t.Fatalf("create promotion %s: %v", promotion.Name, err)
+		}
+	}
+
+	transaction, _, err := transactions.CreateTransaction(ctx, &model.CheckoutRequest{
+		Items:    []model.CheckoutItem{{ProductID: product.ID, Quantity: 1}},
+		Payments: []model.PaymentRequest{{Method: model.PaymentCash, Amount: model.NewMoney(10000)}},
+	}, nil, nil, model.TaxPolicy{Inclusive: true})
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	if transaction.DiscountAmount.Amount != 1000 || transaction.TotalAmount.Amount != 9000 {
+		t.Errorf("want only the running promotion applied, got discount %d, total %d", transaction.DiscountAmount.Amount, transaction.TotalAmount.Amount)
+	}
+	if id := transaction.Details[0].PromotionID; id == nil || *id != 4 {
+		t.Errorf("want promotion 4 on the line, got %v", id)
+	}
+}
+
+// TestGetReport rings up two sales on one day and refunds part of one the
+// next, then reports on ranges with sales, with only the refund and with
+// neither.
+func TestGetReport(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	now := time.Date(2026, 3, 8, 10, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	categories := NewCategoryRepository(store)
+	products := NewProductRepository(store)
+	transactions := NewTransactionRepository(store)
+
+	drinks := &model.Category{Name: "Minuman"}
+	food := &model.Category{Name: "Makanan"}
+	for _, category := range []*model.Category{drinks, food} {
+		if err := categories.Create(ctx, category); err != nil {
+			t.Fatalf("create category: %v", err)
+		}
+	}
+	kopi := &dto.ProductRequest{Name: "Kopi", Price: model.NewMoney(9000), Stock: 10, Categories: []int{drinks.ID}}
+	teh := &dto.ProductRequest{Name: "Teh", Price: model.NewMoney(5000), Stock: 10, Categories: []int{drinks.ID}}
+	roti := &dto.ProductRequest{Name: "Roti", Price: model.NewMoney(4000), Stock: 10, Categories: []int{food.ID}}
+	for _, product := range []*dto.ProductRequest{kopi, teh, roti} {
+		if err := products.Create(ctx, product); err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+	}
+
+	var sales []*model.Transaction
+	for _, items := range [][]model.CheckoutItem{
+		{{ProductID: kopi.ID, Quantity: 2}, {ProductID: teh.ID, Quantity: 1}},
+		{{ProductID: roti.ID, Quantity: 3}, {ProductID: kopi.ID, Quantity: 1}},
+	} {
+		sale, _, err := transactions.CreateTransaction(ctx, &model.CheckoutRequest{
+			Items:    items,
+			Payments: []model.PaymentRequest{{Method: model.PaymentCash, Amount: model.NewMoney(50000)}},
+		}, nil, nil, model.TaxPolicy{Inclusive: true})
+		if err != nil {
+			t.Fatalf("CreateTransaction: %v", err)
+		}
+		sales = append(sales, sale)
+	}
+
+	now = now.AddDate(0, 0, 1)
+	var rotiDetail int
+	for _, detail := range sales[1].Details {
+		if detail.ProductID == roti.ID {
+			rotiDetail = detail.ID
+		}
+	}
+	_, err := transactions.CreateRefund(ctx, sales[1].ID, model.RefundTypeRefund, &model.RefundRequest{
+		Items: []model.RefundItem{{TransactionDetailID: rotiDetail, Quantity: 1}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("CreateRefund: %v", err)
+	}
+
+	day := func(offset int) time.Time { return time.Date(2026, 3, 8+offset, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name                string
+		from, to            time.Time
+		transactions, items int
+		gross, refunds, net int64
+		// top is the expected best sellers by quantity, ties broken by id
+		top        []int
+		categories map[int]int64
+	}{
+		{
+			name: "sales day", from: day(0), to: day(1),
+			transactions: 2, items: 7,
+			gross: 44000, net: 44000,
+			top:        []int{kopi.ID, roti.ID},
+			categories: map[int]int64{drinks.ID: 32000, food.ID: 12000},
+		},
+		{
+			name: "only a refund", from: day(1), to: day(2),
+			refunds: 4000, net: -4000,
+		},
+		{
+			name: "no sales", from: day(2), to: day(3),
+		},
+		{
+			name: "both days", from: day(0), to: day(2),
+			transactions: 2, items: 7,
+			gross: 44000, refunds: 4000, net: 40000,
+			top:        []int{kopi.ID, roti.ID},
+			categories: map[int]int64{drinks.ID: 32000, food.ID: 12000},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := transactions.GetReport(ctx, &model.ReportFilter{StartDate: tt.from, EndDate: tt.to, Top: 2})
+			if err != nil {
+				t.Fatalf("GetReport: %v", err)
+			}
+			if report.TotalTransaks != tt.transactions || report.ItemsSold != tt.items {
+				t.Errorf("want %d transactions and %d items, got %d and %d", tt.transactions, tt.items, report.TotalTransaks, report.ItemsSold)
+			}
+			if report.GrossSales.Amount != tt.gross || report.TotalRefunds.Amount != tt.refunds || report.NetRevenue.Amount != tt.net {
+				t.Errorf("want gross %d, refunds %d and net %d, got %d, %d and %d",
+					tt.gross, tt.refunds, tt.net, report.GrossSales.Amount, report.TotalRefunds.Amount, report.NetRevenue.Amount)
+			}
+
+			if len(report.TopByQuantity) != len(tt.top) || len(report.TopByRevenue) != len(tt.top) {
+				t.Fatalf("want %d best sellers, got %+v and %+v", len(tt.top), report.TopByQuantity, report.TopByRevenue)
+			}
+			for i, productID := range tt.top {
+				if report.TopByQuantity[i].ProductID != productID {
+					t.Errorf("best seller %d: want product %d, got %+v", i, productID, report.TopByQuantity[i])
+				}
+			}
+
+			if len(report.Categories) != len(tt.categories) {
+				t.Fatalf("want %d categories, got %+v", len(tt.categories), report.Categories)
+			}
+			for _, row := range report.Categories {
+				if row.CategoryID == nil || row.Revenue.Amount != tt.categories[*row.CategoryID] {
+					t.Errorf("want category revenue %v, got %+v", tt.categories, row)
+				}
+			}
+			if len(report.Payments) != len(model.PaymentMethods) {
+				t.Errorf("want a row per payment method, got %+v", report.Payments)
+			}
+		})
+	}
+}
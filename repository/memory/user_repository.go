@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"category-crud/model"
+	"category-crud/repository"
+	"context"
+	"sort"
+)
+
+type userRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) repository.UserRepository {
+	return &userRepository{store: store}
+}
+
+func (repo *userRepository) GetAll(ctx context.Context) ([]model.User, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	users := make([]model.User, 0, len(repo.store.users))
+	for _, user := range repo.store.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+
+	return users, nil
+}
+
+func (repo *userRepository) Create(ctx context.Context, user *model.User) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if repo.store.usernameTaken(user.Username, 0) {
+		return repository.ErrUsernameTaken
+	}
+
+	now := repo.store.now()
+	user.ID = repo.store.nextID("users")
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	repo.store.users[user.ID] = *user
+
+	return nil
+}
+
+func (repo *userRepository) GetByID(ctx context.Context, id int) (*model.User, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	user, ok := repo.store.users[id]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+
+	return &user, nil
+}
+
+func (repo *userRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	for _, user := range repo.store.users {
+		if user.Username == username {
+			return &user, nil
+		}
+	}
+
+	return nil, repository.ErrUserNotFound
+}
+
+func (repo *userRepository) Update(ctx context.Context, user *model.User) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	stored, ok := repo.store.users[user.ID]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	if repo.store.usernameTaken(user.Username, user.ID) {
+		return repository.ErrUsernameTaken
+	}
+
+	if user.PasswordHash == "" {
+		user.PasswordHash = stored.PasswordHash
+	}
+	user.CreatedAt = stored.CreatedAt
+	user.UpdatedAt = repo.store.now()
+	repo.store.users[user.ID] = *user
+
+	return nil
+}
+
+func (repo *userRepository) Delete(ctx context.Context, id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, ok := repo.store.users[id]; !ok {
+		return repository.ErrUserNotFound
+	}
+
+	// transactions.cashier_id and shifts.cashier_id have no ON DELETE
+	// action
+	for _, transaction := range repo.store.transactions {
+		if transaction.CashierID != nil && *transaction.CashierID == id {
+			return repository.ErrUserHasTransactions
+		}
+	}
+	for _, shift := range repo.store.shifts {
+		if shift.CashierID != nil && *shift.CashierID == id {
+			return repository.ErrUserHasTransactions
+		}
+	}
+	delete(repo.store.users, id)
+
+	return nil
+}
+
+// usernameTaken mirrors the UNIQUE constraint on users.username. Callers
+// must hold the lock.
+func (s *Store) usernameTaken(username string, exceptID int) bool {
+	for _, user := range s.users {
+		if user.Username == username && user.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"category-crud/apperror"
+	"category-crud/model"
+	"math"
+)
+
+// PaymentMismatch details a checkout whose payments do not settle its
+// total.
+type PaymentMismatch struct {
+	Total model.Money `json:"total"`
+	Paid  model.Money `json:"paid"`
+	// NonCash is what card, QRIS and e-wallet payments add up to.
+	NonCash model.Money `json:"non_cash"`
+}
+
+// SettlePayments checks that payments cover total and turns them into
+// payment records. Only cash gives change: card, QRIS and e-wallet
+// payments may not exceed the total, and the excess must be smaller than
+// every cash payment, so none of them could have been left out. Change is
+// taken from the last cash payments first. A total of 0 is settled by no
+// payments at all.
+func SettlePayments(total model.Money, requests []model.PaymentRequest) ([]model.Payment, error) {
+	paid, nonCash := model.NewMoney(0), model.NewMoney(0)
+	smallestCash := int64(math.MaxInt64)
+	for _, request := range requests {
+		var err error
+		if paid, err = paid.Add(request.Amount); err != nil {
+			return nil, err
+		}
+		// amounts are positive, so nonCash cannot overflow when paid
+		// does not
+		if request.Method == model.PaymentCash {
+			smallestCash = min(smallestCash, request.Amount.Amount)
+		} else {
+			nonCash, _ = nonCash.Add(request.Amount)
+		}
+	}
+
+	mismatch := PaymentMismatch{Total: total, Paid: paid, NonCash: nonCash}
+	if paid.Amount < total.Amount {
+		return nil, apperror.Unprocessable("payments do not cover the total", mismatch)
+	}
+	if nonCash.Amount > total.Amount {
+		return nil, apperror.Unprocessable("card, QRIS and e-wallet payments exceed the total, only cash gives change", mismatch)
+	}
+	change, _ := paid.Sub(total)
+	if change.Amount > 0 && change.Amount >= smallestCash {
+		return nil, apperror.Unprocessable("payments exceed the total by a whole cash payment, which is not needed", mismatch)
+	}
+
+	payments := make([]model.Payment, len(requests))
+	for i := len(requests) - 1; i >= 0; i-- {
+		request := requests[i]
+		payment := model.Payment{
+			Method:   request.Method,
+			Amount:   request.Amount,
+			Tendered: request.Amount,
+			Change:   model.NewMoney(0),
+		}
+		if request.Reference != "" {
+			reference := request.Reference
+			payment.Reference = &reference
+		}
+		if request.Method == model.PaymentCash && change.Amount > 0 {
+			payment.Change = change.Min(request.Amount)
+			payment.Amount, _ = request.Amount.Sub(payment.Change)
+			change, _ = change.Sub(payment.Change)
+		}
+		payments[i] = payment
+	}
+	return payments, nil
+}
+
+// paymentMethods returns one row per payment method in
+// model.PaymentMethods order, zeroed for methods that were not used.
+func paymentMethods(rows []model.PaymentSales) []model.PaymentSales {
+	byMethod := make(map[string]model.PaymentSales, len(rows))
+	for _, row := range rows {
+		byMethod[row.Method] = row
+	}
+
+	methods := make([]model.PaymentSales, 0, len(model.PaymentMethods))
+	for _, method := range model.PaymentMethods {
+		row, ok := byMethod[method]
+		if !ok {
+			row = model.PaymentSales{
+				Method:   method,
+				Amount:   model.NewMoney(0),
+				Tendered: model.NewMoney(0),
+				Change:   model.NewMoney(0),
+			}
+		}
+		methods = append(methods, row)
+	}
+	return methods
+}
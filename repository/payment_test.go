@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"category-crud/apperror"
+	"category-crud/model"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func tender(method string, amount int64, reference string) model.PaymentRequest {
+	return model.PaymentRequest{Method: method, Amount: model.NewMoney(amount), Reference: reference}
+}
+
+// settled is a recorded payment reduced to method, amount, tendered and
+// change.
+type settled struct {
+	method                   string
+	amount, tendered, change int64
+}
+
+func TestSettlePayments(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int64
+		requests []model.PaymentRequest
+		want     []settled
+	}{
+		{
+			name:     "exact cash",
+			total:    10000,
+			requests: []model.PaymentRequest{tender(model.PaymentCash, 10000, "")},
+			want:     []settled{{model.PaymentCash, 10000, 10000, 0}},
+		},
+		{
+			name:     "cash change",
+			total:    9000,
+			requests: []model.PaymentRequest{tender(model.PaymentCash, 20000, "")},
+			want:     []settled{{model.PaymentCash, 9000, 20000, 11000}},
+		},
+		{
+			name:     "exact card",
+			total:    9000,
+			requests: []model.PaymentRequest{tender(model.PaymentCard, 9000, "slip-1")},
+			want:     []settled{{model.PaymentCard, 9000, 9000, 0}},
+		},
+		{
+			name:  "split tender with change",
+			total: 25000,
+			requests: []model.PaymentRequest{
+				tender(model.PaymentCard, 15000, "slip-1"),
+				tender(model.PaymentCash, 20000, ""),
+			},
+			want: []settled{{model.PaymentCard, 15000, 15000, 0}, {model.PaymentCash, 10000, 20000, 10000}},
+		},
+		{
+			name:  "change from the last cash payment",
+			total: 25000,
+			requests: []model.PaymentRequest{
+				tender(model.PaymentCash, 10000, ""),
+				tender(model.PaymentQRIS, 10000, "qr-1"),
+				tender(model.PaymentCash, 10000, ""),
+			},
+			want: []settled{{model.PaymentCash, 10000, 10000, 0}, {model.PaymentQRIS, 10000, 10000, 0}, {model.PaymentCash, 5000, 10000, 5000}},
+		},
+		{
+			name:  "non-cash methods adding up exactly",
+			total: 10000,
+			requests: []model.PaymentRequest{
+				tender(model.PaymentQRIS, 4000, "qr-1"),
+				tender(model.PaymentEWallet, 6000, "ew-1"),
+			},
+			want: []settled{{model.PaymentQRIS, 4000, 4000, 0}, {model.PaymentEWallet, 6000, 6000, 0}},
+		},
+		{
+			name:  "two cash payments both needed",
+			total: 12000,
+			requests: []model.PaymentRequest{
+				tender(model.PaymentCash, 5000, ""),
+				tender(model.PaymentCash, 10000, ""),
+			},
+			want: []settled{{model.PaymentCash, 5000, 5000, 0}, {model.PaymentCash, 7000, 10000, 3000}},
+		},
+		{
+			name:  "nothing to pay",
+			total: 0,
+			want:  []settled{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payments, err := SettlePayments(model.NewMoney(tt.total), tt.requests)
+			if err != nil {
+				t.Fatalf("SettlePayments: %v", err)
+			}
+
+			got := make([]settled, len(payments))
+			var amount int64
+			for i, payment := range payments {
+				got[i] = settled{payment.Method, payment.Amount.Amount, payment.Tendered.Amount, payment.Change.Amount}
+				amount += payment.Amount.Amount
+
+				if payment.Tendered.Amount-payment.Change.Amount != payment.Amount.Amount {
+					t.Errorf("payment %d: tendered %d minus change %d is not amount %d", i, payment.Tendered.Amount, payment.Change.Amount, payment.Amount.Amount)
+				}
+				reference := ""
+				if payment.Reference != nil {
+					reference = *payment.Reference
+				}
+				if reference != tt.requests[i].Reference {
+					t.Errorf("payment %d: want reference %q, got %q", i, tt.requests[i].Reference, reference)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+			if amount != tt.total {
+				t.Errorf("payments add up to %d, want the total %d", amount, tt.total)
+			}
+		})
+	}
+}
+
+func TestSettlePaymentsRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int64
+		requests []model.PaymentRequest
+		want     PaymentMismatch
+	}{
+		{
+			name:     "underpaid",
+			total:    10000,
+			requests: []model.PaymentRequest{tender(model.PaymentCash, 5000, ""), tender(model.PaymentCard, 4000, "slip-1")},
+			want:     PaymentMismatch{Total: model.NewMoney(10000), Paid: model.NewMoney(9000), NonCash: model.NewMoney(4000)},
+		},
+		{
+			name:     "card over the total",
+			total:    10000,
+			requests: []model.PaymentRequest{tender(model.PaymentCard, 12000, "slip-1")},
+			want:     PaymentMismatch{Total: model.NewMoney(10000), Paid: model.NewMoney(12000), NonCash: model.NewMoney(12000)},
+		},
+		{
+			name:     "non-cash over the total together",
+			total:    10000,
+			requests: []model.PaymentRequest{tender(model.PaymentQRIS, 6000, "qr-1"), tender(model.PaymentEWallet, 6000, "ew-1"), tender(model.PaymentCash, 1000, "")},
+			want:     PaymentMismatch{Total: model.NewMoney(10000), Paid: model.NewMoney(13000), NonCash: model.NewMoney(12000)},
+		},
+		{
+			name:     "cash after non-cash covered the total",
+			total:    10000,
+			requests: []model.PaymentRequest{tender(model.PaymentCard, 10000, "slip-1"), tender(model.PaymentCash, 5000, "")},
+			want:     PaymentMismatch{Total: model.NewMoney(10000), Paid: model.NewMoney(15000), NonCash: model.NewMoney(10000)},
+		},
+		{
+			name:     "a second cash payment not needed",
+			total:    10000,
+			requests: []model.PaymentRequest{tender(model.PaymentCash, 10000, ""), tender(model.PaymentCash, 10000, "")},
+			want:     PaymentMismatch{Total: model.NewMoney(10000), Paid: model.NewMoney(20000), NonCash: model.NewMoney(0)},
+		},
+		{
+			name:     "a small cash payment not needed",
+			total:    10000,
+			requests: []model.PaymentRequest{tender(model.PaymentCash, 500, ""), tender(model.PaymentCash, 20000, "")},
+			want:     PaymentMismatch{Total: model.NewMoney(10000), Paid: model.NewMoney(20500), NonCash: model.NewMoney(0)},
+		},
+		{
+			name:  "no payments",
+			total: 10000,
+			want:  PaymentMismatch{Total: model.NewMoney(10000), Paid: model.NewMoney(0), NonCash: model.NewMoney(0)},
+		},
+		{
+			name:     "cash for nothing",
+			total:    0,
+			requests: []model.PaymentRequest{tender(model.PaymentCash, 1000, "")},
+			want:     PaymentMismatch{Total: model.NewMoney(0), Paid: model.NewMoney(1000), NonCash: model.NewMoney(0)},
+		},
+		{
+			name:     "card for nothing",
+			total:    0,
+			requests: []model.PaymentRequest{tender(model.PaymentCard, 1000, "slip-1")},
+			want:     PaymentMismatch{Total: model.NewMoney(0), Paid: model.NewMoney(1000), NonCash: model.NewMoney(1000)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payments, err := SettlePayments(model.NewMoney(tt.total), tt.requests)
+			var appErr *apperror.Error
+			if !errors.As(err, &appErr) || appErr.Code != apperror.CodeUnprocessable {
+				t.Fatalf("want an unprocessable error, got %v, %v", payments, err)
+			}
+			if !reflect.DeepEqual(appErr.Details, tt.want) {
+				t.Errorf("want details %+v, got %+v", tt.want, appErr.Details)
+			}
+		})
+	}
+}
+
+func TestSettlePaymentsOverflow(t *testing.T) {
+	requests := []model.PaymentRequest{tender(model.PaymentCash, math.MaxInt64, ""), tender(model.PaymentCash, 1, "")}
+	if _, err := SettlePayments(model.NewMoney(100), requests); !errors.Is(err, model.ErrAmountOverflow) {
+		t.Errorf("want ErrAmountOverflow, got %v", err)
+	}
+}
@@ -0,0 +1,7 @@
+package repository
+
+import "time"
+
+// testQueryTimeout bounds each query of the tests run against
+// testdb.Open.
+const testQueryTimeout = 10 * time.Second
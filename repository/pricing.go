@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"category-crud/model"
+	"slices"
+	"sort"
+)
+
+// PriceItems turns checkout items into transaction details at the
+// current price of products and returns their subtotal. It fails with
+// model.ErrAmountOverflow when a line or the basket is too large to
+// represent.
+func PriceItems(items []model.CheckoutItem, products map[int]model.Product) ([]model.TransactionDetail, model.Money, error) {
+	details := make([]model.TransactionDetail, 0, len(items))
+	subtotalAmount := model.NewMoney(0)
+	for _, item := range items {
+		product := products[item.ProductID]
+		subtotal, err := product.Price.Mul(int64(item.Quantity))
+		if err != nil {
+			return nil, model.Money{}, err
+		}
+		if subtotalAmount, err = subtotalAmount.Add(subtotal); err != nil {
+			return nil, model.Money{}, err
+		}
+
+		details = append(details, model.TransactionDetail{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			UnitPrice:   product.Price,
+			Quantity:    item.Quantity,
+			Subtotal:    subtotal,
+		})
+	}
+	return details, subtotalAmount, nil
+}
+
+// ApplyPromotions discounts checkout lines with the promotions running at
+// checkout time. The outcome does not depend on the order of promotions:
+//
+//   - a promotion only counts when the subtotal of its matching lines,
+//     before any discount, reaches MinSubtotal;
+//   - each line gets at most one percentage, fixed or buy_x_get_y
+//     promotion, the one with the largest discount;
+//   - then at most one basket promotion is applied, again the largest, to
+//     what its lines still cost, and shared between them in proportion;
+//   - ties go to the lowest promotion id.
+//
+// DiscountAmount and PromotionID of details are set in place. categories
+// maps product ids to the ids of their live categories. The total
+// discount and the applied basket promotion are returned. Discounts never
+// exceed what they apply to, so once the caller has checked that the
+// subtotals add up without overflow, the arithmetic here cannot overflow.
+func ApplyPromotions(details []model.TransactionDetail, categories map[int][]int, promotions []model.Promotion) (model.Money, *int) {
+	for i := range details {
+		details[i].DiscountAmount = model.NewMoney(0)
+		details[i].PromotionID = nil
+	}
+
+	sorted := slices.Clone(promotions)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	var baskets []model.Promotion
+	matching := make(map[int][]int, len(sorted)) // promotion id -> line indexes
+	for _, promotion := range sorted {
+		lines := matchingLines(&promotion, details, categories)
+		if len(lines) == 0 {
+			continue
+		}
+		var subtotal int64
+		for _, i := range lines {
+			subtotal += details[i].Subtotal.Amount
+		}
+		if subtotal < promotion.MinSubtotal.Amount {
+			continue
+		}
+
+		if promotion.Type == model.PromotionBasket {
+			baskets = append(baskets, promotion)
+			matching[promotion.ID] = lines
+			continue
+		}
+		for _, i := range lines {
+			if amount := lineDiscount(&promotion, &details[i]); amount > details[i].DiscountAmount.Amount {
+				id := promotion.ID
+				details[i].DiscountAmount = model.NewMoney(amount)
+				details[i].PromotionID = &id
+			}
+		}
+	}
+
+	var basketID *int
+	var basketDiscount int64
+	for _, promotion := range baskets {
+		var remaining int64
+		for _, i := range matching[promotion.ID] {
+			remaining += details[i].Net().Amount
+		}
+		if amount := min(promotion.Value, remaining); amount > basketDiscount {
+			id := promotion.ID
+			basketID = &id
+			basketDiscount = amount
+		}
+	}
+	if basketID != nil {
+		shareDiscount(details, matching[*basketID], basketDiscount)
+	}
+
+	var discount int64
+	for _, detail := range details {
+		discount += detail.DiscountAmount.Amount
+	}
+	return model.NewMoney(discount), basketID
+}
+
+// matchingLines returns the indexes of the lines a promotion targets.
+func matchingLines(promotion *model.Promotion, details []model.TransactionDetail, categories map[int][]int) []int {
+	var lines []int
+	for i, detail := range details {
+		switch {
+		case promotion.ProductID != nil:
+			if detail.ProductID != *promotion.ProductID {
+				continue
+			}
+		case promotion.CategoryID != nil:
+			if !slices.Contains(categories[detail.ProductID], *promotion.CategoryID) {
+				continue
+			}
+		}
+		lines = append(lines, i)
+	}
+	return lines
+}
+
+// lineDiscount is what a line promotion takes off one line, in minor
+// units. It never exceeds the line subtotal.
+func lineDiscount(promotion *model.Promotion, detail *model.TransactionDetail) int64 {
+	switch promotion.Type {
+	case model.PromotionPercentage:
+		return detail.Subtotal.Fraction(min(promotion.Value, 100), 100, model.RoundDown).Amount
+	case model.PromotionFixed:
+		return min(promotion.Value, detail.UnitPrice.Amount) * int64(detail.Quantity)
+	case model.PromotionBuyXGetY:
+		if promotion.BuyQuantity <= 0 || promotion.GetQuantity <= 0 {
+			return 0
+		}
+		free := detail.Quantity / (promotion.BuyQuantity + promotion.GetQuantity) * promotion.GetQuantity
+		return int64(free) * detail.UnitPrice.Amount
+	}
+	return 0
+}
+
+// shareDiscount spreads amount over lines in proportion to what they still
+// cost. The rounding remainder is handed out one unit at a time in line
+// order, so the shares add up to amount exactly. amount must not exceed
+// what the lines cost.
+func shareDiscount(details []model.TransactionDetail, lines []int, amount int64) {
+	var total int64
+	for _, i := range lines {
+		total += details[i].Net().Amount
+	}
+
+	shares := make([]int64, len(lines))
+	var shared int64
+	for k, i := range lines {
+		shares[k] = model.NewMoney(amount).Fraction(details[i].Net().Amount, total, model.RoundDown).Amount
+		shared += shares[k]
+	}
+	for k := 0; shared < amount; k = (k + 1) % len(lines) {
+		if shares[k] < details[lines[k]].Net().Amount {
+			shares[k]++
+			shared++
+		}
+	}
+
+	for k, i := range lines {
+		details[i].DiscountAmount.Amount += shares[k]
+	}
+}
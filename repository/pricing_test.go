@@ -0,0 +1,336 @@
+package repository
+
+import (
+	"category-crud/model"
+	"reflect"
+	"testing"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+// line is a checkout line of quantity units at price.
+func line(productID int, price int64, quantity int) model.TransactionDetail {
+	return model.TransactionDetail{
+		ProductID: productID,
+		UnitPrice: model.NewMoney(price),
+		Quantity:  quantity,
+		Subtotal:  model.NewMoney(price * int64(quantity)),
+	}
+}
+
+func discounts(details []model.TransactionDetail) []int64 {
+	amounts := make([]int64, len(details))
+	for i, detail := range details {
+		amounts[i] = detail.DiscountAmount.Amount
+	}
+	return amounts
+}
+
+func promotionIDs(details []model.TransactionDetail) []int {
+	ids := make([]int, len(details))
+	for i, detail := range details {
+		if detail.PromotionID != nil {
+			ids[i] = *detail.PromotionID
+		}
+	}
+	return ids
+}
+
+func TestApplyPromotions(t *testing.T) {
+	// products 1 and 2 are in category 7, product 3 in none
+	categories := map[int][]int{1: {7}, 2: {7, 8}}
+
+	tests := []struct {
+		name       string
+		details    []model.TransactionDetail
+		promotions []model.Promotion
+		// want holds the discount of each line, wantIDs the line promotion
+		// it got, 0 for none
+		want       []int64
+		wantIDs    []int
+		wantTotal  int64
+		wantBasket *int
+	}{
+		{
+			name:      "no promotions",
+			details:   []model.TransactionDetail{line(1, 10000, 2)},
+			want:      []int64{0},
+			wantIDs:   []int{0},
+			wantTotal: 0,
+		},
+		{
+			name:    "percentage beats fixed",
+			details: []model.TransactionDetail{line(1, 10000, 2)},
+			promotions: []model.Promotion{
+				{ID: 1, Type: model.PromotionFixed, Value: 1500, ProductID: ptr(1)},
+				{ID: 2, Type: model.PromotionPercentage, Value: 20, ProductID: ptr(1)},
+			},
+			want:      []int64{4000},
+			wantIDs:   []int{2},
+			wantTotal: 4000,
+		},
+		{
+			name:    "fixed beats percentage",
+			details: []model.TransactionDetail{line(1, 10000, 2)},
+			promotions: []model.Promotion{
+				{ID: 1, Type: model.PromotionFixed, Value: 3000, ProductID: ptr(1)},
+				{ID: 2, Type: model.PromotionPercentage, Value: 20, ProductID: ptr(1)},
+			},
+			want:      []int64{6000},
+			wantIDs:   []int{1},
+			wantTotal: 6000,
+		},
+		{
+			name:    "tie goes to the lowest id whatever the order",
+			details: []model.TransactionDetail{line(1, 10000, 2)},
+			promotions: []model.Promotion{
+				{ID: 5, Type: model.PromotionPercentage, Value: 10},
+				{ID: 3, Type: model.PromotionFixed, Value: 1000, ProductID: ptr(1)},
+			},
+			want:      []int64{2000},
+			wantIDs:   []int{3},
+			wantTotal: 2000,
+		},
+		{
+			name:    "overlapping product and category promotions",
+			details: []model.TransactionDetail{line(1, 10000, 1), line(2, 5000, 2), line(3, 4000, 1)},
+			promotions: []model.Promotion{
+				{ID: 1, Type: model.PromotionPercentage, Value: 10, CategoryID: ptr(7)},
+				{ID: 2, Type: model.PromotionFixed, Value: 2500, ProductID: ptr(1)},
+			},
+			want:      []int64{2500, 1000, 0},
+			wantIDs:   []int{2, 1, 0},
+			wantTotal: 3500,
+		},
+		{
+			name:    "fixed larger than the unit price",
+			details: []model.TransactionDetail{line(1, 3000, 2)},
+			promotions: []model.Promotion{
+				{ID: 1, Type: model.PromotionFixed, Value: 5000},
+			},
+			want:      []int64{6000},
+			wantIDs:   []int{1},
+			wantTotal: 6000,
+		},
+		{
+			name:    "percentage over 100",
+			details: []model.TransactionDetail{line(1, 3000, 2)},
+			promotions: []model.Promotion{
+				{ID: 1, Type: model.PromotionPercentage, Value: 150},
+			},
+			want:      []int64{6000},
+			wantIDs:   []int{1},
+			wantTotal: 6000,
+		},
+		{
+			name:    "buy 2 get 1",
+			details: []model.TransactionDetail{line(1, 4000, 7)},
+			promotions: []model.Promotion{
+				{ID: 1, Type: model.PromotionBuyXGetY, ProductID: ptr(1), BuyQuantity: 2, GetQuantity: 1},
+			},
+			want:      []int64{8000},
+			wantIDs:   []int{1},
+			wantTotal: 8000,
+		},
+		{
+			name:    "minimum counts the matching lines only",
+			details: []model.TransactionDetail{line(1, 5000, 3), line(3, 50000, 1)},
+			promotions: []model.Promotion{
+				{ID: 1, Type: model.PromotionPercentage, Value: 10, CategoryID: ptr(7), MinSubtotal: model.NewMoney(20000)},
+			},
+			want:      []int64{0, 0},
+			wantIDs:   []int{0, 0},
+			wantTotal: 0,
+		},
+		{
+			name:    "minimum reached",
+			details: []model.TransactionDetail{line(1, 5000, 3), line(2, 5000, 1)},
+			promotions: []model.Promotion{
+				{ID: 1, Type: model.PromotionPercentage, Value: 10, CategoryID: ptr(7), MinSubtotal: model.NewMoney(20000)},
+			},
+			want:      []int64{1500, 500},
+			wantIDs:   []int{1, 1},
+			wantTotal: 2000,
+		},
+		{
+			name:    "basket remainder on an uneven split",
+			details: []model.TransactionDetail{line(1, 10000, 1), line(2, 10000, 1), line(3, 10000, 1)},
+			promotions: []model.Promotion{
+				{ID: 4, Type: model.PromotionBasket, Value: 1000},
+			},
+			want:       []int64{334, 333, 333},
+			wantIDs:    []int{0, 0, 0},
+			wantTotal:  1000,
+			wantBasket: ptr(4),
+		},
+		{
+			name:    "basket on what lines cost after line discounts",
+			details: []model.TransactionDetail{line(1, 10000, 1), line(3, 5000, 1)},
+			promotions: []model.Promotion{
+				{ID: 1, Type: model.PromotionPercentage, Value: 50, ProductID: ptr(1)},
+				{ID: 2, Type: model.PromotionBasket, Value: 3000},
+			},
+			want:       []int64{6500, 1500},
+			wantIDs:    []int{1, 0},
+			wantTotal:  8000,
+			wantBasket: ptr(2),
+		},
+		{
+			name:    "basket larger than the lines",
+			details: []model.TransactionDetail{line(1, 2000, 1)},
+			promotions: []model.Promotion{
+				{ID: 1, Type: model.PromotionBasket, Value: 5000},
+			},
+			want:       []int64{2000},
+			wantIDs:    []int{0},
+			wantTotal:  2000,
+			wantBasket: ptr(1),
+		},
+		{
+			name:    "largest basket wins",
+			details: []model.TransactionDetail{line(1, 10000, 1), line(3, 10000, 1)},
+			promotions: []model.Promotion{
+				{ID: 1, Type: model.PromotionBasket, Value: 1000},
+				{ID: 2, Type: model.PromotionBasket, Value: 2000, CategoryID: ptr(7)},
+			},
+			want:       []int64{2000, 0},
+			wantIDs:    []int{0, 0},
+			wantTotal:  2000,
+			wantBasket: ptr(2),
+		},
+		{
+			name:    "basket below its minimum",
+			details: []model.TransactionDetail{line(1, 10000, 2)},
+			promotions: []model.Promotion{
+				{ID: 1, Type: model.PromotionBasket, Value: 5000, MinSubtotal: model.NewMoney(50000)},
+			},
+			want:      []int64{0},
+			wantIDs:   []int{0},
+			wantTotal: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// leftovers of an earlier pricing are reset
+			for i := range tt.details {
+				tt.details[i].DiscountAmount = model.NewMoney(999)
+				tt.details[i].PromotionID = ptr(999)
+			}
+
+			total, basketID := ApplyPromotions(tt.details, categories, tt.promotions)
+			if got := discounts(tt.details); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want line discounts %v, got %v", tt.want, got)
+			}
+			if got := promotionIDs(tt.details); !reflect.DeepEqual(got, tt.wantIDs) {
+				t.Errorf("want line promotions %v, got %v", tt.wantIDs, got)
+			}
+			if total.Amount != tt.wantTotal {
+				t.Errorf("want total discount %d, got %d", tt.wantTotal, total.Amount)
+			}
+			if !reflect.DeepEqual(basketID, tt.wantBasket) {
+				t.Errorf("want basket promotion %v, got %v", tt.wantBasket, basketID)
+			}
+			for i, detail := range tt.details {
+				if detail.Net().Amount < 0 {
+					t.Errorf("line %d discounted below zero: %d", i, detail.Net().Amount)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchingLines(t *testing.T) {
+	details := []model.TransactionDetail{line(1, 1000, 1), line(2, 1000, 1), line(3, 1000, 1), line(1, 1000, 2)}
+	categories := map[int][]int{1: {7}, 2: {7, 8}}
+
+	tests := []struct {
+		name      string
+		promotion model.Promotion
+		want      []int
+	}{
+		{"every line", model.Promotion{}, []int{0, 1, 2, 3}},
+		{"product on two lines", model.Promotion{ProductID: ptr(1)}, []int{0, 3}},
+		{"product not in the basket", model.Promotion{ProductID: ptr(9)}, nil},
+		{"category", model.Promotion{CategoryID: ptr(7)}, []int{0, 1, 3}},
+		{"second category of a product", model.Promotion{CategoryID: ptr(8)}, []int{1}},
+		{"category without products", model.Promotion{CategoryID: ptr(9)}, nil},
+		{"product wins over category", model.Promotion{ProductID: ptr(3), CategoryID: ptr(7)}, []int{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchingLines(&tt.promotion, details, categories); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLineDiscount(t *testing.T) {
+	tests := []struct {
+		name      string
+		promotion model.Promotion
+		detail    model.TransactionDetail
+		want      int64
+	}{
+		{"percentage rounds down", model.Promotion{Type: model.PromotionPercentage, Value: 10}, line(1, 3333, 3), 999},
+		{"percentage over 100", model.Promotion{Type: model.PromotionPercentage, Value: 101}, line(1, 3333, 3), 9999},
+		{"fixed per unit", model.Promotion{Type: model.PromotionFixed, Value: 500}, line(1, 3000, 3), 1500},
+		{"fixed above the unit price", model.Promotion{Type: model.PromotionFixed, Value: 5000}, line(1, 3000, 3), 9000},
+		{"buy 2 get 1 short of a set", model.Promotion{Type: model.PromotionBuyXGetY, BuyQuantity: 2, GetQuantity: 1}, line(1, 3000, 2), 0},
+		{"buy 2 get 1 one set", model.Promotion{Type: model.PromotionBuyXGetY, BuyQuantity: 2, GetQuantity: 1}, line(1, 3000, 5), 3000},
+		{"buy 1 get 2 two sets", model.Promotion{Type: model.PromotionBuyXGetY, BuyQuantity: 1, GetQuantity: 2}, line(1, 3000, 6), 12000},
+		{"buy x get y without quantities", model.Promotion{Type: model.PromotionBuyXGetY}, line(1, 3000, 6), 0},
+		{"basket is not a line promotion", model.Promotion{Type: model.PromotionBasket, Value: 500}, line(1, 3000, 3), 0},
+		{"unknown type", model.Promotion{Type: "bogus", Value: 500}, line(1, 3000, 3), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lineDiscount(&tt.promotion, &tt.detail); got != tt.want {
+				t.Errorf("want %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestShareDiscount(t *testing.T) {
+	tests := []struct {
+		name string
+		// nets are what lines cost before sharing
+		nets   []int64
+		lines  []int
+		amount int64
+		want   []int64
+	}{
+		{"proportional", []int64{6000, 3000, 1000}, []int{0, 1, 2}, 1000, []int64{600, 300, 100}},
+		{"remainder in line order", []int64{10000, 10000, 10000}, []int{0, 1, 2}, 1000, []int64{334, 333, 333}},
+		{"remainder of two", []int64{1000, 1000, 1000}, []int{0, 1, 2}, 2, []int64{1, 1, 0}},
+		{"whole cost", []int64{700, 300}, []int{0, 1}, 1000, []int64{700, 300}},
+		{"lines outside the promotion untouched", []int64{5000, 9999, 5000}, []int{0, 2}, 101, []int64{51, 0, 50}},
+		{"single line", []int64{5000}, []int{0}, 1234, []int64{1234}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details := make([]model.TransactionDetail, len(tt.nets))
+			for i, net := range tt.nets {
+				// half the line was already taken off by a line promotion
+				details[i] = line(i+1, net*2, 1)
+				details[i].DiscountAmount = model.NewMoney(net)
+			}
+
+			shareDiscount(details, tt.lines, tt.amount)
+
+			var shared int64
+			got := make([]int64, len(details))
+			for i, detail := range details {
+				got[i] = detail.DiscountAmount.Amount - tt.nets[i]
+				shared += got[i]
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want shares %v, got %v", tt.want, got)
+			}
+			if shared != tt.amount {
+				t.Errorf("shares add up to %d, want %d", shared, tt.amount)
+			}
+		})
+	}
+}
@@ -1,32 +1,43 @@
 package repository
 
 import (
+	"category-crud/apperror"
 	"category-crud/model"
 	"category-crud/model/dto"
+	"context"
 	"database/sql"
-	"errors"
+	"time"
 
 	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
 )
 
-type ProductRepository struct {
+var ErrProductNotFound = apperror.NotFound("produk tidak ditemukan")
+
+type productRepository struct {
 	db      *sql.DB
 	builder *goqu.Database
+	timeout time.Duration
 }
 
-func NewProductRepository(db *sql.DB, builder *goqu.Database) *ProductRepository {
-	return &ProductRepository{
+func NewProductRepository(db *sql.DB, builder *goqu.Database, timeout time.Duration) ProductRepository {
+	return &productRepository{
 		db:      db,
 		builder: builder,
+		timeout: timeout,
 	}
 }
 
-func (repo *ProductRepository) GetAll(filter *dto.ProductFilterRequest) ([]model.Product, error) {
-	// Get all products
-	var products []model.Product
+func (repo *productRepository) GetAll(ctx context.Context, filter *dto.ProductFilterRequest) (*dto.ProductPage, error) {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
 	queryRaw := repo.builder.
-		From("products").
-		Select("id", "name", "price", "stock")
+		From("products")
+	if !filter.IncludeDeleted {
+		queryRaw = queryRaw.Where(goqu.I("deleted_at").IsNull())
+	}
+
 	if filter.Name != "" {
 		queryRaw = queryRaw.Where(goqu.I("name").ILike("%" + filter.Name + "%"))
 	}
@@ -35,20 +46,115 @@ func (repo *ProductRepository) GetAll(filter *dto.ProductFilterRequest) ([]model
 		queryRaw = queryRaw.Where(goqu.I("id").In(filter.IDs))
 	}
 
-	err := queryRaw.
-		ScanStructs(&products)
+	if len(filter.CategoryIDs) > 0 {
+		queryRaw = queryRaw.Where(goqu.I("id").In(
+			repo.builder.From("product_categories").
+				Select("product_id").
+				Where(goqu.I("category_id").In(filter.CategoryIDs)),
+		))
+	}
+
+	if filter.MinPrice != nil {
+		queryRaw = queryRaw.Where(goqu.I("price").Gte(*filter.MinPrice))
+	}
+
+	if filter.MaxPrice != nil {
+		queryRaw = queryRaw.Where(goqu.I("price").Lte(*filter.MaxPrice))
+	}
+
+	if filter.InStock != nil {
+		if *filter.InStock {
+			queryRaw = queryRaw.Where(goqu.I("stock").Gt(0))
+		} else {
+			queryRaw = queryRaw.Where(goqu.I("stock").Lte(0))
+		}
+	}
+
+	total, err := queryRaw.CountContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	productIDs := make([]int, len(products))
+	sort := ProductSort(filter.Sort)
+	orders := make([]exp.OrderedExpression, 0, len(sort))
+	for _, field := range sort {
+		if field.Desc {
+			orders = append(orders, goqu.I(field.Field).Desc())
+		} else {
+			orders = append(orders, goqu.I(field.Field).Asc())
+		}
+	}
 
-	for _, product := range products {
-		productIDs = append(productIDs, product.ID)
+	pageQuery := queryRaw.
+		Select("id", "name", "price", "stock", "tax_class_id", "deleted_at").
+		Order(orders...).
+		Limit(uint(filter.Limit + 1))
+
+	if filter.Cursor != "" {
+		cursor, err := DecodeProductCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		pageQuery = pageQuery.Where(keysetAfter(sort, cursor))
+	} else if filter.Page > 1 {
+		pageQuery = pageQuery.Offset(uint((filter.Page - 1) * filter.Limit))
 	}
 
-	if len(productIDs) == 0 {
-		return products, nil
+	// Get one page of products
+	var products []model.Product
+	err = pageQuery.ScanStructsContext(ctx, &products)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &dto.ProductPage{
+		Data:  products,
+		Total: int(total),
+		Limit: filter.Limit,
+	}
+	if filter.Cursor == "" {
+		page.Page = max(filter.Page, 1)
+	}
+	if len(products) > filter.Limit {
+		page.Data = products[:filter.Limit]
+		page.NextCursor = EncodeProductCursor(page.Data[filter.Limit-1])
+	}
+
+	if err := repo.attachCategories(ctx, page.Data); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// keysetAfter matches rows that sort strictly after cursor, e.g. for
+// (price DESC, id ASC): price < c.price OR (price = c.price AND id > c.id).
+func keysetAfter(sort []dto.SortField, cursor *ProductCursor) exp.Expression {
+	branches := make([]exp.Expression, 0, len(sort))
+	for i, field := range sort {
+		conditions := make([]exp.Expression, 0, i+1)
+		for _, previous := range sort[:i] {
+			conditions = append(conditions, goqu.I(previous.Field).Eq(cursor.Value(previous.Field)))
+		}
+		if field.Desc {
+			conditions = append(conditions, goqu.I(field.Field).Lt(cursor.Value(field.Field)))
+		} else {
+			conditions = append(conditions, goqu.I(field.Field).Gt(cursor.Value(field.Field)))
+		}
+		branches = append(branches, goqu.And(conditions...))
+	}
+	return goqu.Or(branches...)
+}
+
+// attachCategories loads the live categories of the given products only.
+func (repo *productRepository) attachCategories(ctx context.Context, products []model.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	productIDs := make([]int, 0, len(products))
+	for _, product := range products {
+		productIDs = append(productIDs, product.ID)
 	}
 
 	// Get all categories for these products
@@ -62,16 +168,23 @@ func (repo *ProductRepository) GetAll(filter *dto.ProductFilterRequest) ([]model
 			goqu.T("categories").As("c"),
 			goqu.On(goqu.Ex{"pc.category_id": goqu.I("c.id")}),
 		).
-		Where(goqu.I("pc.product_id").In(productIDs)). // productIds is your slice
+		Where(
+			goqu.I("pc.product_id").In(productIDs),
+			goqu.I("c.deleted_at").IsNull(),
+		).
 		Order(
 			goqu.I("pc.product_id").Asc(),
 			goqu.I("c.name").Asc(),
 		).ToSQL()
+	if err != nil {
+		return err
+	}
 
-	catRows, err := repo.db.Query(categoryQuery)
+	catRows, err := repo.db.QueryContext(ctx, categoryQuery)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer catRows.Close()
 
 	// Map categories to products
 	productMap := make(map[int]*model.Product)
@@ -85,7 +198,7 @@ func (repo *ProductRepository) GetAll(filter *dto.ProductFilterRequest) ([]model
 
 		err := catRows.Scan(&productID, &categoryID, &categoryName)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		if product, exists := productMap[productID]; exists {
@@ -96,7 +209,7 @@ func (repo *ProductRepository) GetAll(filter *dto.ProductFilterRequest) ([]model
 		}
 	}
 
-	return products, nil
+	return catRows.Err()
 }
 
 func GenerateInsertProductCategoriesQuery(builder *goqu.Database, product *dto.ProductRequest) string {
@@ -119,35 +232,36 @@ func GenerateInsertProductCategoriesQuery(builder *goqu.Database, product *dto.P
 
 }
 
-func (repo *ProductRepository) Create(product *dto.ProductRequest) error {
-	tx, err := repo.db.Begin()
+func (repo *productRepository) Create(ctx context.Context, product *dto.ProductRequest) error {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	tx, err := repo.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 	query, _, err := repo.builder.Insert("products").Rows(
 		goqu.Record{
-			"name":  product.Name,
-			"price": product.Price,
-			"stock": product.Stock,
+			"name":         product.Name,
+			"price":        product.Price,
+			"stock":        product.Stock,
+			"tax_class_id": product.TaxClassID,
 		},
 	).Returning("id").ToSQL()
-	err = tx.QueryRow(query).Scan(&product.ID)
+	if err != nil {
+		return err
+	}
+	err = tx.QueryRowContext(ctx, query).Scan(&product.ID)
+	if err != nil {
+		return translateError(err)
+	}
 	// Batch insert categories
 	if len(product.Categories) > 0 {
-		records := make([]goqu.Record, 0, len(product.Categories))
-
-		for _, categoryID := range product.Categories {
-			records = append(records, goqu.Record{
-				"product_id":  product.ID,
-				"category_id": categoryID,
-			})
-		}
-
 		queryCategoryInsert := GenerateInsertProductCategoriesQuery(repo.builder, product)
-		_, err = tx.Exec(queryCategoryInsert)
+		_, err = tx.ExecContext(ctx, queryCategoryInsert)
 		if err != nil {
-			return err
+			return translateError(err)
 		}
 	}
 
@@ -155,21 +269,28 @@ func (repo *ProductRepository) Create(product *dto.ProductRequest) error {
 }
 
 // GetByID - ambil produk by ID
-func (repo *ProductRepository) GetByID(id int) (*model.Product, error) {
-	var product model.Product
-	result, err := repo.builder.
-		From("products").
-		Select("id", "name", "price", "stock").
-		Where(goqu.Ex{"id": id}).
-		ScanStruct(&product)
+func (repo *productRepository) GetByID(ctx context.Context, id int, includeDeleted bool) (*model.Product, error) {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
 
-	if !result {
-		return nil, errors.New("produk tidak ditemukan")
+	query := repo.builder.
+		From("products").
+		Select("id", "name", "price", "stock", "tax_class_id", "deleted_at").
+		Where(goqu.Ex{"id": id})
+	if !includeDeleted {
+		query = query.Where(goqu.I("deleted_at").IsNull())
 	}
 
+	var product model.Product
+	result, err := query.ScanStructContext(ctx, &product)
+
 	if err != nil {
 		return nil, err
 	}
+
+	if !result {
+		return nil, ErrProductNotFound
+	}
 	var categories []model.Category
 	err = repo.builder.From(goqu.T("categories").As("c")).
 		Select(
@@ -180,9 +301,12 @@ func (repo *ProductRepository) GetByID(id int) (*model.Product, error) {
 			goqu.T("product_categories").As("pc"),
 			goqu.On(goqu.Ex{"c.id": goqu.I("pc.category_id")}),
 		).
-		Where(goqu.Ex{"pc.product_id": id}).
+		Where(goqu.Ex{
+			"pc.product_id": id,
+			"c.deleted_at":  nil,
+		}).
 		Order(goqu.I("c.name").Asc()).
-		ScanStructs(&categories)
+		ScanStructsContext(ctx, &categories)
 
 	if err != nil {
 		return nil, err
@@ -193,23 +317,30 @@ func (repo *ProductRepository) GetByID(id int) (*model.Product, error) {
 	return &product, nil
 }
 
-func (repo *ProductRepository) Update(product *dto.ProductRequest) error {
-	tx, err := repo.db.Begin()
+func (repo *productRepository) Update(ctx context.Context, product *dto.ProductRequest) error {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	tx, err := repo.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 	query, _, err := repo.builder.Update("products").Set(
 		goqu.Record{
-			"name":  product.Name,
-			"price": product.Price,
-			"stock": product.Stock,
+			"name":         product.Name,
+			"price":        product.Price,
+			"stock":        product.Stock,
+			"tax_class_id": product.TaxClassID,
 		}).
-		Where(goqu.Ex{"id": product.ID}).ToSQL()
+		Where(goqu.Ex{
+			"id":         product.ID,
+			"deleted_at": nil,
+		}).ToSQL()
 
-	result, err := tx.Exec(query)
+	result, err := tx.ExecContext(ctx, query)
 	if err != nil {
-		return err
+		return translateError(err)
 	}
 
 	rows, err := result.RowsAffected()
@@ -218,7 +349,7 @@ func (repo *ProductRepository) Update(product *dto.ProductRequest) error {
 	}
 
 	if rows == 0 {
-		return errors.New("produk tidak ditemukan")
+		return ErrProductNotFound
 	}
 
 	// Delete existing category relationships
@@ -226,7 +357,7 @@ func (repo *ProductRepository) Update(product *dto.ProductRequest) error {
 		Delete("product_categories").
 		Where(goqu.Ex{"product_id": product.ID}).ToSQL()
 
-	_, err = tx.Exec(deleteQuery)
+	_, err = tx.ExecContext(ctx, deleteQuery)
 	if err != nil {
 		return err
 	}
@@ -235,44 +366,46 @@ func (repo *ProductRepository) Update(product *dto.ProductRequest) error {
 	if len(product.Categories) > 0 {
 		insertQuery := GenerateInsertProductCategoriesQuery(repo.builder, product)
 
-		_, err = tx.Exec(insertQuery)
+		_, err = tx.ExecContext(ctx, insertQuery)
 		if err != nil {
-			return err
+			return translateError(err)
 		}
 	}
 
 	return tx.Commit()
 }
 
-func (repo *ProductRepository) BatchUpdateStock(products []model.Product) error {
-	updateProductRecords := make([]goqu.Record, 0, len(products))
-	for _, product := range products {
-		updateProductRecords = append(updateProductRecords, goqu.Record{
-			"id":    product.ID,
-			"stock": product.Stock,
-		})
+// Delete soft-deletes the product. Transactions keep referencing the
+// row, so their history and reports still show it.
+func (repo *productRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	result, err := repo.builder.Update("products").
+		Set(goqu.Record{"deleted_at": goqu.L("NOW()")}).
+		Where(goqu.Ex{
+			"id":         id,
+			"deleted_at": nil,
+		}).Executor().ExecContext(ctx)
+	if err != nil {
+		return err
 	}
-	_, err := repo.builder.Update("products").Set(
-		updateProductRecords,
-	).Executor().Exec()
 
-	return err
+	return expectRow(result, ErrProductNotFound)
 }
 
-func (repo *ProductRepository) Delete(id int) error {
-	query, _, err := repo.builder.Delete("products").Where(goqu.Ex{"id": id}).ToSQL()
-	result, err := repo.db.Exec(query)
-	if err != nil {
-		return err
-	}
-	rows, err := result.RowsAffected()
+// Restore undeletes the product. Restoring a live product is a no-op.
+func (repo *productRepository) Restore(ctx context.Context, id int) error {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	result, err := repo.builder.Update("products").
+		Set(goqu.Record{"deleted_at": nil}).
+		Where(goqu.Ex{"id": id}).
+		Executor().ExecContext(ctx)
 	if err != nil {
 		return err
 	}
 
-	if rows == 0 {
-		return errors.New("produk tidak ditemukan")
-	}
-
-	return err
+	return expectRow(result, ErrProductNotFound)
 }
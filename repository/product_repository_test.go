@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"category-crud/internal/testdb"
+	"category-crud/model"
+	"category-crud/model/dto"
+	"context"
+	"testing"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+func TestKeysetAfter(t *testing.T) {
+	cursor := &ProductCursor{ID: 3, Name: "Kopi", Price: 5000, Stock: 2}
+
+	tests := []struct {
+		name string
+		sort []dto.SortField
+		want string
+	}{
+		{
+			name: "id",
+			sort: ProductSort(nil),
+			want: `SELECT * FROM "products" WHERE ("id" > 3)`,
+		},
+		{
+			name: "price desc with id tie-breaker",
+			sort: ProductSort([]dto.SortField{{Field: "price", Desc: true}}),
+			want: `SELECT * FROM "products" WHERE (("price" < 5000) OR (("price" = 5000) AND ("id" > 3)))`,
+		},
+		{
+			name: "three keys",
+			sort: ProductSort([]dto.SortField{{Field: "stock"}, {Field: "name", Desc: true}}),
+			want: `SELECT * FROM "products" WHERE (("stock" > 2) OR (("stock" = 2) AND ("name" < 'Kopi')) OR (("stock" = 2) AND ("name" = 'Kopi') AND ("id" > 3)))`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _, err := goqu.Dialect("postgres").From("products").Where(keysetAfter(tt.sort, cursor)).ToSQL()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if query != tt.want {
+				t.Errorf("want\n%s\ngot\n%s", tt.want, query)
+			}
+		})
+	}
+}
+
+// TestProductCursorPagesWithTies walks every page of products that share
+// sort keys; the id tie-breaker must keep rows from being skipped or
+// repeated across page boundaries.
+func TestProductCursorPagesWithTies(t *testing.T) {
+	conn, builder := testdb.Open(t)
+	ctx := context.Background()
+	repo := NewProductRepository(conn, builder, testQueryTimeout)
+
+	prices := []int64{5000, 3000, 5000, 5000, 3000, 8000, 5000}
+	for _, price := range prices {
+		if err := repo.Create(ctx, &dto.ProductRequest{Name: "Kopi", Price: model.NewMoney(price), Stock: 1}); err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+	}
+
+	for _, sort := range [][]dto.SortField{
+		{{Field: "price", Desc: true}},
+		{{Field: "name"}, {Field: "stock", Desc: true}},
+	} {
+		filter := &dto.ProductFilterRequest{Sort: sort, Limit: 2}
+		seen := map[int]bool{}
+		var lastPrice int64 = 1 << 62
+		for pages := 0; ; pages++ {
+			if pages > len(prices) {
+				t.Fatalf("sort %+v: pagination does not end", sort)
+			}
+			page, err := repo.GetAll(ctx, filter)
+			if err != nil {
+				t.Fatalf("GetAll: %v", err)
+			}
+			for _, product := range page.Data {
+				if seen[product.ID] {
+					t.Errorf("sort %+v: product %d repeated", sort, product.ID)
+				}
+				seen[product.ID] = true
+				if sort[0].Field == "price" {
+					if product.Price.Amount > lastPrice {
+						t.Errorf("sort %+v: product %d out of order", sort, product.ID)
+					}
+					lastPrice = product.Price.Amount
+				}
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			filter.Cursor = page.NextCursor
+		}
+		if len(seen) != len(prices) {
+			t.Errorf("sort %+v: want %d products, got %d", sort, len(prices), len(seen))
+		}
+	}
+}
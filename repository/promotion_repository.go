@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"category-crud/apperror"
+	"category-crud/model"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+var ErrPromotionNotFound = apperror.NotFound("promosi tidak ditemukan")
+
+var promotionColumns = []interface{}{
+	"id", "name", "type", "value", "product_id", "category_id", "buy_quantity", "get_quantity",
+	"min_subtotal", "starts_at", "ends_at", "active", "created_at", "updated_at", "deleted_at",
+}
+
+type promotionRepository struct {
+	db      *sql.DB
+	builder *goqu.Database
+	timeout time.Duration
+}
+
+func NewPromotionRepository(db *sql.DB, builder *goqu.Database, timeout time.Duration) PromotionRepository {
+	return &promotionRepository{
+		db:      db,
+		builder: builder,
+		timeout: timeout,
+	}
+}
+
+func (repo *promotionRepository) GetAll(ctx context.Context) ([]model.Promotion, error) {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	promotions := []model.Promotion{}
+	err := repo.builder.From("promotions").
+		Select(promotionColumns...).
+		Where(goqu.I("deleted_at").IsNull()).
+		Order(goqu.I("id").Asc()).
+		ScanStructsContext(ctx, &promotions)
+	if err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+func (repo *promotionRepository) Create(ctx context.Context, promotion *model.Promotion) error {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	_, err := repo.builder.Insert("promotions").
+		Rows(promotionRecord(promotion)).
+		Returning(promotionColumns...).
+		Executor().ScanStructContext(ctx, promotion)
+	return translateError(err)
+}
+
+func (repo *promotionRepository) GetByID(ctx context.Context, id int) (*model.Promotion, error) {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	var promotion model.Promotion
+	found, err := repo.builder.From("promotions").
+		Select(promotionColumns...).
+		Where(goqu.Ex{
+			"id":         id,
+			"deleted_at": nil,
+		}).
+		ScanStructContext(ctx, &promotion)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrPromotionNotFound
+	}
+
+	return &promotion, nil
+}
+
+func (repo *promotionRepository) Update(ctx context.Context, promotion *model.Promotion) error {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	record := promotionRecord(promotion)
+	record["updated_at"] = time.Now()
+
+	found, err := repo.builder.Update("promotions").
+		Set(record).
+		Where(goqu.Ex{
+			"id":         promotion.ID,
+			"deleted_at": nil,
+		}).
+		Returning(promotionColumns...).
+		Executor().ScanStructContext(ctx, promotion)
+	if err != nil {
+		return translateError(err)
+	}
+	if !found {
+		return ErrPromotionNotFound
+	}
+
+	return nil
+}
+
+// Delete soft-deletes the promotion; past sales keep pointing at it.
+func (repo *promotionRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	result, err := repo.builder.Update("promotions").
+		Set(goqu.Record{"deleted_at": goqu.L("NOW()")}).
+		Where(goqu.Ex{
+			"id":         id,
+			"deleted_at": nil,
+		}).Executor().ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	return expectRow(result, ErrPromotionNotFound)
+}
+
+func promotionRecord(promotion *model.Promotion) goqu.Record {
+	return goqu.Record{
+		"name":         promotion.Name,
+		"type":         promotion.Type,
+		"value":        promotion.Value,
+		"product_id":   promotion.ProductID,
+		"category_id":  promotion.CategoryID,
+		"buy_quantity": promotion.BuyQuantity,
+		"get_quantity": promotion.GetQuantity,
+		"min_subtotal": promotion.MinSubtotal,
+		"starts_at":    promotion.StartsAt,
+		"ends_at":      promotion.EndsAt,
+		"active":       promotion.Active,
+	}
+}
+
+// runningPromotions reads the promotions that apply to a sale made now,
+// NOW() being the start of the checkout transaction like created_at.
+func runningPromotions(ctx context.Context, tx selector) ([]model.Promotion, error) {
+	var promotions []model.Promotion
+	err := tx.From("promotions").
+		Select(promotionColumns...).
+		Where(
+			goqu.I("deleted_at").IsNull(),
+			goqu.I("active").IsTrue(),
+			goqu.Or(goqu.I("starts_at").IsNull(), goqu.I("starts_at").Lte(goqu.L("NOW()"))),
+			goqu.Or(goqu.I("ends_at").IsNull(), goqu.I("ends_at").Gt(goqu.L("NOW()"))),
+		).
+		Order(goqu.I("id").Asc()).
+		ScanStructsContext(ctx, &promotions)
+	return promotions, err
+}
+
+// liveCategories maps each product to the ids of its live categories.
+func liveCategories(ctx context.Context, tx selector, productIDs []int) (map[int][]int, error) {
+	var links []struct {
+		ProductID  int `db:"product_id"`
+		CategoryID int `db:"category_id"`
+	}
+	err := tx.From(goqu.T("product_categories").As("pc")).
+		InnerJoin(
+			goqu.T("categories").As("c"),
+			goqu.On(goqu.I("pc.category_id").Eq(goqu.I("c.id"))),
+		).
+		Select(
+			goqu.I("pc.product_id").As("product_id"),
+			goqu.I("pc.category_id").As("category_id"),
+		).
+		Where(
+			goqu.I("pc.product_id").In(productIDs),
+			goqu.I("c.deleted_at").IsNull(),
+		).
+		ScanStructsContext(ctx, &links)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make(map[int][]int, len(productIDs))
+	for _, link := range links {
+		categories[link.ProductID] = append(categories[link.ProductID], link.CategoryID)
+	}
+	return categories, nil
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"category-crud/apperror"
+	"category-crud/model"
+)
+
+var (
+	ErrTransactionNotFound = apperror.NotFound("transaksi tidak ditemukan")
+	ErrTransactionVoided   = apperror.Conflict("transaction has been voided", nil)
+	ErrNothingToRefund     = apperror.Conflict("every item of this transaction has already been refunded", nil)
+)
+
+const (
+	RefundReasonDetailNotFound    = "detail_not_found"
+	RefundReasonInvalidQuantity   = "invalid_quantity"
+	RefundReasonExceedsRefundable = "exceeds_refundable"
+)
+
+type RefundItemError struct {
+	TransactionDetailID int    `json:"transaction_detail_id"`
+	Reason              string `json:"reason"`
+	Requested           int    `json:"requested"`
+	Refundable          int    `json:"refundable"`
+}
+
+// RefundedLine is what has already been refunded of one transaction detail.
+type RefundedLine struct {
+	TransactionDetailID int         `db:"transaction_detail_id"`
+	Quantity            int         `db:"quantity"`
+	Amount              model.Money `db:"amount"`
+	TaxAmount           model.Money `db:"tax_amount"`
+}
+
+// PlanRefund turns a refund request into refund lines. Amounts and their
+// tax are pro-rated from what was charged for the line; the last units of
+// a line take the remainder so a fully refunded line always returns its
+// exact total and tax.
+// With no items every remaining quantity is refunded.
+func PlanRefund(details []model.TransactionDetail, refunded map[int]RefundedLine, items []model.RefundItem) ([]model.RefundDetail, error) {
+	detailMap := make(map[int]model.TransactionDetail, len(details))
+	for _, detail := range details {
+		detailMap[detail.ID] = detail
+	}
+
+	if len(items) == 0 {
+		for _, detail := range details {
+			if remaining := detail.Quantity - refunded[detail.ID].Quantity; remaining > 0 {
+				items = append(items, model.RefundItem{
+					TransactionDetailID: detail.ID,
+					Quantity:            remaining,
+				})
+			}
+		}
+		if len(items) == 0 {
+			return nil, ErrNothingToRefund
+		}
+	}
+
+	requested := make(map[int]int, len(items))
+	order := make([]int, 0, len(items))
+	var failures []RefundItemError
+	for _, item := range items {
+		if _, ok := detailMap[item.TransactionDetailID]; !ok {
+			failures = append(failures, RefundItemError{
+				TransactionDetailID: item.TransactionDetailID,
+				Reason:              RefundReasonDetailNotFound,
+				Requested:           item.Quantity,
+			})
+			continue
+		}
+		if item.Quantity <= 0 {
+			failures = append(failures, RefundItemError{
+				TransactionDetailID: item.TransactionDetailID,
+				Reason:              RefundReasonInvalidQuantity,
+				Requested:           item.Quantity,
+			})
+			continue
+		}
+		if _, seen := requested[item.TransactionDetailID]; !seen {
+			order = append(order, item.TransactionDetailID)
+		}
+		requested[item.TransactionDetailID] += item.Quantity
+	}
+
+	lines := make([]model.RefundDetail, 0, len(order))
+	for _, detailID := range order {
+		detail := detailMap[detailID]
+		already := refunded[detailID]
+		remaining := detail.Quantity - already.Quantity
+		quantity := requested[detailID]
+
+		if quantity > remaining {
+			failures = append(failures, RefundItemError{
+				TransactionDetailID: detailID,
+				Reason:              RefundReasonExceedsRefundable,
+				Requested:           quantity,
+				Refundable:          remaining,
+			})
+			continue
+		}
+
+		amount := detail.TotalAmount.Fraction(int64(quantity), int64(detail.Quantity), model.RoundDown)
+		tax := detail.TaxAmount.Fraction(int64(quantity), int64(detail.Quantity), model.RoundDown)
+		if quantity == remaining {
+			var err error
+			if amount, err = detail.TotalAmount.Sub(already.Amount); err != nil {
+				return nil, err
+			}
+			if tax, err = detail.TaxAmount.Sub(already.TaxAmount); err != nil {
+				return nil, err
+			}
+		}
+
+		lines = append(lines, model.RefundDetail{
+			TransactionDetailID: detailID,
+			ProductID:           detail.ProductID,
+			Quantity:            quantity,
+			Amount:              amount,
+			TaxAmount:           tax,
+		})
+	}
+
+	if len(failures) > 0 {
+		return nil, apperror.Unprocessable("one or more refund lines are invalid", failures)
+	}
+
+	return lines, nil
+}
+
+// RefundTotals adds up the amount and tax of refund lines.
+func RefundTotals(lines []model.RefundDetail) (model.Money, model.Money, error) {
+	totalAmount, taxAmount := model.NewMoney(0), model.NewMoney(0)
+	for _, line := range lines {
+		var err error
+		if totalAmount, err = totalAmount.Add(line.Amount); err != nil {
+			return model.Money{}, model.Money{}, err
+		}
+		if taxAmount, err = taxAmount.Add(line.TaxAmount); err != nil {
+			return model.Money{}, model.Money{}, err
+		}
+	}
+	return totalAmount, taxAmount, nil
+}
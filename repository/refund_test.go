@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"category-crud/apperror"
+	"category-crud/model"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// sold is a transaction detail of quantity units that charged total, tax
+// of it.
+func sold(id, productID, quantity int, total, tax int64) model.TransactionDetail {
+	return model.TransactionDetail{
+		ID:          id,
+		ProductID:   productID,
+		Quantity:    quantity,
+		TotalAmount: model.NewMoney(total),
+		TaxAmount:   model.NewMoney(tax),
+	}
+}
+
+func refundLine(detailID, productID, quantity int, amount, tax int64) model.RefundDetail {
+	return model.RefundDetail{
+		TransactionDetailID: detailID,
+		ProductID:           productID,
+		Quantity:            quantity,
+		Amount:              model.NewMoney(amount),
+		TaxAmount:           model.NewMoney(tax),
+	}
+}
+
+func TestPlanRefund(t *testing.T) {
+	// three units for 10000 with 990 tax do not split evenly
+	details := []model.TransactionDetail{
+		sold(1, 7, 3, 10000, 990),
+		sold(2, 8, 1, 5000, 495),
+	}
+
+	tests := []struct {
+		name     string
+		refunded map[int]RefundedLine
+		items    []model.RefundItem
+		want     []model.RefundDetail
+	}{
+		{
+			name: "everything",
+			want: []model.RefundDetail{refundLine(1, 7, 3, 10000, 990), refundLine(2, 8, 1, 5000, 495)},
+		},
+		{
+			name:  "one unit rounds down",
+			items: []model.RefundItem{{TransactionDetailID: 1, Quantity: 1}},
+			want:  []model.RefundDetail{refundLine(1, 7, 1, 3333, 330)},
+		},
+		{
+			name:     "second unit rounds down",
+			refunded: map[int]RefundedLine{1: {TransactionDetailID: 1, Quantity: 1, Amount: model.NewMoney(3333), TaxAmount: model.NewMoney(330)}},
+			items:    []model.RefundItem{{TransactionDetailID: 1, Quantity: 1}},
+			want:     []model.RefundDetail{refundLine(1, 7, 1, 3333, 330)},
+		},
+		{
+			name:     "last unit takes the remainder",
+			refunded: map[int]RefundedLine{1: {TransactionDetailID: 1, Quantity: 2, Amount: model.NewMoney(6666), TaxAmount: model.NewMoney(660)}},
+			items:    []model.RefundItem{{TransactionDetailID: 1, Quantity: 1}},
+			want:     []model.RefundDetail{refundLine(1, 7, 1, 3334, 330)},
+		},
+		{
+			name:     "everything that is left",
+			refunded: map[int]RefundedLine{1: {TransactionDetailID: 1, Quantity: 1, Amount: model.NewMoney(3333), TaxAmount: model.NewMoney(330)}},
+			want:     []model.RefundDetail{refundLine(1, 7, 2, 6667, 660), refundLine(2, 8, 1, 5000, 495)},
+		},
+		{
+			name:  "lines of one detail add up",
+			items: []model.RefundItem{{TransactionDetailID: 1, Quantity: 1}, {TransactionDetailID: 2, Quantity: 1}, {TransactionDetailID: 1, Quantity: 1}},
+			want:  []model.RefundDetail{refundLine(1, 7, 2, 6666, 660), refundLine(2, 8, 1, 5000, 495)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, err := PlanRefund(details, tt.refunded, tt.items)
+			if err != nil {
+				t.Fatalf("PlanRefund: %v", err)
+			}
+			if !reflect.DeepEqual(lines, tt.want) {
+				t.Errorf("want %+v, got %+v", tt.want, lines)
+			}
+		})
+	}
+}
+
+func TestPlanRefundRejected(t *testing.T) {
+	details := []model.TransactionDetail{
+		sold(1, 7, 3, 10000, 990),
+		sold(2, 8, 1, 5000, 495),
+	}
+	twoRefunded := map[int]RefundedLine{1: {TransactionDetailID: 1, Quantity: 2, Amount: model.NewMoney(6666), TaxAmount: model.NewMoney(660)}}
+
+	tests := []struct {
+		name     string
+		refunded map[int]RefundedLine
+		items    []model.RefundItem
+		want     []RefundItemError
+	}{
+		{
+			name:  "unknown detail",
+			items: []model.RefundItem{{TransactionDetailID: 9, Quantity: 1}},
+			want:  []RefundItemError{{TransactionDetailID: 9, Reason: RefundReasonDetailNotFound, Requested: 1}},
+		},
+		{
+			name:  "no quantity",
+			items: []model.RefundItem{{TransactionDetailID: 1, Quantity: 0}},
+			want:  []RefundItemError{{TransactionDetailID: 1, Reason: RefundReasonInvalidQuantity}},
+		},
+		{
+			name:  "more than was sold",
+			items: []model.RefundItem{{TransactionDetailID: 2, Quantity: 2}},
+			want:  []RefundItemError{{TransactionDetailID: 2, Reason: RefundReasonExceedsRefundable, Requested: 2, Refundable: 1}},
+		},
+		{
+			name:     "more than is left",
+			refunded: twoRefunded,
+			items:    []model.RefundItem{{TransactionDetailID: 1, Quantity: 2}},
+			want:     []RefundItemError{{TransactionDetailID: 1, Reason: RefundReasonExceedsRefundable, Requested: 2, Refundable: 1}},
+		},
+		{
+			name:  "every failing line is reported",
+			items: []model.RefundItem{{TransactionDetailID: 9, Quantity: 1}, {TransactionDetailID: 1, Quantity: 4}, {TransactionDetailID: 2, Quantity: 1}},
+			want: []RefundItemError{
+				{TransactionDetailID: 9, Reason: RefundReasonDetailNotFound, Requested: 1},
+				{TransactionDetailID: 1, Reason: RefundReasonExceedsRefundable, Requested: 4, Refundable: 3},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, err := PlanRefund(details, tt.refunded, tt.items)
+			var appErr *apperror.Error
+			if !errors.As(err, &appErr) || appErr.Code != apperror.CodeUnprocessable {
+				t.Fatalf("want an unprocessable error, got %v, %v", lines, err)
+			}
+			if !reflect.DeepEqual(appErr.Details, tt.want) {
+				t.Errorf("want details %+v, got %+v", tt.want, appErr.Details)
+			}
+		})
+	}
+}
+
+func TestPlanRefundNothingLeft(t *testing.T) {
+	details := []model.TransactionDetail{sold(1, 7, 2, 10000, 990)}
+	refunded := map[int]RefundedLine{1: {TransactionDetailID: 1, Quantity: 2, Amount: model.NewMoney(10000), TaxAmount: model.NewMoney(990)}}
+
+	if _, err := PlanRefund(details, refunded, nil); !errors.Is(err, ErrNothingToRefund) {
+		t.Errorf("want ErrNothingToRefund, got %v", err)
+	}
+}
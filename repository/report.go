@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"category-crud/model"
+	"math"
+)
+
+// FinishReport derives the totals that follow from the aggregated ones
+// and replaces nil lists with empty ones so an empty range still renders
+// as a zeroed report.
+func FinishReport(report *model.Report) error {
+	var err error
+	report.GrossSales = report.TotalRevenue
+	if report.NetRevenue, err = report.GrossSales.Sub(report.TotalRefunds); err != nil {
+		return err
+	}
+	if report.NetTax, err = report.TotalTax.Sub(report.TaxRefunded); err != nil {
+		return err
+	}
+
+	if report.TotalTransaks > 0 {
+		size := float64(report.ItemsSold) / float64(report.TotalTransaks)
+		report.AverageBasketSize = math.Round(size*100) / 100
+		report.AverageBasketValue = report.TotalRevenue.Fraction(1, int64(report.TotalTransaks), model.RoundDown)
+	}
+
+	if report.TopByQuantity == nil {
+		report.TopByQuantity = []model.ProductSales{}
+	}
+	if report.TopByRevenue == nil {
+		report.TopByRevenue = []model.ProductSales{}
+	}
+	if report.Categories == nil {
+		report.Categories = []model.CategorySales{}
+	}
+	if report.Cashiers == nil {
+		report.Cashiers = []model.CashierSales{}
+	}
+	report.Payments = paymentMethods(report.Payments)
+
+	if len(report.TopByQuantity) > 0 {
+		report.ProductTerlaris = model.ProductTerlaris{
+			Nama:       report.TopByQuantity[0].ProductName,
+			QtyTerjual: report.TopByQuantity[0].Quantity,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"category-crud/model"
+	"category-crud/model/dto"
+	"context"
+)
+
+// Categories and products are soft-deleted: Delete sets deleted_at and
+// Restore clears it. Reads skip deleted rows unless includeDeleted is set,
+// and Update only touches live rows.
+type CategoryRepository interface {
+	GetAll(ctx context.Context, includeDeleted bool) ([]model.Category, error)
+	Create(ctx context.Context, category *model.Category) error
+	GetByID(ctx context.Context, id int, includeDeleted bool) (*model.Category, error)
+	Update(ctx context.Context, category *model.Category) error
+	Delete(ctx context.Context, id int) error
+	Restore(ctx context.Context, id int) error
+}
+
+type ProductRepository interface {
+	GetAll(ctx context.Context, filter *dto.ProductFilterRequest) (*dto.ProductPage, error)
+	Create(ctx context.Context, product *dto.ProductRequest) error
+	GetByID(ctx context.Context, id int, includeDeleted bool) (*model.Product, error)
+	Update(ctx context.Context, product *dto.ProductRequest) error
+	Delete(ctx context.Context, id int) error
+	Restore(ctx context.Context, id int) error
+}
+
+// Promotions are soft-deleted: deleted ones are hidden from every read but
+// stay referenced by the sales they discounted.
+type PromotionRepository interface {
+	GetAll(ctx context.Context) ([]model.Promotion, error)
+	Create(ctx context.Context, promotion *model.Promotion) error
+	GetByID(ctx context.Context, id int) (*model.Promotion, error)
+	Update(ctx context.Context, promotion *model.Promotion) error
+	Delete(ctx context.Context, id int) error
+}
+
+type TaxClassRepository interface {
+	GetAll(ctx context.Context) ([]model.TaxClass, error)
+	Create(ctx context.Context, taxClass *model.TaxClass) error
+	GetByID(ctx context.Context, id int) (*model.TaxClass, error)
+	Update(ctx context.Context, taxClass *model.TaxClass) error
+	// Delete fails with ErrTaxClassInUse while a product or category
+	// still uses the class.
+	Delete(ctx context.Context, id int) error
+}
+
+// Shifts belong to the cashier who opened them, or to no one when opened
+// with an API key. Each cashier has at most one open shift.
+type ShiftRepository interface {
+	// GetAll lists shifts, most recently opened first.
+	GetAll(ctx context.Context, filter *dto.ShiftFilterRequest) (*dto.ShiftPage, error)
+	// Open fails with ErrShiftAlreadyOpen while the cashier still has a
+	// shift open.
+	Open(ctx context.Context, shift *model.Shift) error
+	GetByID(ctx context.Context, id int) (*model.Shift, error)
+	// Close reconciles the drawer with ReconcileShift and closes the
+	// shift, failing with ErrShiftClosed if it already was.
+	Close(ctx context.Context, id int, req *model.CloseShiftRequest) (*model.Shift, error)
+}
+
+type TransactionRepository interface {
+	// CreateTransaction records a checkout and decrements stock atomically,
+	// applying the promotions running at checkout time with
+	// ApplyPromotions, then tax with ApplyTax, and settling the payments
+	// against the total with SettlePayments. The sale is tied to the shift
+	// cashierID has open, if any. When idempotencyKey is set
+	// and cashierID already used it with the same request hash, the
+	// original transaction is returned and replayed is true. Expired keys
+	// are purged as new ones are stored.
+	CreateTransaction(ctx context.Context, req *model.CheckoutRequest, cashierID *int, idempotencyKey *model.IdempotencyKey, tax model.TaxPolicy) (transaction *model.Transaction, replayed bool, err error)
+	// GetAll lists transactions, newest first, with their details.
+	GetAll(ctx context.Context, filter *dto.TransactionFilterRequest) (*dto.TransactionPage, error)
+	// ExportLines calls yield for every detail of the transactions matching
+	// filter, newest transaction first, ignoring pagination. Rows are
+	// streamed, so yield may write to a slow client; an error from yield
+	// stops the export and is returned.
+	ExportLines(ctx context.Context, filter *dto.TransactionFilterRequest, yield func(*model.TransactionLine) error) error
+	// GetByID loads a transaction with its details.
+	GetByID(ctx context.Context, id int) (*model.Transaction, error)
+	// CreateRefund records a refund (or void) of a transaction, made by
+	// cashierID during the shift they have open, if any, and restocks the
+	// refunded products. A void also marks the transaction voided so it
+	// cannot be refunded again.
+	CreateRefund(ctx context.Context, transactionID int, refundType string, req *model.RefundRequest, cashierID *int) (*model.Refund, error)
+	// GetReport aggregates the transactions selected by filter. A range
+	// without sales yields a zeroed report.
+	GetReport(ctx context.Context, filter *model.ReportFilter) (*model.Report, error)
+	// GetTimeseries returns one bucket per interval of the filter's range,
+	// zero-filled where nothing was sold.
+	GetTimeseries(ctx context.Context, filter *model.TimeseriesFilter) (*model.Timeseries, error)
+}
+
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	// Migrations returns nil when the backend has no schema to migrate.
+	Migrations(ctx context.Context) (*model.MigrationState, error)
+}
+
+type UserRepository interface {
+	GetAll(ctx context.Context) ([]model.User, error)
+	Create(ctx context.Context, user *model.User) error
+	GetByID(ctx context.Context, id int) (*model.User, error)
+	GetByUsername(ctx context.Context, username string) (*model.User, error)
+	// Update keeps the stored password when user.PasswordHash is empty.
+	Update(ctx context.Context, user *model.User) error
+	Delete(ctx context.Context, id int) error
+}
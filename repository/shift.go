@@ -0,0 +1,60 @@
+package repository
+
+import "category-crud/model"
+
+// ShiftRefund is a refund of a sale and the shift it was made during, nil
+// when none was open.
+type ShiftRefund struct {
+	TransactionID int         `db:"transaction_id"`
+	ShiftID       *int        `db:"shift_id"`
+	TotalAmount   model.Money `db:"total_amount"`
+}
+
+// ReconcileShift works out what the drawer of shift id should hold and
+// compares it with counted. cashSales is what the shift's sales took in
+// cash. cashPaid is what each sale refunded during the shift (by
+// transaction id) took in cash, and refunds are every refund of those
+// sales, oldest first, whichever shift made them. A refund is paid back in
+// cash, out of the drawer of the shift it was made during, up to what its
+// sale still holds in cash; the rest goes back to the card, QRIS or
+// e-wallet payment.
+func ReconcileShift(id int, openingFloat, cashSales model.Money, cashPaid map[int]model.Money, refunds []ShiftRefund, counted model.Money) (*model.ShiftReconciliation, error) {
+	remaining := make(map[int]model.Money, len(cashPaid))
+	for transactionID, amount := range cashPaid {
+		remaining[transactionID] = amount
+	}
+
+	var err error
+	cashRefunds := model.NewMoney(0)
+	for _, refund := range refunds {
+		cash := refund.TotalAmount.Min(remaining[refund.TransactionID])
+		if cash.Amount <= 0 {
+			continue
+		}
+		// cash is at most what remains, so this cannot overflow
+		remaining[refund.TransactionID], _ = remaining[refund.TransactionID].Sub(cash)
+		if refund.ShiftID != nil && *refund.ShiftID == id {
+			if cashRefunds, err = cashRefunds.Add(cash); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	reconciliation := &model.ShiftReconciliation{
+		OpeningFloat: openingFloat,
+		CashSales:    cashSales,
+		CashRefunds:  cashRefunds,
+		CountedCash:  counted,
+	}
+	expected, err := openingFloat.Add(cashSales)
+	if err != nil {
+		return nil, err
+	}
+	if reconciliation.ExpectedCash, err = expected.Sub(cashRefunds); err != nil {
+		return nil, err
+	}
+	if reconciliation.Variance, err = counted.Sub(reconciliation.ExpectedCash); err != nil {
+		return nil, err
+	}
+	return reconciliation, nil
+}
@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"category-crud/apperror"
+	"category-crud/model"
+	"category-crud/model/dto"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrShiftNotFound = apperror.NotFound("shift tidak ditemukan")
+	// ErrShiftAlreadyOpen keeps sales from being split across drawers.
+	ErrShiftAlreadyOpen = apperror.Conflict("kasir masih memiliki shift yang terbuka", nil)
+	ErrShiftClosed      = apperror.Conflict("shift sudah ditutup", nil)
+)
+
+var shiftColumns = []interface{}{
+	"id",
+	"cashier_id",
+	"opening_float",
+	"opened_at",
+	"closed_at",
+	"note",
+	"cash_sales",
+	"cash_refunds",
+	"expected_cash",
+	"counted_cash",
+	"variance",
+}
+
+// shiftRow is a shifts row. The reconciliation columns are NULL, read as
+// zero, until the shift closes.
+type shiftRow struct {
+	model.Shift
+	CashSales    model.Money `db:"cash_sales"`
+	CashRefunds  model.Money `db:"cash_refunds"`
+	ExpectedCash model.Money `db:"expected_cash"`
+	CountedCash  model.Money `db:"counted_cash"`
+	Variance     model.Money `db:"variance"`
+}
+
+func (row *shiftRow) shift() model.Shift {
+	shift := row.Shift
+	if shift.ClosedAt != nil {
+		shift.Reconciliation = &model.ShiftReconciliation{
+			OpeningFloat: shift.OpeningFloat,
+			CashSales:    row.CashSales,
+			CashRefunds:  row.CashRefunds,
+			ExpectedCash: row.ExpectedCash,
+			CountedCash:  row.CountedCash,
+			Variance:     row.Variance,
+		}
+	}
+	return shift
+}
+
+type shiftRepository struct {
+	db      *sql.DB
+	builder *goqu.Database
+	timeout time.Duration
+}
+
+func NewShiftRepository(db *sql.DB, builder *goqu.Database, timeout time.Duration) ShiftRepository {
+	return &shiftRepository{
+		db:      db,
+		builder: builder,
+		timeout: timeout,
+	}
+}
+
+func (repo *shiftRepository) GetAll(ctx context.Context, filter *dto.ShiftFilterRequest) (*dto.ShiftPage, error) {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	queryRaw := repo.builder.From("shifts")
+	if filter.CashierID != nil {
+		queryRaw = queryRaw.Where(goqu.Ex{"cashier_id": *filter.CashierID})
+	}
+	if filter.Open != nil {
+		if *filter.Open {
+			queryRaw = queryRaw.Where(goqu.I("closed_at").IsNull())
+		} else {
+			queryRaw = queryRaw.Where(goqu.I("closed_at").IsNotNull())
+		}
+	}
+
+	total, err := queryRaw.CountContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []shiftRow
+	err = queryRaw.
+		Select(shiftColumns...).
+		Order(goqu.I("opened_at").Desc(), goqu.I("id").Desc()).
+		Limit(uint(filter.Limit)).
+		Offset(uint((filter.Page-1)*filter.Limit)).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	shifts := make([]model.Shift, 0, len(rows))
+	for i := range rows {
+		shifts = append(shifts, rows[i].shift())
+	}
+
+	return &dto.ShiftPage{
+		Data:  shifts,
+		Total: int(total),
+		Limit: filter.Limit,
+		Page:  filter.Page,
+	}, nil
+}
+
+func (repo *shiftRepository) Open(ctx context.Context, shift *model.Shift) error {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	var row shiftRow
+	_, err := repo.builder.Insert("shifts").Rows(
+		goqu.Record{
+			"cashier_id":    shift.CashierID,
+			"opening_float": shift.OpeningFloat,
+		},
+	).Returning(shiftColumns...).Executor().ScanStructContext(ctx, &row)
+	if err != nil {
+		return translateShiftError(err)
+	}
+
+	*shift = row.shift()
+	return nil
+}
+
+func (repo *shiftRepository) GetByID(ctx context.Context, id int) (*model.Shift, error) {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	var row shiftRow
+	found, err := repo.builder.From("shifts").
+		Select(shiftColumns...).
+		Where(goqu.Ex{"id": id}).
+		ScanStructContext(ctx, &row)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrShiftNotFound
+	}
+
+	shift := row.shift()
+	return &shift, nil
+}
+
+// Close locks the shift, so checkouts still tied to it finish first and
+// later ones find it closed, then stores the reconciliation.
+func (repo *shiftRepository) Close(ctx context.Context, id int, req *model.CloseShiftRequest) (*model.Shift, error) {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	tx, err := repo.builder.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var row shiftRow
+	found, err := tx.From("shifts").
+		Select(shiftColumns...).
+		Where(goqu.Ex{"id": id}).
+		ForUpdate(exp.Wait).
+		ScanStructContext(ctx, &row)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrShiftNotFound
+	}
+	if row.ClosedAt != nil {
+		return nil, ErrShiftClosed
+	}
+
+	var cashSales model.Money
+	_, err = tx.From(goqu.T("transaction_payments").As("p")).
+		InnerJoin(
+			goqu.T("transactions").As("t"),
+			goqu.On(goqu.I("p.transaction_id").Eq(goqu.I("t.id"))),
+		).
+		Select(goqu.COALESCE(goqu.SUM("p.amount"), 0)).
+		Where(
+			goqu.I("t.shift_id").Eq(id),
+			goqu.I("p.method").Eq(model.PaymentCash),
+		).
+		ScanValContext(ctx, &cashSales)
+	if err != nil {
+		return nil, err
+	}
+
+	// a refund's cash depends on what earlier refunds of the same sale
+	// already paid back, whichever shift made them
+	refunded := tx.From("refunds").Select("transaction_id").Where(goqu.Ex{"shift_id": id})
+
+	var payments []struct {
+		TransactionID int         `db:"transaction_id"`
+		Amount        model.Money `db:"amount"`
+	}
+	err = tx.From("transaction_payments").
+		Select(
+			goqu.I("transaction_id"),
+			goqu.SUM("amount").As("amount"),
+		).
+		Where(
+			goqu.Ex{"transaction_id": refunded},
+			goqu.I("method").Eq(model.PaymentCash),
+		).
+		GroupBy("transaction_id").
+		ScanStructsContext(ctx, &payments)
+	if err != nil {
+		return nil, err
+	}
+	cashPaid := make(map[int]model.Money, len(payments))
+	for _, payment := range payments {
+		cashPaid[payment.TransactionID] = payment.Amount
+	}
+
+	var refunds []ShiftRefund
+	err = tx.From("refunds").
+		Select("transaction_id", "shift_id", "total_amount").
+		Where(goqu.Ex{"transaction_id": refunded}).
+		Order(goqu.I("id").Asc()).
+		ScanStructsContext(ctx, &refunds)
+	if err != nil {
+		return nil, err
+	}
+
+	reconciliation, err := ReconcileShift(id, row.OpeningFloat, cashSales, cashPaid, refunds, req.CountedCash)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Update("shifts").
+		Set(goqu.Record{
+			"closed_at":     time.Now(),
+			"note":          req.Note,
+			"cash_sales":    reconciliation.CashSales,
+			"cash_refunds":  reconciliation.CashRefunds,
+			"expected_cash": reconciliation.ExpectedCash,
+			"counted_cash":  reconciliation.CountedCash,
+			"variance":      reconciliation.Variance,
+		}).
+		Where(goqu.Ex{"id": id}).
+		Returning(shiftColumns...).
+		Executor().ScanStructContext(ctx, &row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	shift := row.shift()
+	return &shift, nil
+}
+
+func translateShiftError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation on the open shift index
+		return ErrShiftAlreadyOpen
+	}
+	return translateError(err)
+}
+
+// openShift returns the shift cashierID has open, if any. The row is
+// share-locked so the shift cannot close before the sale commits.
+func openShift(ctx context.Context, tx *goqu.TxDatabase, cashierID *int) (*int, error) {
+	query := tx.From("shifts").
+		Select("id").
+		Where(goqu.I("closed_at").IsNull())
+	if cashierID != nil {
+		query = query.Where(goqu.Ex{"cashier_id": *cashierID})
+	} else {
+		query = query.Where(goqu.I("cashier_id").IsNull())
+	}
+
+	var id int
+	found, err := query.ForShare(exp.Wait).ScanValContext(ctx, &id)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &id, nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"category-crud/internal/testdb"
+	"category-crud/model"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// TestOpenShiftConcurrently races cashiers opening their drawer, so only
+// the unique index on open shifts can keep a second one from opening.
+func TestOpenShiftConcurrently(t *testing.T) {
+	const attempts = 10
+	conn, builder := testdb.Open(t)
+	ctx := context.Background()
+	users := NewUserRepository(conn, builder, testQueryTimeout)
+	shifts := NewShiftRepository(conn, builder, testQueryTimeout)
+
+	cashier := &model.User{Username: "alice", Name: "alice", Role: "cashier", Active: true, PasswordHash: "-"}
+	if err := users.Create(ctx, cashier); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	for name, cashierID := range map[string]*int{"cashier": &cashier.ID, "no cashier": nil} {
+		t.Run(name, func(t *testing.T) {
+			var wg sync.WaitGroup
+			errs := make([]error, attempts)
+			opened := make([]*model.Shift, attempts)
+			start := make(chan struct{})
+			for i := range attempts {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-start
+					opened[i] = &model.Shift{CashierID: cashierID, OpeningFloat: model.NewMoney(10000)}
+					errs[i] = shifts.Open(ctx, opened[i])
+				}()
+			}
+			close(start)
+			wg.Wait()
+
+			var open *model.Shift
+			for i, err := range errs {
+				switch {
+				case err == nil && open == nil:
+					open = opened[i]
+				case err == nil:
+					t.Errorf("shifts %d and %d are both open", open.ID, opened[i].ID)
+				case !errors.Is(err, ErrShiftAlreadyOpen):
+					t.Errorf("want ErrShiftAlreadyOpen, got %v", err)
+				}
+			}
+			if open == nil {
+				t.Fatal("no shift opened")
+			}
+
+			closeReq := &model.CloseShiftRequest{CountedCash: model.NewMoney(10000)}
+			if _, err := shifts.Close(ctx, open.ID, closeReq); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+			if _, err := shifts.Close(ctx, open.ID, closeReq); !errors.Is(err, ErrShiftClosed) {
+				t.Errorf("closing twice: want ErrShiftClosed, got %v", err)
+			}
+			if err := shifts.Open(ctx, &model.Shift{CashierID: cashierID}); err != nil {
+				t.Errorf("want a new shift once the first is closed, got %v", err)
+			}
+		})
+	}
+}
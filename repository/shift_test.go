@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"category-crud/model"
+	"errors"
+	"math"
+	"testing"
+)
+
+// refundIn is a refund of transactionID made during shiftID.
+func refundIn(shiftID, transactionID int, amount int64) ShiftRefund {
+	return ShiftRefund{TransactionID: transactionID, ShiftID: &shiftID, TotalAmount: model.NewMoney(amount)}
+}
+
+func TestReconcileShift(t *testing.T) {
+	// the shift being closed; shift 2 is another drawer
+	const shift = 1
+
+	tests := []struct {
+		name         string
+		openingFloat int64
+		cashSales    int64
+		// cashPaid is what each refunded sale took in cash, after change
+		cashPaid     map[int]int64
+		refunds      []ShiftRefund
+		counted      int64
+		wantRefunds  int64
+		wantExpected int64
+		wantVariance int64
+	}{
+		{
+			name:         "no sales",
+			openingFloat: 100000,
+			counted:      100000,
+			wantExpected: 100000,
+		},
+		{
+			name:         "cash sales after change",
+			openingFloat: 100000,
+			cashSales:    22000,
+			counted:      122000,
+			wantExpected: 122000,
+		},
+		{
+			name:         "short",
+			openingFloat: 100000,
+			cashSales:    22000,
+			counted:      120000,
+			wantExpected: 122000,
+			wantVariance: -2000,
+		},
+		{
+			name:         "over",
+			openingFloat: 100000,
+			cashSales:    22000,
+			counted:      122500,
+			wantExpected: 122000,
+			wantVariance: 500,
+		},
+		{
+			name:         "nothing counted",
+			openingFloat: 50000,
+			cashSales:    9000,
+			wantExpected: 59000,
+			wantVariance: -59000,
+		},
+		{
+			name:         "cash sale refunded",
+			openingFloat: 100000,
+			cashSales:    9000,
+			cashPaid:     map[int]int64{1: 9000},
+			refunds:      []ShiftRefund{refundIn(shift, 1, 4000)},
+			counted:      105000,
+			wantRefunds:  4000,
+			wantExpected: 105000,
+		},
+		{
+			name:         "card sale refunded",
+			openingFloat: 100000,
+			refunds:      []ShiftRefund{refundIn(shift, 2, 5000)},
+			counted:      100000,
+			wantExpected: 100000,
+		},
+		{
+			name:         "split sale refunded past its cash",
+			openingFloat: 100000,
+			cashSales:    4000,
+			cashPaid:     map[int]int64{1: 4000},
+			refunds:      []ShiftRefund{refundIn(shift, 1, 9000)},
+			counted:      100000,
+			wantRefunds:  4000,
+			wantExpected: 100000,
+		},
+		{
+			name:      "refunds exhausting a sale's cash",
+			cashSales: 6000,
+			cashPaid:  map[int]int64{1: 5000},
+			refunds: []ShiftRefund{
+				refundIn(shift, 1, 3000),
+				refundIn(shift, 1, 3000),
+				refundIn(shift, 1, 3000),
+			},
+			counted:      1000,
+			wantRefunds:  5000,
+			wantExpected: 1000,
+		},
+		{
+			name:         "refund of an earlier shift's sale",
+			openingFloat: 50000,
+			cashPaid:     map[int]int64{1: 9000},
+			refunds:      []ShiftRefund{refundIn(shift, 1, 9000)},
+			counted:      41000,
+			wantRefunds:  9000,
+			wantExpected: 41000,
+		},
+		{
+			name:         "cash an earlier refund in another drawer already paid back",
+			openingFloat: 50000,
+			cashPaid:     map[int]int64{1: 5000},
+			refunds:      []ShiftRefund{refundIn(2, 1, 3000), refundIn(shift, 1, 3000)},
+			counted:      48000,
+			wantRefunds:  2000,
+			wantExpected: 48000,
+		},
+		{
+			name:         "refunds outside the shift",
+			openingFloat: 50000,
+			cashSales:    9000,
+			cashPaid:     map[int]int64{1: 9000},
+			refunds:      []ShiftRefund{refundIn(2, 1, 1000), {TransactionID: 1, TotalAmount: model.NewMoney(1000)}, refundIn(shift, 1, 1000)},
+			counted:      58000,
+			wantRefunds:  1000,
+			wantExpected: 58000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cashPaid := make(map[int]model.Money, len(tt.cashPaid))
+			for transactionID, amount := range tt.cashPaid {
+				cashPaid[transactionID] = model.NewMoney(amount)
+			}
+
+			got, err := ReconcileShift(shift, model.NewMoney(tt.openingFloat), model.NewMoney(tt.cashSales), cashPaid, tt.refunds, model.NewMoney(tt.counted))
+			if err != nil {
+				t.Fatalf("ReconcileShift: %v", err)
+			}
+			want := model.ShiftReconciliation{
+				OpeningFloat: model.NewMoney(tt.openingFloat),
+				CashSales:    model.NewMoney(tt.cashSales),
+				CashRefunds:  model.NewMoney(tt.wantRefunds),
+				ExpectedCash: model.NewMoney(tt.wantExpected),
+				CountedCash:  model.NewMoney(tt.counted),
+				Variance:     model.NewMoney(tt.wantVariance),
+			}
+			if *got != want {
+				t.Errorf("want %+v, got %+v", want, *got)
+			}
+		})
+	}
+}
+
+func TestReconcileShiftOverflow(t *testing.T) {
+	_, err := ReconcileShift(1, model.NewMoney(math.MaxInt64), model.NewMoney(1), nil, nil, model.NewMoney(0))
+	if !errors.Is(err, model.ErrAmountOverflow) {
+		t.Errorf("want ErrAmountOverflow, got %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package repository
+
+import "category-crud/model"
+
+// ApplyTax computes the tax of checkout lines that have already been
+// discounted. rates maps product ids to their rate in basis points, at
+// most 10000: the rate of the product's own tax class, else of its first
+// live category (by id) that has one, else zero. Tax is rounded per line
+// with policy.Rounding; with inclusive prices it is the part of the
+// discounted subtotal that is tax, otherwise it is added on top.
+// TaxRateBps, TaxAmount and TotalAmount of details are set in place and
+// the tax and grand totals are returned. Exclusive tax can push the grand
+// total past int64, which fails with model.ErrAmountOverflow.
+func ApplyTax(details []model.TransactionDetail, rates map[int]int, policy model.TaxPolicy) (model.Money, model.Money, error) {
+	taxAmount, totalAmount := model.NewMoney(0), model.NewMoney(0)
+	for i := range details {
+		detail := &details[i]
+		detail.TaxRateBps = rates[detail.ProductID]
+
+		rate := int64(detail.TaxRateBps)
+		net := detail.Net()
+		if policy.Inclusive {
+			detail.TaxAmount = net.Fraction(rate, 10000+rate, policy.Rounding)
+			detail.TotalAmount = net
+		} else {
+			detail.TaxAmount = net.Fraction(rate, 10000, policy.Rounding)
+			total, err := net.Add(detail.TaxAmount)
+			if err != nil {
+				return model.Money{}, model.Money{}, err
+			}
+			detail.TotalAmount = total
+		}
+
+		var err error
+		if taxAmount, err = taxAmount.Add(detail.TaxAmount); err != nil {
+			return model.Money{}, model.Money{}, err
+		}
+		if totalAmount, err = totalAmount.Add(detail.TotalAmount); err != nil {
+			return model.Money{}, model.Money{}, err
+		}
+	}
+	return taxAmount, totalAmount, nil
+}
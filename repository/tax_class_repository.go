@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"category-crud/apperror"
+	"category-crud/model"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrTaxClassNotFound  = apperror.NotFound("kelas pajak tidak ditemukan")
+	ErrTaxClassNameTaken = apperror.Conflict("nama kelas pajak sudah dipakai", nil)
+	// ErrTaxClassInUse keeps products and categories from silently losing
+	// their rate; reassign them first.
+	ErrTaxClassInUse = apperror.Conflict("kelas pajak masih dipakai produk atau kategori", nil)
+)
+
+var taxClassColumns = []interface{}{"id", "name", "rate_bps", "created_at", "updated_at"}
+
+type taxClassRepository struct {
+	db      *sql.DB
+	builder *goqu.Database
+	timeout time.Duration
+}
+
+func NewTaxClassRepository(db *sql.DB, builder *goqu.Database, timeout time.Duration) TaxClassRepository {
+	return &taxClassRepository{
+		db:      db,
+		builder: builder,
+		timeout: timeout,
+	}
+}
+
+func (repo *taxClassRepository) GetAll(ctx context.Context) ([]model.TaxClass, error) {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	taxClasses := []model.TaxClass{}
+	err := repo.builder.From("tax_classes").
+		Select(taxClassColumns...).
+		Order(goqu.I("id").Asc()).
+		ScanStructsContext(ctx, &taxClasses)
+	if err != nil {
+		return nil, err
+	}
+	return taxClasses, nil
+}
+
+func (repo *taxClassRepository) Create(ctx context.Context, taxClass *model.TaxClass) error {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	_, err := repo.builder.Insert("tax_classes").Rows(
+		goqu.Record{
+			"name":     taxClass.Name,
+			"rate_bps": taxClass.RateBps,
+		},
+	).Returning(taxClassColumns...).Executor().ScanStructContext(ctx, taxClass)
+	return translateTaxClassError(err)
+}
+
+func (repo *taxClassRepository) GetByID(ctx context.Context, id int) (*model.TaxClass, error) {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	var taxClass model.TaxClass
+	found, err := repo.builder.From("tax_classes").
+		Select(taxClassColumns...).
+		Where(goqu.Ex{"id": id}).
+		ScanStructContext(ctx, &taxClass)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrTaxClassNotFound
+	}
+
+	return &taxClass, nil
+}
+
+// Update changes the rate of future sales only; transaction details keep
+// the rate they were charged.
+func (repo *taxClassRepository) Update(ctx context.Context, taxClass *model.TaxClass) error {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	found, err := repo.builder.Update("tax_classes").
+		Set(goqu.Record{
+			"name":       taxClass.Name,
+			"rate_bps":   taxClass.RateBps,
+			"updated_at": time.Now(),
+		}).
+		Where(goqu.Ex{"id": taxClass.ID}).
+		Returning(taxClassColumns...).
+		Executor().ScanStructContext(ctx, taxClass)
+	if err != nil {
+		return translateTaxClassError(err)
+	}
+	if !found {
+		return ErrTaxClassNotFound
+	}
+
+	return nil
+}
+
+func (repo *taxClassRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	result, err := repo.builder.Delete("tax_classes").
+		Where(goqu.Ex{"id": id}).
+		Executor().ExecContext(ctx)
+	if err != nil {
+		return translateTaxClassError(err)
+	}
+
+	return expectRow(result, ErrTaxClassNotFound)
+}
+
+func translateTaxClassError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "23503": // foreign_key_violation from products or categories
+			return ErrTaxClassInUse
+		case "23505": // unique_violation on name
+			return ErrTaxClassNameTaken
+		}
+	}
+	return translateError(err)
+}
+
+// taxRates resolves the tax rate of each product as described on
+// ApplyTax.
+func taxRates(ctx context.Context, tx selector, productIDs []int) (map[int]int, error) {
+	categoryRate := tx.From(goqu.T("product_categories").As("pc")).
+		InnerJoin(
+			goqu.T("categories").As("c"),
+			goqu.On(goqu.I("pc.category_id").Eq(goqu.I("c.id"))),
+		).
+		InnerJoin(
+			goqu.T("tax_classes").As("ct"),
+			goqu.On(goqu.I("c.tax_class_id").Eq(goqu.I("ct.id"))),
+		).
+		Select(goqu.I("ct.rate_bps")).
+		Where(
+			goqu.I("pc.product_id").Eq(goqu.I("p.id")),
+			goqu.I("c.deleted_at").IsNull(),
+		).
+		Order(goqu.I("c.id").Asc()).
+		Limit(1)
+
+	var rows []struct {
+		ProductID int `db:"product_id"`
+		RateBps   int `db:"rate_bps"`
+	}
+	err := tx.From(goqu.T("products").As("p")).
+		LeftJoin(
+			goqu.T("tax_classes").As("pt"),
+			goqu.On(goqu.I("p.tax_class_id").Eq(goqu.I("pt.id"))),
+		).
+		Select(
+			goqu.I("p.id").As("product_id"),
+			goqu.COALESCE(goqu.I("pt.rate_bps"), categoryRate, 0).As("rate_bps"),
+		).
+		Where(goqu.I("p.id").In(productIDs)).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make(map[int]int, len(rows))
+	for _, row := range rows {
+		rates[row.ProductID] = row.RateBps
+	}
+	return rates, nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"category-crud/model"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// discounted is a line of one unit at price with discount taken off.
+func discounted(productID int, price, discount int64) model.TransactionDetail {
+	detail := line(productID, price, 1)
+	detail.DiscountAmount = model.NewMoney(discount)
+	return detail
+}
+
+func TestApplyTax(t *testing.T) {
+	// products 1 and 2 are taxed at 11%, 4 has a zero-rate class, 5 is
+	// taxed at 100% to land inclusive tax on a half; 3 has no tax class
+	rates := map[int]int{1: 1100, 2: 1100, 4: 0, 5: 10000}
+	exclusive := []model.TransactionDetail{line(1, 50, 1), line(2, 40, 1), line(3, 1000, 1), line(4, 700, 1)}
+	inclusive := []model.TransactionDetail{line(5, 101, 1), line(1, 100000, 1), line(3, 1000, 1)}
+
+	tests := []struct {
+		name      string
+		policy    model.TaxPolicy
+		details   []model.TransactionDetail
+		wantTax   []int64
+		wantTotal int64
+	}{
+		// 5.5 and 4.4 on top
+		{"exclusive half up", model.TaxPolicy{Rounding: model.RoundHalfUp}, exclusive, []int64{6, 4, 0, 0}, 1800},
+		{"exclusive down", model.TaxPolicy{Rounding: model.RoundDown}, exclusive, []int64{5, 4, 0, 0}, 1799},
+		{"exclusive up", model.TaxPolicy{Rounding: model.RoundUp}, exclusive, []int64{6, 5, 0, 0}, 1801},
+		{"exclusive default rounding", model.TaxPolicy{}, exclusive, []int64{6, 4, 0, 0}, 1800},
+		// 50.5 and 9909.9 included
+		{"inclusive half up", model.TaxPolicy{Inclusive: true, Rounding: model.RoundHalfUp}, inclusive, []int64{51, 9910, 0}, 101101},
+		{"inclusive down", model.TaxPolicy{Inclusive: true, Rounding: model.RoundDown}, inclusive, []int64{50, 9909, 0}, 101101},
+		{"inclusive up", model.TaxPolicy{Inclusive: true, Rounding: model.RoundUp}, inclusive, []int64{51, 9910, 0}, 101101},
+		{"exact rates", model.TaxPolicy{Inclusive: true}, []model.TransactionDetail{line(1, 11100, 1), line(2, 111, 3)}, []int64{1100, 33}, 11433},
+		{"exclusive on the discounted price", model.TaxPolicy{}, []model.TransactionDetail{discounted(1, 20000, 5000)}, []int64{1650}, 16650},
+		{"inclusive on the discounted price", model.TaxPolicy{Inclusive: true}, []model.TransactionDetail{discounted(1, 12100, 1000)}, []int64{1100}, 11100},
+		{"fully discounted", model.TaxPolicy{}, []model.TransactionDetail{discounted(1, 5000, 5000)}, []int64{0}, 0},
+		{"empty basket", model.TaxPolicy{}, nil, []int64{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details := append([]model.TransactionDetail{}, tt.details...)
+			taxAmount, totalAmount, err := ApplyTax(details, rates, tt.policy)
+			if err != nil {
+				t.Fatalf("ApplyTax: %v", err)
+			}
+
+			gotTax := make([]int64, len(details))
+			var lineTax, lineTotal int64
+			for i, detail := range details {
+				gotTax[i] = detail.TaxAmount.Amount
+				lineTax += detail.TaxAmount.Amount
+				lineTotal += detail.TotalAmount.Amount
+
+				if detail.TaxRateBps != rates[detail.ProductID] {
+					t.Errorf("line %d: want rate %d, got %d", i, rates[detail.ProductID], detail.TaxRateBps)
+				}
+				want := detail.Net().Amount
+				if !tt.policy.Inclusive {
+					want += detail.TaxAmount.Amount
+				}
+				if detail.TotalAmount.Amount != want {
+					t.Errorf("line %d: want total %d, got %d", i, want, detail.TotalAmount.Amount)
+				}
+			}
+			if !reflect.DeepEqual(gotTax, tt.wantTax) {
+				t.Errorf("want line tax %v, got %v", tt.wantTax, gotTax)
+			}
+			if taxAmount.Amount != lineTax {
+				t.Errorf("header tax %d is not the sum of lines %d", taxAmount.Amount, lineTax)
+			}
+			if totalAmount.Amount != lineTotal || totalAmount.Amount != tt.wantTotal {
+				t.Errorf("want total %d, got header %d and lines %d", tt.wantTotal, totalAmount.Amount, lineTotal)
+			}
+		})
+	}
+}
+
+func TestApplyTaxOverflow(t *testing.T) {
+	rates := map[int]int{1: 1100}
+	tests := []struct {
+		name    string
+		policy  model.TaxPolicy
+		details []model.TransactionDetail
+		wantErr error
+	}{
+		{"exclusive tax on top of a huge line", model.TaxPolicy{}, []model.TransactionDetail{line(1, math.MaxInt64-10, 1)}, model.ErrAmountOverflow},
+		{"inclusive tax stays within the line", model.TaxPolicy{Inclusive: true}, []model.TransactionDetail{line(1, math.MaxInt64-10, 1)}, nil},
+		{"lines adding up past int64", model.TaxPolicy{Inclusive: true}, []model.TransactionDetail{line(2, math.MaxInt64/2+1, 1), line(2, math.MaxInt64/2+1, 1)}, model.ErrAmountOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ApplyTax(tt.details, rates, tt.policy); !errors.Is(err, tt.wantErr) {
+				t.Errorf("want %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
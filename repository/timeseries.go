@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"category-crud/model"
+	"time"
+)
+
+// BucketStart truncates t to the start of its interval on loc's wall
+// clock, matching Postgres' date_trunc on a local timestamp converted back
+// with AT TIME ZONE.
+func BucketStart(t time.Time, interval string, loc *time.Location) time.Time {
+	t = t.In(loc)
+	year, month, day := t.Date()
+	switch interval {
+	case model.IntervalHour:
+		return localTime(year, month, day, t.Hour(), loc)
+	case model.IntervalWeek:
+		monday := day - (int(t.Weekday())+6)%7
+		return localTime(year, month, monday, 0, loc)
+	case model.IntervalMonth:
+		return localTime(year, month, 1, 0, loc)
+	default:
+		return localTime(year, month, day, 0, loc)
+	}
+}
+
+// nextBucket returns the start of the interval after the one starting at
+// start.
+func nextBucket(start time.Time, interval string) time.Time {
+	year, month, day := start.Date()
+	loc := start.Location()
+	switch interval {
+	case model.IntervalHour:
+		return localTime(year, month, day, start.Hour()+1, loc)
+	case model.IntervalWeek:
+		return localTime(year, month, day+7, 0, loc)
+	case model.IntervalMonth:
+		return localTime(year, month+1, 1, 0, loc)
+	default:
+		return localTime(year, month, day+1, 0, loc)
+	}
+}
+
+// localTime resolves a wall clock hour in loc the way Postgres does
+// around DST transitions, where time.Date leaves the choice open: a time
+// that happens twice is the later one, and a time skipped by a jump
+// forward is read with the offset in force before the jump. Hour buckets
+// on a fall-back day thus fold the repeated hour into one, and a day that
+// skips midnight starts an hour late.
+func localTime(year int, month time.Month, day, hour int, loc *time.Location) time.Time {
+	wall := time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+
+	// transitions are months apart, so the offsets a day either side are
+	// the ones before and after any transition at wall
+	_, before := wall.Add(-24 * time.Hour).In(loc).Zone()
+	_, after := wall.Add(24 * time.Hour).In(loc).Zone()
+	early := wall.Add(-time.Duration(before) * time.Second).In(loc)
+	late := wall.Add(-time.Duration(after) * time.Second).In(loc)
+
+	if sameWallClock(late, wall) {
+		return late
+	}
+	return early
+}
+
+func sameWallClock(t, wall time.Time) bool {
+	year, month, day := t.Date()
+	return year == wall.Year() && month == wall.Month() && day == wall.Day() && t.Hour() == wall.Hour() && t.Minute() == wall.Minute()
+}
+
+// CountBuckets returns how many buckets FillBuckets would produce,
+// counting no further than limit.
+func CountBuckets(filter *model.TimeseriesFilter, limit int) int {
+	count := 0
+	for start := BucketStart(filter.StartDate, filter.Interval, filter.Location); start.Before(filter.EndDate) && count < limit; start = nextBucket(start, filter.Interval) {
+		count++
+	}
+	return count
+}
+
+// FillBuckets returns one bucket per interval of the filter's range, in
+// order, taking the totals from the sparse buckets that had sales.
+func FillBuckets(filter *model.TimeseriesFilter, sparse []model.SalesBucket) *model.Timeseries {
+	sales := make(map[int64]model.SalesBucket, len(sparse))
+	for _, bucket := range sparse {
+		sales[bucket.Start.Unix()] = bucket
+	}
+
+	series := &model.Timeseries{
+		Interval: filter.Interval,
+		Timezone: filter.Location.String(),
+		Buckets:  []model.SalesBucket{},
+	}
+	for start := BucketStart(filter.StartDate, filter.Interval, filter.Location); start.Before(filter.EndDate); start = nextBucket(start, filter.Interval) {
+		bucket := sales[start.Unix()]
+		bucket.Start = start
+		series.Buckets = append(series.Buckets, bucket)
+	}
+
+	return series
+}
@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"category-crud/model"
+	"testing"
+	"time"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s unavailable: %v", name, err)
+	}
+	return loc
+}
+
+func mustParseTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+// The expected instants are what date_trunc(interval, t AT TIME ZONE zone)
+// AT TIME ZONE zone returns in Postgres.
+func TestBucketStart(t *testing.T) {
+	tests := []struct {
+		name     string
+		zone     string
+		interval string
+		at       string
+		want     string
+	}{
+		{"hour", "Asia/Jakarta", model.IntervalHour, "2026-03-08T10:45:00Z", "2026-03-08T10:00:00Z"},
+		{"day in a zone ahead of UTC", "Asia/Jakarta", model.IntervalDay, "2026-03-08T18:00:00Z", "2026-03-08T17:00:00Z"},
+		{"hour before spring forward", "America/New_York", model.IntervalHour, "2026-03-08T06:59:00Z", "2026-03-08T06:00:00Z"},
+		{"hour after spring forward", "America/New_York", model.IntervalHour, "2026-03-08T07:00:00Z", "2026-03-08T07:00:00Z"},
+		{"first of the repeated hours", "America/New_York", model.IntervalHour, "2026-11-01T05:30:00Z", "2026-11-01T06:00:00Z"},
+		{"second of the repeated hours", "America/New_York", model.IntervalHour, "2026-11-01T06:30:00Z", "2026-11-01T06:00:00Z"},
+		{"day of spring forward", "America/New_York", model.IntervalDay, "2026-03-08T20:00:00Z", "2026-03-08T05:00:00Z"},
+		{"day of fall back", "America/New_York", model.IntervalDay, "2026-11-01T20:00:00Z", "2026-11-01T04:00:00Z"},
+		{"day that skips midnight", "America/Sao_Paulo", model.IntervalDay, "2018-11-04T12:00:00Z", "2018-11-04T03:00:00Z"},
+		{"week from a Sunday", "Asia/Jakarta", model.IntervalWeek, "2026-03-08T10:00:00Z", "2026-03-01T17:00:00Z"},
+		{"week from a Monday", "Asia/Jakarta", model.IntervalWeek, "2026-03-08T17:00:00Z", "2026-03-08T17:00:00Z"},
+		{"week across a year", "UTC", model.IntervalWeek, "2027-01-02T12:00:00Z", "2026-12-28T00:00:00Z"},
+		{"week across spring forward", "America/New_York", model.IntervalWeek, "2026-03-10T12:00:00Z", "2026-03-09T04:00:00Z"},
+		{"month end", "Asia/Jakarta", model.IntervalMonth, "2026-01-31T16:59:59Z", "2025-12-31T17:00:00Z"},
+		{"month start in local time", "Asia/Jakarta", model.IntervalMonth, "2026-01-31T17:00:00Z", "2026-01-31T17:00:00Z"},
+		{"month across fall back", "America/New_York", model.IntervalMonth, "2026-11-20T12:00:00Z", "2026-11-01T04:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := mustLoadLocation(t, tt.zone)
+			got := BucketStart(mustParseTime(t, tt.at), tt.interval, loc)
+			want := mustParseTime(t, tt.want)
+			if !got.Equal(want) {
+				t.Errorf("want %s, got %s", want.UTC(), got.UTC())
+			}
+			if got.Location() != loc {
+				t.Errorf("want the bucket in %s, got %s", loc, got.Location())
+			}
+		})
+	}
+}
+
+func TestNextBucket(t *testing.T) {
+	tests := []struct {
+		name     string
+		zone     string
+		interval string
+		start    string
+		want     string
+	}{
+		{"into the skipped hour", "America/New_York", model.IntervalHour, "2026-03-08T06:00:00Z", "2026-03-08T07:00:00Z"},
+		{"into the repeated hour", "America/New_York", model.IntervalHour, "2026-11-01T04:00:00Z", "2026-11-01T06:00:00Z"},
+		{"out of the repeated hour", "America/New_York", model.IntervalHour, "2026-11-01T06:00:00Z", "2026-11-01T07:00:00Z"},
+		{"23 hour day", "America/New_York", model.IntervalDay, "2026-03-08T05:00:00Z", "2026-03-09T04:00:00Z"},
+		{"25 hour day", "America/New_York", model.IntervalDay, "2026-11-01T04:00:00Z", "2026-11-02T05:00:00Z"},
+		{"into the day that skips midnight", "America/Sao_Paulo", model.IntervalDay, "2018-11-03T03:00:00Z", "2018-11-04T03:00:00Z"},
+		{"out of the day that skips midnight", "America/Sao_Paulo", model.IntervalDay, "2018-11-04T03:00:00Z", "2018-11-05T02:00:00Z"},
+		{"week across a month", "UTC", model.IntervalWeek, "2026-03-30T00:00:00Z", "2026-04-06T00:00:00Z"},
+		{"January to February", "UTC", model.IntervalMonth, "2026-01-01T00:00:00Z", "2026-02-01T00:00:00Z"},
+		{"February of a leap year", "UTC", model.IntervalMonth, "2028-02-01T00:00:00Z", "2028-03-01T00:00:00Z"},
+		{"December to January", "Asia/Jakarta", model.IntervalMonth, "2026-11-30T17:00:00Z", "2026-12-31T17:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := mustLoadLocation(t, tt.zone)
+			got := nextBucket(mustParseTime(t, tt.start).In(loc), tt.interval)
+			want := mustParseTime(t, tt.want)
+			if !got.Equal(want) {
+				t.Errorf("want %s, got %s", want.UTC(), got.UTC())
+			}
+		})
+	}
+}
+
+func TestCountBuckets(t *testing.T) {
+	tests := []struct {
+		name     string
+		zone     string
+		interval string
+		from, to string
+		limit    int
+		want     int
+	}{
+		{"spring forward day in hours", "America/New_York", model.IntervalHour, "2026-03-08T05:00:00Z", "2026-03-09T04:00:00Z", 100, 23},
+		{"fall back day in hours", "America/New_York", model.IntervalHour, "2026-11-01T04:00:00Z", "2026-11-02T05:00:00Z", 100, 24},
+		{"March in days", "America/New_York", model.IntervalDay, "2026-03-01T05:00:00Z", "2026-04-01T04:00:00Z", 100, 31},
+		{"partial first and last buckets", "UTC", model.IntervalDay, "2026-03-01T12:00:00Z", "2026-03-03T12:00:00Z", 100, 3},
+		{"year in ISO weeks", "UTC", model.IntervalWeek, "2026-01-01T00:00:00Z", "2027-01-01T00:00:00Z", 100, 53},
+		{"year in months", "Asia/Jakarta", model.IntervalMonth, "2025-12-31T17:00:00Z", "2026-12-31T17:00:00Z", 100, 12},
+		{"capped", "UTC", model.IntervalHour, "2026-01-01T00:00:00Z", "2027-01-01T00:00:00Z", 10, 10},
+		{"empty range", "UTC", model.IntervalDay, "2026-03-01T00:00:00Z", "2026-03-01T00:00:00Z", 100, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := &model.TimeseriesFilter{
+				Interval:  tt.interval,
+				Location:  mustLoadLocation(t, tt.zone),
+				StartDate: mustParseTime(t, tt.from),
+				EndDate:   mustParseTime(t, tt.to),
+			}
+			if got := CountBuckets(filter, tt.limit); got != tt.want {
+				t.Errorf("want %d buckets, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFillBuckets(t *testing.T) {
+	loc := mustLoadLocation(t, "America/New_York")
+
+	tests := []struct {
+		name     string
+		interval string
+		from, to string
+		// sales are rung up at these instants, each for 1000
+		sales []string
+		want  map[string]int64
+		count int
+	}{
+		{
+			name:     "hours of spring forward",
+			interval: model.IntervalHour,
+			from:     "2026-03-08T05:00:00Z",
+			to:       "2026-03-09T04:00:00Z",
+			sales:    []string{"2026-03-08T06:30:00Z", "2026-03-08T07:30:00Z"},
+			want:     map[string]int64{"2026-03-08T06:00:00Z": 1000, "2026-03-08T07:00:00Z": 1000},
+			count:    23,
+		},
+		{
+			name:     "hours of fall back",
+			interval: model.IntervalHour,
+			from:     "2026-11-01T04:00:00Z",
+			to:       "2026-11-02T05:00:00Z",
+			sales:    []string{"2026-11-01T05:30:00Z", "2026-11-01T06:30:00Z", "2026-11-01T07:30:00Z"},
+			want:     map[string]int64{"2026-11-01T06:00:00Z": 2000, "2026-11-01T07:00:00Z": 1000},
+			count:    24,
+		},
+		{
+			name:     "days around fall back",
+			interval: model.IntervalDay,
+			from:     "2026-10-31T04:00:00Z",
+			to:       "2026-11-03T05:00:00Z",
+			sales:    []string{"2026-11-01T04:30:00Z", "2026-11-02T04:30:00Z", "2026-11-02T05:30:00Z"},
+			want:     map[string]int64{"2026-11-01T04:00:00Z": 2000, "2026-11-02T05:00:00Z": 1000},
+			count:    3,
+		},
+		{
+			name:     "months",
+			interval: model.IntervalMonth,
+			from:     "2026-01-01T05:00:00Z",
+			to:       "2026-04-01T04:00:00Z",
+			sales:    []string{"2026-02-01T04:59:00Z", "2026-02-28T23:00:00Z", "2026-03-01T05:00:00Z"},
+			want:     map[string]int64{"2026-01-01T05:00:00Z": 1000, "2026-02-01T05:00:00Z": 1000, "2026-03-01T05:00:00Z": 1000},
+			count:    3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := &model.TimeseriesFilter{
+				Interval:  tt.interval,
+				Location:  loc,
+				StartDate: mustParseTime(t, tt.from),
+				EndDate:   mustParseTime(t, tt.to),
+			}
+
+			// group the sales the way the memory store does
+			totals := map[int64]*model.SalesBucket{}
+			var sparse []model.SalesBucket
+			for _, at := range tt.sales {
+				start := BucketStart(mustParseTime(t, at), tt.interval, loc)
+				if totals[start.Unix()] == nil {
+					totals[start.Unix()] = &model.SalesBucket{Start: start}
+				}
+				totals[start.Unix()].TotalTransaksi++
+				totals[start.Unix()].TotalRevenue.Amount += 1000
+			}
+			for _, bucket := range totals {
+				// the database returns the same instant in UTC
+				bucket.Start = bucket.Start.UTC()
+				sparse = append(sparse, *bucket)
+			}
+
+			series := FillBuckets(filter, sparse)
+			if len(series.Buckets) != tt.count {
+				t.Fatalf("want %d buckets, got %d", tt.count, len(series.Buckets))
+			}
+			if got := CountBuckets(filter, 1000); got != tt.count {
+				t.Errorf("CountBuckets: want %d, got %d", tt.count, got)
+			}
+			if series.Timezone != loc.String() {
+				t.Errorf("want timezone %s, got %s", loc, series.Timezone)
+			}
+
+			var total int64
+			for i, bucket := range series.Buckets {
+				if i > 0 && !bucket.Start.After(series.Buckets[i-1].Start) {
+					t.Errorf("bucket %d starts at %s, not after %s", i, bucket.Start, series.Buckets[i-1].Start)
+				}
+				want := tt.want[bucket.Start.UTC().Format(time.RFC3339)]
+				if bucket.TotalRevenue.Amount != want {
+					t.Errorf("bucket %s: want revenue %d, got %d", bucket.Start.UTC().Format(time.RFC3339), want, bucket.TotalRevenue.Amount)
+				}
+				total += bucket.TotalRevenue.Amount
+			}
+			if want := int64(1000 * len(tt.sales)); total != want {
+				t.Errorf("want %d revenue across buckets, got %d", want, total)
+			}
+		})
+	}
+}
@@ -3,87 +3,147 @@ package repository
 import (
 	"category-crud/model"
 	"category-crud/model/dto"
+	"context"
 	"database/sql"
-	"errors"
 	"time"
 
 	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
 )
 
-type TransactionRepository struct {
-	db          *sql.DB
-	productRepo *ProductRepository
-	builder     *goqu.Database
+type transactionRepository struct {
+	db      *sql.DB
+	builder *goqu.Database
+	timeout time.Duration
 }
 
+// idempotencyPurgeBatch bounds how many expired idempotency keys one
+// checkout deletes.
+const idempotencyPurgeBatch = 100
+
 type TransactionResult struct {
 	ID        uint64    `db:"id"`
 	CreatedAt time.Time `db:"created_at"`
 }
 
-func NewTransactionRepository(db *sql.DB, builder *goqu.Database, productRepo *ProductRepository) *TransactionRepository {
-	return &TransactionRepository{
-		db:          db,
-		productRepo: productRepo,
-		builder:     builder,
+func NewTransactionRepository(db *sql.DB, builder *goqu.Database, timeout time.Duration) TransactionRepository {
+	return &transactionRepository{
+		db:      db,
+		builder: builder,
+		timeout: timeout,
 	}
 }
 
-func (repo *TransactionRepository) CreateTransaction(items []model.CheckoutItem) (*model.Transaction, error) {
-	tx, err := repo.db.Begin()
+// CreateTransaction runs the whole checkout inside one transaction. The
+// affected product rows are locked with SELECT ... FOR UPDATE (in id order
+// to avoid deadlocks) so concurrent checkouts cannot oversell.
+func (repo *transactionRepository) CreateTransaction(ctx context.Context, req *model.CheckoutRequest, cashierID *int, idempotencyKey *model.IdempotencyKey, tax model.TaxPolicy) (*model.Transaction, bool, error) {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	tx, err := repo.builder.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	defer tx.Rollback()
 
-	totalAmount := 0
-	details := make([]model.TransactionDetail, 0, len(items))
+	if idempotencyKey != nil {
+		original, err := repo.claimIdempotencyKey(ctx, tx, idempotencyKey, cashierID)
+		if err != nil {
+			return nil, false, err
+		}
+		if original != nil {
+			return original, true, nil
+		}
+	}
+
+	items := req.Items
 	productID := make([]int, 0, len(items))
+	quantities := make(map[int]int, len(items))
 
 	// get product id mapping
 	for _, item := range items {
-		productID = append(productID, item.ProductID)
+		if _, seen := quantities[item.ProductID]; !seen {
+			productID = append(productID, item.ProductID)
+		}
+		quantities[item.ProductID] += item.Quantity
 	}
 
-	products, err := repo.productRepo.GetAll(&dto.ProductFilterRequest{
-		IDs: productID,
-	})
-
-	productMap := make(map[int]*model.Product)
+	var products []model.Product
+	err = tx.From("products").
+		Select("id", "name", "price", "stock").
+		Where(
+			goqu.I("id").In(productID),
+			goqu.I("deleted_at").IsNull(),
+		).
+		Order(goqu.I("id").Asc()).
+		ForUpdate(exp.Wait).
+		ScanStructsContext(ctx, &products)
+	if err != nil {
+		return nil, false, err
+	}
 
+	productMap := make(map[int]model.Product, len(products))
 	for _, product := range products {
-		productMap[product.ID] = &product
+		productMap[product.ID] = product
 	}
 
-	// map total amount and update stock
-	for _, item := range items {
-		product, ok := productMap[item.ProductID]
-		if !ok {
-			return nil, err
+	if err := CheckStock(items, productMap); err != nil {
+		return nil, false, err
+	}
+
+	details, subtotalAmount, err := PriceItems(items, productMap)
+	if err != nil {
+		return nil, false, err
+	}
+
+	promotions, err := runningPromotions(ctx, tx)
+	if err != nil {
+		return nil, false, err
+	}
+	categories := map[int][]int{}
+	if len(promotions) > 0 {
+		if categories, err = liveCategories(ctx, tx, productID); err != nil {
+			return nil, false, err
 		}
+	}
+	discountAmount, promotionID := ApplyPromotions(details, categories, promotions)
 
-		totalAmount += product.Price * item.Quantity
-		details = append(details, model.TransactionDetail{
-			ProductID:   product.ID,
-			ProductName: product.Name,
-			Quantity:    item.Quantity,
-			Subtotal:    product.Price * item.Quantity,
-		})
-		// update stock
-		product.Stock -= item.Quantity
+	rates, err := taxRates(ctx, tx, productID)
+	if err != nil {
+		return nil, false, err
+	}
+	taxAmount, totalAmount, err := ApplyTax(details, rates, tax)
+	if err != nil {
+		return nil, false, err
+	}
+	payments, err := SettlePayments(totalAmount, req.Payments)
+	if err != nil {
+		return nil, false, err
+	}
+	shiftID, err := openShift(ctx, tx, cashierID)
+	if err != nil {
+		return nil, false, err
 	}
 
 	// insert total Amount
 	var result TransactionResult
 
-	_, err = repo.builder.Insert("transactions").Rows(
+	_, err = tx.Insert("transactions").Rows(
 		goqu.Record{
-			"total_amount": totalAmount,
+			"subtotal_amount": subtotalAmount,
+			"discount_amount": discountAmount,
+			"tax_amount":      taxAmount,
+			"total_amount":    totalAmount,
+			"tax_inclusive":   tax.Inclusive,
+			"promotion_id":    promotionID,
+			"cashier_id":      cashierID,
+			"shift_id":        shiftID,
 		},
-	).Returning("id", "created_at").Executor().ScanStruct(&result)
+	).Returning("id", "created_at").Executor().ScanStructContext(ctx, &result)
 
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	// insert details
@@ -91,129 +151,816 @@ func (repo *TransactionRepository) CreateTransaction(items []model.CheckoutItem)
 	detailRecords := make([]goqu.Record, 0, len(details))
 	for _, detail := range details {
 		detailRecords = append(detailRecords, goqu.Record{
-			"transaction_id": result.ID,
-			"product_id":     detail.ProductID,
-			"quantity":       detail.Quantity,
-			"subtotal":       detail.Subtotal,
+			"transaction_id":  result.ID,
+			"product_id":      detail.ProductID,
+			"product_name":    detail.ProductName,
+			"unit_price":      detail.UnitPrice,
+			"quantity":        detail.Quantity,
+			"subtotal":        detail.Subtotal,
+			"discount_amount": detail.DiscountAmount,
+			"promotion_id":    detail.PromotionID,
+			"tax_rate_bps":    detail.TaxRateBps,
+			"tax_amount":      detail.TaxAmount,
+			"total_amount":    detail.TotalAmount,
 		})
 	}
 
-	err = repo.builder.Insert("transaction_details").Rows(
+	err = tx.Insert("transaction_details").Rows(
 		detailRecords,
 	).
-		Returning(goqu.Star()).
-		Executor().ScanStructs(&insertedDetails)
+		Returning(detailColumns...).
+		Executor().ScanStructsContext(ctx, &insertedDetails)
 
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	// update product stock
-	for _, product := range products {
-		_, err = repo.builder.Update("products").Set(goqu.Record{
-			"stock": productMap[product.ID].Stock,
-		}).Where(goqu.Ex{
-			"id": product.ID,
-		}).Executor().Exec()
+	insertedPayments := []model.Payment{}
+	paymentRecords := make([]goqu.Record, 0, len(payments))
+	for _, payment := range payments {
+		paymentRecords = append(paymentRecords, goqu.Record{
+			"transaction_id": result.ID,
+			"method":         payment.Method,
+			"amount":         payment.Amount,
+			"tendered":       payment.Tendered,
+			"change_amount":  payment.Change,
+			"reference":      payment.Reference,
+		})
+	}
+
+	// a sale that comes to nothing is settled without payments
+	if len(paymentRecords) > 0 {
+		err = tx.Insert("transaction_payments").Rows(
+			paymentRecords,
+		).
+			Returning(paymentColumns...).
+			Executor().ScanStructsContext(ctx, &insertedPayments)
+
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	// update product stock; the rows are locked so the guard only trips
+	// if the schema is changed underneath us
+	for id, quantity := range quantities {
+		result, err := tx.Update("products").Set(goqu.Record{
+			"stock": goqu.L("stock - ?", quantity),
+		}).Where(
+			goqu.Ex{"id": id},
+			goqu.I("stock").Gte(quantity),
+		).Executor().ExecContext(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return nil, false, err
+		}
+		if rows == 0 {
+			return nil, false, checkoutError([]CheckoutItemError{{
+				ProductID: id,
+				Reason:    CheckoutReasonInsufficientStock,
+				Requested: quantity,
+				Available: productMap[id].Stock,
+			}})
+		}
+	}
+
+	if idempotencyKey != nil {
+		_, err = tx.Update("idempotency_keys").
+			Set(goqu.Record{"transaction_id": result.ID}).
+			Where(idempotencyScope(idempotencyKey.Key, cashierID)).
+			Executor().ExecContext(ctx)
+		if err != nil {
+			return nil, false, err
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	return &model.Transaction{
-		ID:          int(result.ID),
-		CreatedAt:   result.CreatedAt,
-		TotalAmount: totalAmount,
-		Details:     insertedDetails,
-	}, nil
+		ID:             int(result.ID),
+		CreatedAt:      result.CreatedAt,
+		SubtotalAmount: subtotalAmount,
+		DiscountAmount: discountAmount,
+		TaxAmount:      taxAmount,
+		TotalAmount:    totalAmount,
+		TaxInclusive:   tax.Inclusive,
+		PromotionID:    promotionID,
+		CashierID:      cashierID,
+		ShiftID:        shiftID,
+		Details:        insertedDetails,
+		Payments:       insertedPayments,
+	}, false, nil
 }
 
-func (repo *TransactionRepository) GetReport(startDateStr string, endDateStr string) (*model.Report, error) {
+// claimIdempotencyKey inserts the key, scoped to cashierID, inside the
+// checkout transaction. A concurrent request with the same key blocks on
+// the unique index until the first one commits or rolls back. If the key
+// already belongs to a finished checkout, that transaction is returned
+// instead.
+func (repo *transactionRepository) claimIdempotencyKey(ctx context.Context, tx *goqu.TxDatabase, key *model.IdempotencyKey, cashierID *int) (*model.Transaction, error) {
 	now := time.Now()
-	startDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	endDate := startDate.Add(24 * time.Hour)
 
-	var err error
+	// an expired key is free to be used again
+	_, err := tx.Delete("idempotency_keys").Where(
+		idempotencyScope(key.Key, cashierID),
+		goqu.I("expires_at").Lte(now),
+	).Executor().ExecContext(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	// Parse start_date or default to today
-	if startDateStr == "" {
-		now := time.Now()
-		startDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	} else {
-		startDate, err = time.Parse("2006-01-02", startDateStr)
-		if err != nil {
+	// sweep a batch of other expired keys so the table does not grow
+	// forever; rows another checkout is sweeping are skipped, not waited on
+	expired := tx.From("idempotency_keys").
+		Select("id").
+		Where(goqu.I("expires_at").Lte(now)).
+		Limit(idempotencyPurgeBatch).
+		ForUpdate(exp.SkipLocked)
+	_, err = tx.Delete("idempotency_keys").
+		Where(goqu.Ex{"id": expired}).
+		Executor().ExecContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := tx.Insert("idempotency_keys").Rows(goqu.Record{
+		"key":          key.Key,
+		"cashier_id":   cashierID,
+		"request_hash": key.RequestHash,
+		"expires_at":   key.ExpiresAt,
+	}).OnConflict(goqu.DoNothing()).Executor().ExecContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rows == 1 {
+		return nil, nil
+	}
+
+	var stored model.IdempotencyKey
+	_, err = tx.From("idempotency_keys").
+		Select(
+			"key",
+			"request_hash",
+			goqu.COALESCE(goqu.I("transaction_id"), 0).As("transaction_id"),
+			"expires_at",
+		).
+		Where(idempotencyScope(key.Key, cashierID)).
+		ScanStructContext(ctx, &stored)
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.RequestHash != key.RequestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+
+	return findTransaction(ctx, tx, stored.TransactionID)
+}
+
+// idempotencyScope matches key as sent by cashierID, mirroring the unique
+// index on (COALESCE(cashier_id, 0), key).
+func idempotencyScope(key string, cashierID *int) exp.Expression {
+	scope := 0
+	if cashierID != nil {
+		scope = *cashierID
+	}
+	return goqu.And(
+		goqu.Ex{"key": key},
+		goqu.L("COALESCE(cashier_id, 0) = ?", scope),
+	)
+}
+
+// selector is satisfied by both *goqu.Database and *goqu.TxDatabase.
+type selector interface {
+	From(from ...interface{}) *goqu.SelectDataset
+}
+
+// findTransaction loads a transaction header with its details.
+func findTransaction(ctx context.Context, db selector, id int) (*model.Transaction, error) {
+	var transaction model.Transaction
+	found, err := db.From("transactions").
+		Select(transactionColumns...).
+		Where(goqu.Ex{"id": id}).
+		ScanStructContext(ctx, &transaction)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrTransactionNotFound
+	}
 
+	transactions := []model.Transaction{transaction}
+	if err := attachDetails(ctx, db, transactions); err != nil {
+		return nil, err
+	}
+	if err := attachPayments(ctx, db, transactions); err != nil {
+		return nil, err
+	}
+
+	return &transactions[0], nil
+}
+
+var transactionColumns = []interface{}{
+	"id",
+	"subtotal_amount",
+	"discount_amount",
+	"tax_amount",
+	"total_amount",
+	"tax_inclusive",
+	"promotion_id",
+	"created_at",
+	"voided_at",
+	"cashier_id",
+	"shift_id",
+}
+
+// detailColumns reads the snapshots of transaction_details. Rows from
+// before the snapshot columns existed stay empty until they are
+// backfilled.
+var detailColumns = []interface{}{
+	"id",
+	"transaction_id",
+	"product_id",
+	goqu.COALESCE(goqu.I("product_name"), "").As("product_name"),
+	goqu.COALESCE(goqu.I("unit_price"), 0).As("unit_price"),
+	"quantity",
+	"subtotal",
+	"discount_amount",
+	"promotion_id",
+	"tax_rate_bps",
+	"tax_amount",
+	"total_amount",
+}
+
+// attachDetails loads the details of the given transactions.
+func attachDetails(ctx context.Context, db selector, transactions []model.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	transactionIDs := make([]int, 0, len(transactions))
+	for _, transaction := range transactions {
+		transactionIDs = append(transactionIDs, transaction.ID)
+	}
+
+	var details []model.TransactionDetail
+	err := db.From("transaction_details").
+		Select(detailColumns...).
+		Where(goqu.I("transaction_id").In(transactionIDs)).
+		Order(goqu.I("id").Asc()).
+		ScanStructsContext(ctx, &details)
+	if err != nil {
+		return err
+	}
+
+	transactionMap := make(map[int]*model.Transaction, len(transactions))
+	for i := range transactions {
+		transactions[i].Details = []model.TransactionDetail{}
+		transactionMap[transactions[i].ID] = &transactions[i]
+	}
+	for _, detail := range details {
+		if transaction, ok := transactionMap[detail.TransactionID]; ok {
+			transaction.Details = append(transaction.Details, detail)
 		}
 	}
 
-	// Parse end_date or default to end of today
-	if endDateStr == "" {
-		now := time.Now()
-		endDate = time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, now.Location())
-	} else {
-		endDate, err = time.Parse("2006-01-02", endDateStr)
-		if err != nil {
-			return nil, errors.New("Invalid end_date format. Use YYYY-MM-DD")
+	return nil
+}
+
+var paymentColumns = []interface{}{
+	"id",
+	"transaction_id",
+	"method",
+	"amount",
+	"tendered",
+	"change_amount",
+	"reference",
+}
+
+// attachPayments loads the payments of the given transactions. Sales
+// made before payments were recorded have none.
+func attachPayments(ctx context.Context, db selector, transactions []model.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	transactionIDs := make([]int, 0, len(transactions))
+	for _, transaction := range transactions {
+		transactionIDs = append(transactionIDs, transaction.ID)
+	}
+
+	var payments []model.Payment
+	err := db.From("transaction_payments").
+		Select(paymentColumns...).
+		Where(goqu.I("transaction_id").In(transactionIDs)).
+		Order(goqu.I("id").Asc()).
+		ScanStructsContext(ctx, &payments)
+	if err != nil {
+		return err
+	}
+
+	transactionMap := make(map[int]*model.Transaction, len(transactions))
+	for i := range transactions {
+		transactions[i].Payments = []model.Payment{}
+		transactionMap[transactions[i].ID] = &transactions[i]
+	}
+	for _, payment := range payments {
+		if transaction, ok := transactionMap[payment.TransactionID]; ok {
+			transaction.Payments = append(transaction.Payments, payment)
 		}
-		// Set to end of day
-		endDate = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 23, 59, 59, 0, endDate.Location())
 	}
 
-	// Validate: start_date must be before end_date
-	if startDate.After(endDate) {
-		return nil, errors.New("start_date must be before end_date")
+	return nil
+}
+
+func (repo *transactionRepository) GetAll(ctx context.Context, filter *dto.TransactionFilterRequest) (*dto.TransactionPage, error) {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	queryRaw := filterTransactions(repo.builder, repo.builder.From(goqu.T("transactions").As("t")), filter)
+
+	total, err := queryRaw.CountContext(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	var transactions []model.Transaction
-	var transactionID []int
+	err = queryRaw.
+		Select(transactionColumns...).
+		Order(goqu.I("t.created_at").Desc(), goqu.I("t.id").Desc()).
+		Limit(uint(filter.Limit)).
+		Offset(uint((filter.Page-1)*filter.Limit)).
+		ScanStructsContext(ctx, &transactions)
+	if err != nil {
+		return nil, err
+	}
 
-	err = repo.builder.
-		From("transactions").
-		Where(goqu.I("created_at").Gte(startDate)).
-		Where(goqu.I("created_at").Lt(endDate)).
-		ScanStructs(&transactions)
+	if err := attachDetails(ctx, repo.builder, transactions); err != nil {
+		return nil, err
+	}
+	if err := attachPayments(ctx, repo.builder, transactions); err != nil {
+		return nil, err
+	}
 
-	for _, transaction := range transactions {
-		transactionID = append(transactionID, transaction.ID)
+	if transactions == nil {
+		transactions = []model.Transaction{}
+	}
+
+	return &dto.TransactionPage{
+		Data:  transactions,
+		Total: int(total),
+		Limit: filter.Limit,
+		Page:  filter.Page,
+	}, nil
+}
+
+// filterTransactions applies the list filters to a query on transactions
+// aliased as t.
+func filterTransactions(builder *goqu.Database, query *goqu.SelectDataset, filter *dto.TransactionFilterRequest) *goqu.SelectDataset {
+	if filter.StartDate != nil {
+		query = query.Where(goqu.I("t.created_at").Gte(*filter.StartDate))
+	}
+
+	if filter.EndDate != nil {
+		query = query.Where(goqu.I("t.created_at").Lt(*filter.EndDate))
+	}
+
+	if len(filter.ProductIDs) > 0 {
+		query = query.Where(goqu.I("t.id").In(
+			builder.From("transaction_details").
+				Select("transaction_id").
+				Where(goqu.I("product_id").In(filter.ProductIDs)),
+		))
 	}
 
+	if filter.MinTotal != nil {
+		query = query.Where(goqu.I("t.total_amount").Gte(*filter.MinTotal))
+	}
+
+	if filter.MaxTotal != nil {
+		query = query.Where(goqu.I("t.total_amount").Lte(*filter.MaxTotal))
+	}
+
+	return query
+}
+
+// ExportLines reads the rows through a cursor instead of loading them.
+// It is bound by the request context only: the query timeout is meant for
+// single queries, not for a download that lasts as long as the client
+// reads.
+func (repo *transactionRepository) ExportLines(ctx context.Context, filter *dto.TransactionFilterRequest, yield func(*model.TransactionLine) error) error {
+	query := filterTransactions(repo.builder, repo.builder.From(goqu.T("transactions").As("t")), filter).
+		InnerJoin(
+			goqu.T("transaction_details").As("td"),
+			goqu.On(goqu.I("td.transaction_id").Eq(goqu.I("t.id"))),
+		).
+		Select(
+			goqu.I("t.id").As("transaction_id"),
+			goqu.I("t.created_at").As("created_at"),
+			goqu.I("t.voided_at").As("voided_at"),
+			goqu.I("t.cashier_id").As("cashier_id"),
+			goqu.I("t.total_amount").As("total_amount"),
+			goqu.I("td.id").As("detail_id"),
+			goqu.I("td.product_id").As("product_id"),
+			goqu.COALESCE(goqu.I("td.product_name"), "").As("product_name"),
+			goqu.COALESCE(goqu.I("td.unit_price"), 0).As("unit_price"),
+			goqu.I("td.quantity").As("quantity"),
+			goqu.I("td.subtotal").As("subtotal"),
+			goqu.I("td.discount_amount").As("discount_amount"),
+			goqu.I("td.tax_amount").As("tax_amount"),
+			goqu.I("td.total_amount").As("line_total"),
+		).
+		Order(goqu.I("t.created_at").Desc(), goqu.I("t.id").Desc(), goqu.I("td.id").Asc())
+
+	scanner, err := query.Executor().ScannerContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer scanner.Close()
+
+	for scanner.Next() {
+		var line model.TransactionLine
+		if err := scanner.ScanStruct(&line); err != nil {
+			return err
+		}
+		if err := yield(&line); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+func (repo *transactionRepository) GetByID(ctx context.Context, id int) (*model.Transaction, error) {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	return findTransaction(ctx, repo.builder, id)
+}
+
+// CreateRefund locks the transaction row so refunds of the same sale are
+// serialised, then records the refund document and restocks the products
+// in the same database transaction. Like a checkout it share-locks the
+// shift it is tied to, so the shift cannot close before it commits.
+func (repo *transactionRepository) CreateRefund(ctx context.Context, transactionID int, refundType string, req *model.RefundRequest, cashierID *int) (*model.Refund, error) {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	tx, err := repo.builder.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback()
 
-	if len(transactionID) == 0 {
-		return nil, nil
+	var transaction model.Transaction
+	found, err := tx.From("transactions").
+		Select(transactionColumns...).
+		Where(goqu.Ex{"id": transactionID}).
+		ForUpdate(exp.Wait).
+		ScanStructContext(ctx, &transaction)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrTransactionNotFound
+	}
+	if transaction.VoidedAt != nil {
+		return nil, ErrTransactionVoided
+	}
+
+	var details []model.TransactionDetail
+	err = tx.From("transaction_details").
+		Select(detailColumns...).
+		Where(goqu.Ex{"transaction_id": transactionID}).
+		Order(goqu.I("id").Asc()).
+		ScanStructsContext(ctx, &details)
+	if err != nil {
+		return nil, err
+	}
+
+	var refundedLines []RefundedLine
+	err = tx.From(goqu.T("refund_details").As("rd")).
+		InnerJoin(
+			goqu.T("refunds").As("r"),
+			goqu.On(goqu.I("rd.refund_id").Eq(goqu.I("r.id"))),
+		).
+		Select(
+			goqu.I("rd.transaction_detail_id").As("transaction_detail_id"),
+			goqu.SUM("rd.quantity").As("quantity"),
+			goqu.SUM("rd.amount").As("amount"),
+			goqu.SUM("rd.tax_amount").As("tax_amount"),
+		).
+		Where(goqu.Ex{"r.transaction_id": transactionID}).
+		GroupBy("rd.transaction_detail_id").
+		ScanStructsContext(ctx, &refundedLines)
+	if err != nil {
+		return nil, err
+	}
+
+	refunded := make(map[int]RefundedLine, len(refundedLines))
+	for _, line := range refundedLines {
+		refunded[line.TransactionDetailID] = line
+	}
+
+	lines, err := PlanRefund(details, refunded, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	shiftID, err := openShift(ctx, tx, cashierID)
+	if err != nil {
+		return nil, err
+	}
+
+	refund := model.Refund{
+		TransactionID: transactionID,
+		CashierID:     cashierID,
+		ShiftID:       shiftID,
+		Type:          refundType,
+		Reason:        req.Reason,
+	}
+	refund.TotalAmount, refund.TaxAmount, err = RefundTotals(lines)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Insert("refunds").Rows(goqu.Record{
+		"transaction_id": refund.TransactionID,
+		"cashier_id":     refund.CashierID,
+		"shift_id":       refund.ShiftID,
+		"type":           refund.Type,
+		"reason":         refund.Reason,
+		"total_amount":   refund.TotalAmount,
+		"tax_amount":     refund.TaxAmount,
+	}).Returning("id", "created_at").Executor().ScanStructContext(ctx, &refund)
+	if err != nil {
+		return nil, err
+	}
+
+	detailRecords := make([]goqu.Record, 0, len(lines))
+	for _, line := range lines {
+		detailRecords = append(detailRecords, goqu.Record{
+			"refund_id":             refund.ID,
+			"transaction_detail_id": line.TransactionDetailID,
+			"product_id":            line.ProductID,
+			"quantity":              line.Quantity,
+			"amount":                line.Amount,
+			"tax_amount":            line.TaxAmount,
+		})
+	}
+
+	err = tx.Insert("refund_details").Rows(detailRecords).
+		Returning(goqu.Star()).
+		Executor().ScanStructsContext(ctx, &refund.Details)
+	if err != nil {
+		return nil, err
+	}
+
+	// put the goods back on the shelf
+	for _, line := range lines {
+		_, err = tx.Update("products").Set(goqu.Record{
+			"stock": goqu.L("stock + ?", line.Quantity),
+		}).Where(goqu.Ex{"id": line.ProductID}).Executor().ExecContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if refundType == model.RefundTypeVoid {
+		_, err = tx.Update("transactions").
+			Set(goqu.Record{"voided_at": refund.CreatedAt}).
+			Where(goqu.Ex{"id": transactionID}).
+			Executor().ExecContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &refund, nil
+}
+
+func (repo *transactionRepository) GetReport(ctx context.Context, filter *model.ReportFilter) (*model.Report, error) {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	inRange := goqu.And(
+		goqu.I("t.created_at").Gte(filter.StartDate),
+		goqu.I("t.created_at").Lt(filter.EndDate),
+	)
+	refundsInRange := goqu.And(
+		goqu.I("created_at").Gte(filter.StartDate),
+		goqu.I("created_at").Lt(filter.EndDate),
+	)
+	if filter.ShiftID != nil {
+		inRange = goqu.And(goqu.I("t.shift_id").Eq(*filter.ShiftID))
+		refundsInRange = goqu.And(goqu.I("shift_id").Eq(*filter.ShiftID))
 	}
 
 	var report model.Report
-	var productTerlaris model.ProductTerlaris
+	_, err := repo.builder.
+		From(goqu.T("transactions").As("t")).
+		Select(
+			goqu.COALESCE(goqu.SUM("t.total_amount"), 0).As("total_revenue"),
+			goqu.COALESCE(goqu.SUM("t.discount_amount"), 0).As("total_discounts"),
+			goqu.COALESCE(goqu.SUM("t.tax_amount"), 0).As("total_tax"),
+			goqu.COUNT(goqu.Star()).As("total_transaksi"),
+		).
+		Where(inRange).
+		ScanStructContext(ctx, &report)
+	if err != nil {
+		return nil, err
+	}
 
+	var refunds struct {
+		TotalAmount model.Money `db:"total_amount"`
+		TaxAmount   model.Money `db:"tax_amount"`
+	}
 	_, err = repo.builder.
-		From("transaction_details").
+		From("refunds").
 		Select(
-			goqu.SUM("subtotal").As("total_revenue"),
-			goqu.COUNT("id").As("total_transaksi"),
+			goqu.COALESCE(goqu.SUM("total_amount"), 0).As("total_amount"),
+			goqu.COALESCE(goqu.SUM("tax_amount"), 0).As("tax_amount"),
 		).
-		Where(goqu.C("transaction_id").Eq(transactionID)).
-		ScanStruct(&report)
+		Where(refundsInRange).
+		ScanStructContext(ctx, &refunds)
+	if err != nil {
+		return nil, err
+	}
+	report.TotalRefunds = refunds.TotalAmount
+	report.TaxRefunded = refunds.TaxAmount
 
-	_, err = repo.builder.
+	// a range without sales can still have refunds of earlier ones; the
+	// remaining queries only find rows for sales
+	if report.TotalTransaks == 0 {
+		if err := FinishReport(&report); err != nil {
+			return nil, err
+		}
+		return &report, nil
+	}
+
+	// every detail sold in the range
+	details := repo.builder.
 		From(goqu.T("transaction_details").As("td")).
 		InnerJoin(
-			goqu.T("products").As("p"),
-			goqu.On(goqu.I("td.product_id").Eq(goqu.I("p.id"))),
+			goqu.T("transactions").As("t"),
+			goqu.On(goqu.I("td.transaction_id").Eq(goqu.I("t.id"))),
+		).
+		Where(inRange)
+
+	_, err = details.
+		Select(goqu.COALESCE(goqu.SUM("td.quantity"), 0)).
+		ScanValContext(ctx, &report.ItemsSold)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.builder.
+		From(goqu.T("transaction_payments").As("p")).
+		InnerJoin(
+			goqu.T("transactions").As("t"),
+			goqu.On(goqu.I("p.transaction_id").Eq(goqu.I("t.id"))),
 		).
 		Select(
-			goqu.I("p.name").As("product_name"),
-			goqu.SUM("td.quantity").As("total_qty"),
+			goqu.I("p.method").As("method"),
+			goqu.COUNT(goqu.Star()).As("payments"),
+			goqu.SUM("p.amount").As("amount"),
+			goqu.SUM("p.tendered").As("tendered"),
+			goqu.SUM("p.change_amount").As("change_amount"),
 		).
-		GroupBy("td.product_id", "p.name").
-		Order(goqu.I("total_qty").Desc()).
-		Limit(1).
-		ScanStruct(&productTerlaris)
+		Where(inRange).
+		GroupBy("p.method").
+		ScanStructsContext(ctx, &report.Payments)
+	if err != nil {
+		return nil, err
+	}
 
-	report.ProductTerlaris = productTerlaris
-	return &report, err
+	// the name recorded on the most recent sale wins when a product was
+	// renamed
+	productSales := details.
+		Select(
+			goqu.I("td.product_id").As("product_id"),
+			goqu.L("COALESCE((ARRAY_AGG(td.product_name ORDER BY td.id DESC))[1], '')").As("product_name"),
+			goqu.SUM("td.quantity").As("quantity"),
+			goqu.SUM("td.total_amount").As("revenue"),
+		).
+		GroupBy("td.product_id").
+		Limit(uint(filter.Top))
+
+	err = productSales.
+		Order(goqu.I("quantity").Desc(), goqu.I("product_id").Asc()).
+		ScanStructsContext(ctx, &report.TopByQuantity)
+	if err != nil {
+		return nil, err
+	}
+
+	err = productSales.
+		Order(goqu.I("revenue").Desc(), goqu.I("product_id").Asc()).
+		ScanStructsContext(ctx, &report.TopByRevenue)
+	if err != nil {
+		return nil, err
+	}
+
+	// deleted categories still count: the sales happened
+	err = details.
+		LeftJoin(
+			goqu.T("product_categories").As("pc"),
+			goqu.On(goqu.I("td.product_id").Eq(goqu.I("pc.product_id"))),
+		).
+		LeftJoin(
+			goqu.T("categories").As("c"),
+			goqu.On(goqu.I("pc.category_id").Eq(goqu.I("c.id"))),
+		).
+		Select(
+			goqu.I("pc.category_id").As("category_id"),
+			goqu.COALESCE(goqu.I("c.name"), "").As("category_name"),
+			goqu.SUM("td.quantity").As("quantity"),
+			goqu.SUM("td.total_amount").As("revenue"),
+		).
+		GroupBy("pc.category_id", "c.name").
+		Order(goqu.I("revenue").Desc(), goqu.I("pc.category_id").Asc().NullsLast()).
+		ScanStructsContext(ctx, &report.Categories)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.builder.
+		From(goqu.T("transactions").As("t")).
+		LeftJoin(
+			goqu.T("users").As("u"),
+			goqu.On(goqu.I("t.cashier_id").Eq(goqu.I("u.id"))),
+		).
+		Select(
+			goqu.I("t.cashier_id").As("cashier_id"),
+			goqu.COALESCE(goqu.Func("NULLIF", goqu.I("u.name"), ""), goqu.I("u.username"), "").As("cashier_name"),
+			goqu.SUM("t.total_amount").As("total_revenue"),
+			goqu.COUNT("t.id").As("total_transaksi"),
+		).
+		Where(inRange).
+		GroupBy("t.cashier_id", "u.name", "u.username").
+		Order(goqu.I("total_revenue").Desc(), goqu.I("t.cashier_id").Asc().NullsLast()).
+		ScanStructsContext(ctx, &report.Cashiers)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := FinishReport(&report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (repo *transactionRepository) GetTimeseries(ctx context.Context, filter *model.TimeseriesFilter) (*model.Timeseries, error) {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	// units per transaction, so each total_amount is summed once
+	items := repo.builder.
+		From("transaction_details").
+		Select(goqu.I("transaction_id"), goqu.SUM("quantity").As("quantity")).
+		GroupBy("transaction_id")
+
+	// truncate on the requested zone's wall clock, then convert back
+	timezone := filter.Location.String()
+	bucket := goqu.L("date_trunc(?, t.created_at AT TIME ZONE ?) AT TIME ZONE ?", filter.Interval, timezone, timezone)
+
+	var buckets []model.SalesBucket
+	err := repo.builder.
+		From(goqu.T("transactions").As("t")).
+		LeftJoin(
+			items.As("d"),
+			goqu.On(goqu.I("d.transaction_id").Eq(goqu.I("t.id"))),
+		).
+		Select(
+			bucket.As("bucket"),
+			goqu.SUM("t.total_amount").As("total_revenue"),
+			goqu.COUNT("t.id").As("total_transaksi"),
+			goqu.COALESCE(goqu.SUM("d.quantity"), 0).As("items_sold"),
+		).
+		Where(
+			goqu.I("t.created_at").Gte(filter.StartDate),
+			goqu.I("t.created_at").Lt(filter.EndDate),
+		).
+		GroupBy(goqu.I("bucket")).
+		Order(goqu.I("bucket").Asc()).
+		ScanStructsContext(ctx, &buckets)
+	if err != nil {
+		return nil, err
+	}
 
+	return FillBuckets(filter, buckets), nil
 }
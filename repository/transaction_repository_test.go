@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"category-crud/apperror"
+	"category-crud/internal/testdb"
+	"category-crud/model"
+	"category-crud/model/dto"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+// TestCreateTransactionConcurrentStock races checkouts of two products
+// listed in opposite orders, so both the FOR UPDATE locks and their id
+// order (a deadlock would fail a checkout) are exercised.
+func TestCreateTransactionConcurrentStock(t *testing.T) {
+	const (
+		buyers = 40
+		stock  = 13
+	)
+	conn, builder := testdb.Open(t)
+	ctx := context.Background()
+	products := NewProductRepository(conn, builder, testQueryTimeout)
+	transactions := NewTransactionRepository(conn, builder, testQueryTimeout)
+
+	coffee := &dto.ProductRequest{Name: "Kopi", Price: model.NewMoney(8000), Stock: stock}
+	tea := &dto.ProductRequest{Name: "Teh", Price: model.NewMoney(5000), Stock: stock}
+	for _, product := range []*dto.ProductRequest{coffee, tea} {
+		if err := products.Create(ctx, product); err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, buyers)
+	start := make(chan struct{})
+	for i := range buyers {
+		items := []model.CheckoutItem{
+			{ProductID: coffee.ID, Quantity: 1},
+			{ProductID: tea.ID, Quantity: 1},
+		}
+		if i%2 == 1 {
+			items[0], items[1] = items[1], items[0]
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, _, errs[i] = transactions.CreateTransaction(ctx, &model.CheckoutRequest{
+				Items:    items,
+				Payments: []model.PaymentRequest{{Method: model.PaymentCash, Amount: model.NewMoney(13000)}},
+			}, nil, nil, model.TaxPolicy{Inclusive: true})
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	succeeded, conflicts := 0, 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case apperror.CodeOf(err) == apperror.CodeInsufficientStock:
+			conflicts++
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if succeeded != stock || conflicts != buyers-stock {
+		t.Errorf("want %d sales and %d stock conflicts, got %d and %d", stock, buyers-stock, succeeded, conflicts)
+	}
+
+	for _, product := range []*dto.ProductRequest{coffee, tea} {
+		stored, err := products.GetByID(ctx, product.ID, false)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if stored.Stock != 0 {
+			t.Errorf("%s: want stock 0, got %d", product.Name, stored.Stock)
+		}
+	}
+}
+
+func TestCreateTransactionIdempotencyKeys(t *testing.T) {
+	conn, builder := testdb.Open(t)
+	ctx := context.Background()
+	products := NewProductRepository(conn, builder, testQueryTimeout)
+	users := NewUserRepository(conn, builder, testQueryTimeout)
+	transactions := NewTransactionRepository(conn, builder, testQueryTimeout)
+
+	product := &dto.ProductRequest{Name: "Kopi", Price: model.NewMoney(8000), Stock: 100}
+	if err := products.Create(ctx, product); err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	var cashiers []*model.User
+	for _, username := range []string{"alice", "bob"} {
+		user := &model.User{Username: username, Name: username, Role: "cashier", Active: true, PasswordHash: "-"}
+		if err := users.Create(ctx, user); err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		cashiers = append(cashiers, user)
+	}
+	alice, bob := &cashiers[0].ID, &cashiers[1].ID
+
+	checkout := func(cashierID *int, key, hash string, expiresAt time.Time) (*model.Transaction, bool, error) {
+		return transactions.CreateTransaction(ctx, &model.CheckoutRequest{
+			Items:    []model.CheckoutItem{{ProductID: product.ID, Quantity: 1}},
+			Payments: []model.PaymentRequest{{Method: model.PaymentCash, Amount: model.NewMoney(8000)}},
+		}, cashierID, &model.IdempotencyKey{Key: key, RequestHash: hash, ExpiresAt: expiresAt}, model.TaxPolicy{Inclusive: true})
+	}
+	later := time.Now().Add(time.Hour)
+
+	first, replayed, err := checkout(alice, "key-1", "hash-a", later)
+	if err != nil || replayed {
+		t.Fatalf("first checkout: replayed %v, err %v", replayed, err)
+	}
+
+	again, replayed, err := checkout(alice, "key-1", "hash-a", later)
+	if err != nil || !replayed || again.ID != first.ID {
+		t.Errorf("retry: want replay of %d, got %+v, replayed %v, err %v", first.ID, again, replayed, err)
+	}
+
+	if _, _, err := checkout(alice, "key-1", "hash-b", later); !errors.Is(err, ErrIdempotencyKeyReused) {
+		t.Errorf("different payload: want ErrIdempotencyKeyReused, got %v", err)
+	}
+
+	for name, cashierID := range map[string]*int{"other cashier": bob, "no cashier": nil} {
+		other, replayed, err := checkout(cashierID, "key-1", "hash-a", later)
+		if err != nil || replayed || other.ID == first.ID {
+			t.Errorf("%s: want a new sale, got %+v, replayed %v, err %v", name, other, replayed, err)
+		}
+	}
+
+	// expired keys are free to reuse and are swept by later checkouts
+	if _, _, err := checkout(alice, "key-2", "hash-a", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("checkout with expired key: %v", err)
+	}
+	if _, _, err := checkout(bob, "key-3", "hash-a", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("checkout with expired key: %v", err)
+	}
+	reused, replayed, err := checkout(alice, "key-2", "hash-b", later)
+	if err != nil || replayed {
+		t.Fatalf("reuse of expired key: replayed %v, err %v", replayed, err)
+	}
+	var expired int
+	if _, err := builder.From("idempotency_keys").Select(goqu.COUNT("*")).Where(goqu.I("expires_at").Lte(time.Now())).ScanValContext(ctx, &expired); err != nil {
+		t.Fatal(err)
+	}
+	if expired != 0 {
+		t.Errorf("want expired keys swept, %d left", expired)
+	}
+	if reused.CashierID == nil || *reused.CashierID != *alice {
+		t.Errorf("want cashier %d, got %v", *alice, reused.CashierID)
+	}
+}
+
+// TestGetReport mirrors the memory store's report test: two sales on one
+// day and part of one refunded the next, reported on ranges with sales,
+// with only the refund and with neither.
+func TestGetReport(t *testing.T) {
+	conn, builder := testdb.Open(t)
+	ctx := context.Background()
+	categories := NewCategoryRepository(conn, builder, testQueryTimeout)
+	products := NewProductRepository(conn, builder, testQueryTimeout)
+	transactions := NewTransactionRepository(conn, builder, testQueryTimeout)
+
+	drinks := &model.Category{Name: "Minuman"}
+	food := &model.Category{Name: "Makanan"}
+	for _, category := range []*model.Category{drinks, food} {
+		if err := categories.Create(ctx, category); err != nil {
+			t.Fatalf("create category: %v", err)
+		}
+	}
+	kopi := &dto.ProductRequest{Name: "Kopi", Price: model.NewMoney(9000), Stock: 10, Categories: []int{drinks.ID}}
+	teh := &dto.ProductRequest{Name: "Teh", Price: model.NewMoney(5000), Stock: 10, Categories: []int{drinks.ID}}
+	roti := &dto.ProductRequest{Name: "Roti", Price: model.NewMoney(4000), Stock: 10, Categories: []int{food.ID}}
+	for _, product := range []*dto.ProductRequest{kopi, teh, roti} {
+		if err := products.Create(ctx, product); err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+	}
+
+	day := func(offset int) time.Time { return time.Date(2026, 3, 8+offset, 0, 0, 0, 0, time.UTC) }
+	var sales []*model.Transaction
+	for _, items := range [][]model.CheckoutItem{
+		{{ProductID: kopi.ID, Quantity: 2}, {ProductID: teh.ID, Quantity: 1}},
+		{{ProductID: roti.ID, Quantity: 3}, {ProductID: kopi.ID, Quantity: 1}},
+	} {
+		sale, _, err := transactions.CreateTransaction(ctx, &model.CheckoutRequest{
+			Items:    items,
+			Payments: []model.PaymentRequest{{Method: model.PaymentCash, Amount: model.NewMoney(50000)}},
+		}, nil, nil, model.TaxPolicy{Inclusive: true})
+		if err != nil {
+			t.Fatalf("CreateTransaction: %v", err)
+		}
+		sales = append(sales, sale)
+	}
+	var rotiDetail int
+	for _, detail := range sales[1].Details {
+		if detail.ProductID == roti.ID {
+			rotiDetail = detail.ID
+		}
+	}
+	refund, err := transactions.CreateRefund(ctx, sales[1].ID, model.RefundTypeRefund, &model.RefundRequest{
+		Items: []model.RefundItem{{TransactionDetailID: rotiDetail, Quantity: 1}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("CreateRefund: %v", err)
+	}
+
+	// the sales were rung up on the first day, the refund on the next
+	if _, err := conn.ExecContext(ctx, `UPDATE transactions SET created_at = $1`, day(0).Add(10*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ExecContext(ctx, `UPDATE refunds SET created_at = $1 WHERE id = $2`, day(1).Add(10*time.Hour), refund.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name                string
+		from, to            time.Time
+		transactions, items int
+		gross, refunds, net int64
+		// top is the expected best sellers by quantity, ties broken by id
+		top        []int
+		categories map[int]int64
+	}{
+		{
+			name: "sales day", from: day(0), to: day(1),
+			transactions: 2, items: 7,
+			gross: 44000, net: 44000,
+			top:        []int{kopi.ID, roti.ID},
+			categories: map[int]int64{drinks.ID: 32000, food.ID: 12000},
+		},
+		{
+			name: "only a refund", from: day(1), to: day(2),
+			refunds: 4000, net: -4000,
+		},
+		{
+			name: "no sales", from: day(2), to: day(3),
+		},
+		{
+			name: "both days", from: day(0), to: day(2),
+			transactions: 2, items: 7,
+			gross: 44000, refunds: 4000, net: 40000,
+			top:        []int{kopi.ID, roti.ID},
+			categories: map[int]int64{drinks.ID: 32000, food.ID: 12000},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := transactions.GetReport(ctx, &model.ReportFilter{StartDate: tt.from, EndDate: tt.to, Top: 2})
+			if err != nil {
+				t.Fatalf("GetReport: %v", err)
+			}
+			if report.TotalTransaks != tt.transactions || report.ItemsSold != tt.items {
+				t.Errorf("want %d transactions and %d items, got %d and %d", tt.transactions, tt.items, report.TotalTransaks, report.ItemsSold)
+			}
+			if report.GrossSales.Amount != tt.gross || report.TotalRefunds.Amount != tt.refunds || report.NetRevenue.Amount != tt.net {
+				t.Errorf("want gross %d, refunds %d and net %d, got %d, %d and %d",
+					tt.gross, tt.refunds, tt.net, report.GrossSales.Amount, report.TotalRefunds.Amount, report.NetRevenue.Amount)
+			}
+
+			if len(report.TopByQuantity) != len(tt.top) || len(report.TopByRevenue) != len(tt.top) {
+				t.Fatalf("want %d best sellers, got %+v and %+v", len(tt.top), report.TopByQuantity, report.TopByRevenue)
+			}
+			for i, productID := range tt.top {
+				if report.TopByQuantity[i].ProductID != productID {
+					t.Errorf("best seller %d: want product %d, got %+v", i, productID, report.TopByQuantity[i])
+				}
+			}
+
+			if len(report.Categories) != len(tt.categories) {
+				t.Fatalf("want %d categories, got %+v", len(tt.categories), report.Categories)
+			}
+			for _, row := range report.Categories {
+				if row.CategoryID == nil || row.Revenue.Amount != tt.categories[*row.CategoryID] {
+					t.Errorf("want category revenue %v, got %+v", tt.categories, row)
+				}
+			}
+			if len(report.Payments) != len(model.PaymentMethods) {
+				t.Errorf("want a row per payment method, got %+v", report.Payments)
+			}
+		})
+	}
+}
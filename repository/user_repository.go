@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"category-crud/apperror"
+	"category-crud/model"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrUserNotFound  = apperror.NotFound("pengguna tidak ditemukan")
+	ErrUsernameTaken = apperror.Conflict("username sudah dipakai", nil)
+	// ErrUserHasTransactions keeps sales history attributable; deactivate
+	// the user instead.
+	ErrUserHasTransactions = apperror.Conflict("pengguna masih memiliki transaksi, nonaktifkan saja", nil)
+)
+
+var userColumns = []interface{}{"id", "username", "name", "role", "active", "password_hash", "created_at", "updated_at"}
+
+type userRepository struct {
+	db      *sql.DB
+	builder *goqu.Database
+	timeout time.Duration
+}
+
+func NewUserRepository(db *sql.DB, builder *goqu.Database, timeout time.Duration) UserRepository {
+	return &userRepository{
+		db:      db,
+		builder: builder,
+		timeout: timeout,
+	}
+}
+
+func (repo *userRepository) GetAll(ctx context.Context) ([]model.User, error) {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	users := []model.User{}
+	err := repo.builder.From("users").
+		Select(userColumns...).
+		Order(goqu.I("id").Asc()).
+		ScanStructsContext(ctx, &users)
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (repo *userRepository) Create(ctx context.Context, user *model.User) error {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	_, err := repo.builder.Insert("users").Rows(
+		goqu.Record{
+			"username":      user.Username,
+			"name":          user.Name,
+			"role":          user.Role,
+			"active":        user.Active,
+			"password_hash": user.PasswordHash,
+		},
+	).Returning(userColumns...).Executor().ScanStructContext(ctx, user)
+	return translateUserError(err)
+}
+
+func (repo *userRepository) GetByID(ctx context.Context, id int) (*model.User, error) {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	return repo.find(ctx, goqu.Ex{"id": id})
+}
+
+func (repo *userRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	return repo.find(ctx, goqu.Ex{"username": username})
+}
+
+func (repo *userRepository) find(ctx context.Context, where goqu.Ex) (*model.User, error) {
+	var user model.User
+	found, err := repo.builder.From("users").
+		Select(userColumns...).
+		Where(where).
+		ScanStructContext(ctx, &user)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrUserNotFound
+	}
+
+	return &user, nil
+}
+
+func (repo *userRepository) Update(ctx context.Context, user *model.User) error {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	record := goqu.Record{
+		"username":   user.Username,
+		"name":       user.Name,
+		"role":       user.Role,
+		"active":     user.Active,
+		"updated_at": time.Now(),
+	}
+	if user.PasswordHash != "" {
+		record["password_hash"] = user.PasswordHash
+	}
+
+	found, err := repo.builder.Update("users").
+		Set(record).
+		Where(goqu.Ex{"id": user.ID}).
+		Returning(userColumns...).
+		Executor().ScanStructContext(ctx, user)
+	if err != nil {
+		return translateUserError(err)
+	}
+	if !found {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (repo *userRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := withQueryTimeout(ctx, repo.timeout)
+	defer cancel()
+
+	result, err := repo.builder.Delete("users").Where(goqu.Ex{
+		"id": id,
+	}).Executor().ExecContext(ctx)
+	if err != nil {
+		return translateUserError(err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func translateUserError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "23503": // foreign_key_violation from transactions.cashier_id or shifts.cashier_id
+			return ErrUserHasTransactions
+		case "23505": // unique_violation on username
+			return ErrUsernameTaken
+		}
+	}
+	return translateError(err)
+}
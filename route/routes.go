@@ -1,6 +1,7 @@
 package route
 
 import (
+	"category-crud/auth"
 	"category-crud/handler"
 	"net/http"
 
@@ -11,33 +12,97 @@ import (
 // SetupRoutes configures all API routes
 func Configure(handlerGroup *handler.HandlerGroup) *mux.Router {
 	r := mux.NewRouter()
+	middlewares := []mux.MiddlewareFunc{handler.RequestID, handler.AccessLog}
+	if handlerGroup.Metrics != nil {
+		middlewares = append(middlewares, handlerGroup.Metrics.Instrument)
+	}
+	r.Use(middlewares...)
+	// mux skips middleware for unmatched requests, so wrap these by hand
+	r.NotFoundHandler = chain(http.HandlerFunc(handler.NotFound), middlewares)
+	r.MethodNotAllowedHandler = chain(http.HandlerFunc(handler.MethodNotAllowed), middlewares)
 
 	// Root route - redirect to Swagger
 	r.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "/swagger/index.html", http.StatusMovedPermanently)
 	}).Methods("GET")
 
+	// Health endpoints
+	r.HandleFunc("/healthz", handlerGroup.Health.Live).Methods("GET")
+	r.HandleFunc("/readyz", handlerGroup.Health.Ready).Methods("GET")
+	if handlerGroup.Metrics != nil {
+		r.HandleFunc("/metrics", handlerGroup.Metrics.Serve).Methods("GET")
+	}
+
+	// Role based access: cashiers may only check out, read products and
+	// open and close their shift
+	anyRole := handlerGroup.Auth.Require(auth.RoleAdmin, auth.RoleManager, auth.RoleCashier)
+	staff := handlerGroup.Auth.Require(auth.RoleAdmin, auth.RoleManager)
+	admin := handlerGroup.Auth.Require(auth.RoleAdmin)
+
+	// Auth and user endpoints
+	r.HandleFunc("/api/auth/login", handlerGroup.User.Login).Methods("POST")
+	r.HandleFunc("/api/users", admin(handlerGroup.User.Create)).Methods("POST")
+	r.HandleFunc("/api/users", admin(handlerGroup.User.GetAll)).Methods("GET")
+	r.HandleFunc("/api/users/{id}", admin(handlerGroup.User.GetByID)).Methods("GET")
+	r.HandleFunc("/api/users/{id}", admin(handlerGroup.User.Update)).Methods("PUT")
+	r.HandleFunc("/api/users/{id}", admin(handlerGroup.User.Delete)).Methods("DELETE")
+
 	// Category endpoints
-	r.HandleFunc("/api/categories", handlerGroup.Category.Create).Methods("POST")
-	r.HandleFunc("/api/categories", handlerGroup.Category.GetAll).Methods("GET")
-	r.HandleFunc("/api/categories/{id}", handlerGroup.Category.GetByID).Methods("GET")
-	r.HandleFunc("/api/categories/{id}", handlerGroup.Category.Update).Methods("PUT")
-	r.HandleFunc("/api/categories/{id}", handlerGroup.Category.Delete).Methods("DELETE")
+	r.HandleFunc("/api/categories", staff(handlerGroup.Category.Create)).Methods("POST")
+	r.HandleFunc("/api/categories", staff(handlerGroup.Category.GetAll)).Methods("GET")
+	r.HandleFunc("/api/categories/{id}", staff(handlerGroup.Category.GetByID)).Methods("GET")
+	r.HandleFunc("/api/categories/{id}", staff(handlerGroup.Category.Update)).Methods("PUT")
+	r.HandleFunc("/api/categories/{id}", admin(handlerGroup.Category.Delete)).Methods("DELETE")
+	r.HandleFunc("/api/categories/{id}/restore", admin(handlerGroup.Category.Restore)).Methods("POST")
 
 	// Product endpoints
-	r.HandleFunc("/api/products", handlerGroup.Product.Create).Methods("POST")
-	r.HandleFunc("/api/products", handlerGroup.Product.GetAll).Methods("GET")
-	r.HandleFunc("/api/products/{id}", handlerGroup.Product.GetByID).Methods("GET")
-	r.HandleFunc("/api/products/{id}", handlerGroup.Product.Update).Methods("PUT")
-	r.HandleFunc("/api/products/{id}", handlerGroup.Product.Delete).Methods("DELETE")
+	r.HandleFunc("/api/products", staff(handlerGroup.Product.Create)).Methods("POST")
+	r.HandleFunc("/api/products", anyRole(handlerGroup.Product.GetAll)).Methods("GET")
+	r.HandleFunc("/api/products/{id}", anyRole(handlerGroup.Product.GetByID)).Methods("GET")
+	r.HandleFunc("/api/products/{id}", staff(handlerGroup.Product.Update)).Methods("PUT")
+	r.HandleFunc("/api/products/{id}", admin(handlerGroup.Product.Delete)).Methods("DELETE")
+	r.HandleFunc("/api/products/{id}/restore", admin(handlerGroup.Product.Restore)).Methods("POST")
+
+	// Promotion endpoints
+	r.HandleFunc("/api/promotions", staff(handlerGroup.Promotion.Create)).Methods("POST")
+	r.HandleFunc("/api/promotions", staff(handlerGroup.Promotion.GetAll)).Methods("GET")
+	r.HandleFunc("/api/promotions/{id}", staff(handlerGroup.Promotion.GetByID)).Methods("GET")
+	r.HandleFunc("/api/promotions/{id}", staff(handlerGroup.Promotion.Update)).Methods("PUT")
+	r.HandleFunc("/api/promotions/{id}", admin(handlerGroup.Promotion.Delete)).Methods("DELETE")
+
+	// Tax class endpoints
+	r.HandleFunc("/api/tax-classes", staff(handlerGroup.TaxClass.Create)).Methods("POST")
+	r.HandleFunc("/api/tax-classes", staff(handlerGroup.TaxClass.GetAll)).Methods("GET")
+	r.HandleFunc("/api/tax-classes/{id}", staff(handlerGroup.TaxClass.GetByID)).Methods("GET")
+	r.HandleFunc("/api/tax-classes/{id}", staff(handlerGroup.TaxClass.Update)).Methods("PUT")
+	r.HandleFunc("/api/tax-classes/{id}", admin(handlerGroup.TaxClass.Delete)).Methods("DELETE")
+
+	// Shift endpoints: cashiers open and close their own drawer
+	r.HandleFunc("/api/shifts", anyRole(handlerGroup.Shift.Open)).Methods("POST")
+	r.HandleFunc("/api/shifts", staff(handlerGroup.Shift.GetAll)).Methods("GET")
+	r.HandleFunc("/api/shifts/{id}", staff(handlerGroup.Shift.GetByID)).Methods("GET")
+	r.HandleFunc("/api/shifts/{id}/close", anyRole(handlerGroup.Shift.Close)).Methods("POST")
+	r.HandleFunc("/api/shifts/{id}/report", staff(handlerGroup.Shift.GetReport)).Methods("GET")
 
 	// Transaction endpoints
-	r.HandleFunc("/api/checkout", handlerGroup.Transaction.Checkout).Methods("POST")
-	r.HandleFunc("/api/report/hari-ini", handlerGroup.Transaction.GetReportToday).Methods("GET")
-	r.HandleFunc("/api/report", handlerGroup.Transaction.GetReport).Methods("GET")
+	r.HandleFunc("/api/checkout", anyRole(handlerGroup.Transaction.Checkout)).Methods("POST")
+	r.HandleFunc("/api/transactions", staff(handlerGroup.Transaction.GetAll)).Methods("GET")
+	r.HandleFunc("/api/transactions/{id}", staff(handlerGroup.Transaction.GetByID)).Methods("GET")
+	r.HandleFunc("/api/transactions/{id}/refund", staff(handlerGroup.Transaction.Refund)).Methods("POST")
+	r.HandleFunc("/api/transactions/{id}/void", staff(handlerGroup.Transaction.Void)).Methods("POST")
+	r.HandleFunc("/api/report/hari-ini", staff(handlerGroup.Transaction.GetReportToday)).Methods("GET")
+	r.HandleFunc("/api/report", staff(handlerGroup.Transaction.GetReport)).Methods("GET")
+	r.HandleFunc("/api/report/timeseries", staff(handlerGroup.Transaction.GetTimeseries)).Methods("GET")
 
 	// Swagger documentation
 	r.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 
 	return r
 }
+
+func chain(h http.Handler, middlewares []mux.MiddlewareFunc) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
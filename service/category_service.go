@@ -3,32 +3,68 @@ package service
 import (
 	"category-crud/model"
 	"category-crud/repository"
+	"category-crud/validation"
+	"context"
 )
 
 type CategoryService struct {
-	repo *repository.CategoryRepository
+	repo         repository.CategoryRepository
+	taxClassRepo repository.TaxClassRepository
 }
 
-func NewCategoryService(repo *repository.CategoryRepository) *CategoryService {
-	return &CategoryService{repo: repo}
+func NewCategoryService(repo repository.CategoryRepository, taxClassRepo repository.TaxClassRepository) *CategoryService {
+	return &CategoryService{repo: repo, taxClassRepo: taxClassRepo}
 }
 
-func (s *CategoryService) GetAll() ([]model.Category, error) {
-	return s.repo.GetAll()
+func (s *CategoryService) GetAll(ctx context.Context, includeDeleted bool) ([]model.Category, error) {
+	return s.repo.GetAll(ctx, includeDeleted)
 }
 
-func (s *CategoryService) Create(data *model.Category) error {
-	return s.repo.Create(data)
+func (s *CategoryService) Create(ctx context.Context, data *model.Category) error {
+	if err := s.validate(ctx, data); err != nil {
+		return err
+	}
+	// deleted_at is only set through Delete and Restore
+	data.DeletedAt = nil
+	return s.repo.Create(ctx, data)
 }
 
-func (s *CategoryService) GetByID(id int) (*model.Category, error) {
-	return s.repo.GetByID(id)
+func (s *CategoryService) GetByID(ctx context.Context, id int, includeDeleted bool) (*model.Category, error) {
+	return s.repo.GetByID(ctx, id, includeDeleted)
 }
 
-func (s *CategoryService) Update(product *model.Category) error {
-	return s.repo.Update(product)
+func (s *CategoryService) Update(ctx context.Context, product *model.Category) error {
+	if err := s.validate(ctx, product); err != nil {
+		return err
+	}
+	product.DeletedAt = nil
+	return s.repo.Update(ctx, product)
 }
 
-func (s *CategoryService) Delete(id int) error {
-	return s.repo.Delete(id)
+// validate checks the request rules and that the tax class exists.
+func (s *CategoryService) validate(ctx context.Context, category *model.Category) error {
+	if err := validation.Struct(category); err != nil {
+		return err
+	}
+
+	missing, err := checkTaxClass(ctx, s.taxClassRepo, category.TaxClassID)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return validation.Failed(missing...)
+	}
+
+	return nil
+}
+
+func (s *CategoryService) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *CategoryService) Restore(ctx context.Context, id int) (*model.Category, error) {
+	if err := s.repo.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id, false)
 }
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"category-crud/apperror"
+	"category-crud/model"
+	"context"
+	"testing"
+)
+
+func TestCategoryServiceCRUD(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(t)
+
+	category := &model.Category{Name: "Minuman", Description: "Dingin"}
+	if err := s.category.Create(ctx, category); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if category.ID == 0 {
+		t.Fatal("Create did not assign an id")
+	}
+
+	got, err := s.category.GetByID(ctx, category.ID, false)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Name != "Minuman" || got.Description != "Dingin" {
+		t.Errorf("GetByID: got %+v", got)
+	}
+
+	category.Name = "Minuman Dingin"
+	if err := s.category.Update(ctx, category); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	got, err = s.category.GetByID(ctx, category.ID, false)
+	if err != nil {
+		t.Fatalf("GetByID after update: %v", err)
+	}
+	if got.Name != "Minuman Dingin" {
+		t.Errorf("Update: want name %q, got %q", "Minuman Dingin", got.Name)
+	}
+
+	_, err = s.category.GetByID(ctx, category.ID+1, false)
+	assertCode(t, err, apperror.CodeNotFound)
+}
+
+func TestCategoryServiceValidation(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(t)
+
+	assertCode(t, s.category.Create(ctx, &model.Category{Name: "  "}), apperror.CodeValidation)
+
+	taxClassID := 42
+	err := s.category.Create(ctx, &model.Category{Name: "Rokok", TaxClassID: &taxClassID})
+	assertCode(t, err, apperror.CodeValidation)
+
+	categories, err := s.category.GetAll(ctx, true)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(categories) != 0 {
+		t.Errorf("rejected categories were stored: %+v", categories)
+	}
+}
+
+func TestCategoryServiceSoftDelete(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(t)
+
+	kept := &model.Category{Name: "Makanan"}
+	deleted := &model.Category{Name: "Minuman"}
+	for _, category := range []*model.Category{kept, deleted} {
+		if err := s.category.Create(ctx, category); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	if err := s.category.Delete(ctx, deleted.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	assertCode(t, s.category.Delete(ctx, deleted.ID), apperror.CodeNotFound)
+
+	_, err := s.category.GetByID(ctx, deleted.ID, false)
+	assertCode(t, err, apperror.CodeNotFound)
+	got, err := s.category.GetByID(ctx, deleted.ID, true)
+	if err != nil {
+		t.Fatalf("GetByID including deleted: %v", err)
+	}
+	if got.DeletedAt == nil {
+		t.Error("deleted category has no deleted_at")
+	}
+
+	live, err := s.category.GetAll(ctx, false)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(live) != 1 || live[0].ID != kept.ID {
+		t.Errorf("GetAll: want only category %d, got %+v", kept.ID, live)
+	}
+	all, err := s.category.GetAll(ctx, true)
+	if err != nil {
+		t.Fatalf("GetAll including deleted: %v", err)
+	}
+	if len(all) != 2 {
+		t.Errorf("GetAll including deleted: want 2 categories, got %d", len(all))
+	}
+
+	deleted.Name = "Minuman Baru"
+	assertCode(t, s.category.Update(ctx, deleted), apperror.CodeNotFound)
+
+	restored, err := s.category.Restore(ctx, deleted.ID)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if restored.DeletedAt != nil || restored.Name != "Minuman" {
+		t.Errorf("Restore: got %+v", restored)
+	}
+}
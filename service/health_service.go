@@ -0,0 +1,67 @@
+package service
+
+import (
+	"category-crud/model"
+	"category-crud/repository"
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+const readinessTimeout = 2 * time.Second
+
+type HealthService struct {
+	repo         repository.HealthRepository
+	shuttingDown atomic.Bool
+}
+
+func NewHealthService(repo repository.HealthRepository) *HealthService {
+	return &HealthService{repo: repo}
+}
+
+// ShuttingDown makes readiness fail so load balancers stop routing new
+// requests while in-flight ones drain.
+func (s *HealthService) ShuttingDown() {
+	s.shuttingDown.Store(true)
+}
+
+// Readiness pings the database and checks that every embedded migration
+// has been applied. ready is false if any check fails.
+func (s *HealthService) Readiness(ctx context.Context) (readiness *model.Readiness, ready bool) {
+	readiness = &model.Readiness{
+		Status:   model.ReadinessReady,
+		Database: model.HealthCheck{Status: model.HealthStatusOK},
+	}
+	ready = true
+
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	if err := s.repo.Ping(ctx); err != nil {
+		readiness.Database = model.HealthCheck{Status: model.HealthStatusFail, Error: err.Error()}
+		ready = false
+	} else {
+		migrations, err := s.repo.Migrations(ctx)
+		switch {
+		case err != nil:
+			migrations = &model.MigrationState{Status: model.HealthStatusFail, Error: err.Error()}
+			ready = false
+		case migrations != nil && migrations.Pending > 0:
+			migrations.Status = model.HealthStatusFail
+			ready = false
+		case migrations != nil:
+			migrations.Status = model.HealthStatusOK
+		}
+		readiness.Migrations = migrations
+	}
+
+	if !ready {
+		readiness.Status = model.ReadinessNotReady
+	}
+	if s.shuttingDown.Load() {
+		readiness.Status = model.ReadinessShuttingDown
+		ready = false
+	}
+
+	return readiness, ready
+}
@@ -4,32 +4,91 @@ import (
 	"category-crud/model"
 	"category-crud/model/dto"
 	"category-crud/repository"
+	"category-crud/validation"
+	"context"
+	"errors"
+	"fmt"
 )
 
 type ProductService struct {
-	repo *repository.ProductRepository
+	repo         repository.ProductRepository
+	categoryRepo repository.CategoryRepository
+	taxClassRepo repository.TaxClassRepository
 }
 
-func NewProductService(repo *repository.ProductRepository) *ProductService {
-	return &ProductService{repo: repo}
+func NewProductService(repo repository.ProductRepository, categoryRepo repository.CategoryRepository, taxClassRepo repository.TaxClassRepository) *ProductService {
+	return &ProductService{repo: repo, categoryRepo: categoryRepo, taxClassRepo: taxClassRepo}
 }
 
-func (s *ProductService) GetAll(filter *dto.ProductFilterRequest) ([]model.Product, error) {
-	return s.repo.GetAll(filter)
+func (s *ProductService) GetAll(ctx context.Context, filter *dto.ProductFilterRequest) (*dto.ProductPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = dto.DefaultProductLimit
+	}
+	if filter.Limit > dto.MaxProductLimit {
+		filter.Limit = dto.MaxProductLimit
+	}
+	return s.repo.GetAll(ctx, filter)
 }
 
-func (s *ProductService) Create(data *dto.ProductRequest) error {
-	return s.repo.Create(data)
+func (s *ProductService) Create(ctx context.Context, data *dto.ProductRequest) error {
+	if err := s.validate(ctx, data); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, data)
 }
 
-func (s *ProductService) GetByID(id int) (*model.Product, error) {
-	return s.repo.GetByID(id)
+func (s *ProductService) GetByID(ctx context.Context, id int, includeDeleted bool) (*model.Product, error) {
+	return s.repo.GetByID(ctx, id, includeDeleted)
 }
 
-func (s *ProductService) Update(product *dto.ProductRequest) error {
-	return s.repo.Update(product)
+func (s *ProductService) Update(ctx context.Context, product *dto.ProductRequest) error {
+	if err := s.validate(ctx, product); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, product)
 }
 
-func (s *ProductService) Delete(id int) error {
-	return s.repo.Delete(id)
+// validate checks the request rules, that every referenced category
+// exists and is not deleted, and that the tax class exists.
+func (s *ProductService) validate(ctx context.Context, product *dto.ProductRequest) error {
+	if err := validation.Struct(product); err != nil {
+		return err
+	}
+
+	var missing []validation.FieldError
+	for i, categoryID := range product.Categories {
+		_, err := s.categoryRepo.GetByID(ctx, categoryID, false)
+		if errors.Is(err, repository.ErrCategoryNotFound) {
+			missing = append(missing, validation.FieldError{
+				Field:   fmt.Sprintf("categories[%d]", i),
+				Rule:    "exists",
+				Message: fmt.Sprintf("category %d does not exist", categoryID),
+			})
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	taxClass, err := checkTaxClass(ctx, s.taxClassRepo, product.TaxClassID)
+	if err != nil {
+		return err
+	}
+	missing = append(missing, taxClass...)
+	if len(missing) > 0 {
+		return validation.Failed(missing...)
+	}
+
+	return nil
+}
+
+func (s *ProductService) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *ProductService) Restore(ctx context.Context, id int) (*model.Product, error) {
+	if err := s.repo.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id, false)
 }
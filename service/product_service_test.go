@@ -0,0 +1,157 @@
+package service
+
+import (
+	"category-crud/apperror"
+	"category-crud/model"
+	"category-crud/model/dto"
+	"context"
+	"testing"
+)
+
+func TestProductServiceCRUD(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(t)
+
+	category := &model.Category{Name: "Minuman"}
+	if err := s.category.Create(ctx, category); err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+	product := s.createProduct(t, "Teh Botol", 5000, 10, category.ID)
+
+	got, err := s.product.GetByID(ctx, product.ID, false)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Name != "Teh Botol" || got.Price.Amount != 5000 || got.Stock != 10 {
+		t.Errorf("GetByID: got %+v", got)
+	}
+	if len(got.Categories) != 1 || got.Categories[0].ID != category.ID {
+		t.Errorf("GetByID: want category %d, got %+v", category.ID, got.Categories)
+	}
+
+	product.Price = model.NewMoney(5500)
+	product.Categories = nil
+	if err := s.product.Update(ctx, product); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	got, err = s.product.GetByID(ctx, product.ID, false)
+	if err != nil {
+		t.Fatalf("GetByID after update: %v", err)
+	}
+	if got.Price.Amount != 5500 || len(got.Categories) != 0 {
+		t.Errorf("Update: got %+v", got)
+	}
+
+	page, err := s.product.GetAll(ctx, &dto.ProductFilterRequest{})
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if page.Total != 1 || page.Limit != dto.DefaultProductLimit {
+		t.Errorf("GetAll: want 1 product with the default limit, got total %d limit %d", page.Total, page.Limit)
+	}
+}
+
+func TestProductServiceValidation(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(t)
+
+	tests := []struct {
+		name    string
+		product dto.ProductRequest
+	}{
+		{"blank name", dto.ProductRequest{Name: " ", Price: model.NewMoney(1000)}},
+		{"negative price", dto.ProductRequest{Name: "Kopi", Price: model.NewMoney(-1)}},
+		{"negative stock", dto.ProductRequest{Name: "Kopi", Price: model.NewMoney(1000), Stock: -1}},
+		{"unknown category", dto.ProductRequest{Name: "Kopi", Price: model.NewMoney(1000), Categories: []int{7}}},
+		{"duplicate category", dto.ProductRequest{Name: "Kopi", Price: model.NewMoney(1000), Categories: []int{1, 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertCode(t, s.product.Create(ctx, &tt.product), apperror.CodeValidation)
+		})
+	}
+
+	page, err := s.product.GetAll(ctx, &dto.ProductFilterRequest{IncludeDeleted: true})
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if page.Total != 0 {
+		t.Errorf("rejected products were stored: %+v", page.Data)
+	}
+}
+
+func TestProductServiceDeletedCategory(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(t)
+
+	category := &model.Category{Name: "Musiman"}
+	if err := s.category.Create(ctx, category); err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+	product := s.createProduct(t, "Kue Lebaran", 25000, 3, category.ID)
+	if err := s.category.Delete(ctx, category.ID); err != nil {
+		t.Fatalf("delete category: %v", err)
+	}
+
+	got, err := s.product.GetByID(ctx, product.ID, false)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if len(got.Categories) != 0 {
+		t.Errorf("deleted category still listed: %+v", got.Categories)
+	}
+
+	err = s.product.Create(ctx, &dto.ProductRequest{Name: "Ketupat", Price: model.NewMoney(5000), Categories: []int{category.ID}})
+	assertCode(t, err, apperror.CodeValidation)
+}
+
+func TestProductServiceSoftDelete(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(t)
+
+	kept := s.createProduct(t, "Kopi", 8000, 5)
+	deleted := s.createProduct(t, "Teh", 5000, 5)
+
+	if err := s.product.Delete(ctx, deleted.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	assertCode(t, s.product.Delete(ctx, deleted.ID), apperror.CodeNotFound)
+
+	_, err := s.product.GetByID(ctx, deleted.ID, false)
+	assertCode(t, err, apperror.CodeNotFound)
+	if _, err := s.product.GetByID(ctx, deleted.ID, true); err != nil {
+		t.Fatalf("GetByID including deleted: %v", err)
+	}
+
+	page, err := s.product.GetAll(ctx, &dto.ProductFilterRequest{})
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if page.Total != 1 || page.Data[0].ID != kept.ID {
+		t.Errorf("GetAll: want only product %d, got %+v", kept.ID, page.Data)
+	}
+	page, err = s.product.GetAll(ctx, &dto.ProductFilterRequest{IncludeDeleted: true})
+	if err != nil {
+		t.Fatalf("GetAll including deleted: %v", err)
+	}
+	if page.Total != 2 {
+		t.Errorf("GetAll including deleted: want 2 products, got %d", page.Total)
+	}
+
+	deleted.Stock = 100
+	assertCode(t, s.product.Update(ctx, deleted), apperror.CodeNotFound)
+
+	_, _, err = s.transaction.Checkout(ctx, cashCheckout(5000, model.CheckoutItem{ProductID: deleted.ID, Quantity: 1}), nil, "")
+	assertCode(t, err, apperror.CodeUnprocessable)
+
+	restored, err := s.product.Restore(ctx, deleted.ID)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if restored.DeletedAt != nil || restored.Stock != 5 {
+		t.Errorf("Restore: got %+v", restored)
+	}
+	if _, _, err := s.transaction.Checkout(ctx, cashCheckout(5000, model.CheckoutItem{ProductID: deleted.ID, Quantity: 1}), nil, ""); err != nil {
+		t.Fatalf("checkout of restored product: %v", err)
+	}
+}
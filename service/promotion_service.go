@@ -0,0 +1,154 @@
+package service
+
+import (
+	"category-crud/model"
+	"category-crud/model/dto"
+	"category-crud/repository"
+	"category-crud/validation"
+	"context"
+	"errors"
+	"fmt"
+)
+
+type PromotionService struct {
+	repo         repository.PromotionRepository
+	productRepo  repository.ProductRepository
+	categoryRepo repository.CategoryRepository
+}
+
+func NewPromotionService(repo repository.PromotionRepository, productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository) *PromotionService {
+	return &PromotionService{repo: repo, productRepo: productRepo, categoryRepo: categoryRepo}
+}
+
+func (s *PromotionService) GetAll(ctx context.Context) ([]model.Promotion, error) {
+	return s.repo.GetAll(ctx)
+}
+
+func (s *PromotionService) GetByID(ctx context.Context, id int) (*model.Promotion, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *PromotionService) Create(ctx context.Context, req *dto.PromotionRequest) (*model.Promotion, error) {
+	if err := s.validate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	promotion := &model.Promotion{Active: true}
+	applyPromotionRequest(promotion, req)
+	if err := s.repo.Create(ctx, promotion); err != nil {
+		return nil, err
+	}
+
+	return promotion, nil
+}
+
+// Update replaces a promotion. The active flag is only changed when given.
+func (s *PromotionService) Update(ctx context.Context, req *dto.PromotionRequest) (*model.Promotion, error) {
+	if err := s.validate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	promotion, err := s.repo.GetByID(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	applyPromotionRequest(promotion, req)
+	if err := s.repo.Update(ctx, promotion); err != nil {
+		return nil, err
+	}
+
+	return promotion, nil
+}
+
+func (s *PromotionService) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func applyPromotionRequest(promotion *model.Promotion, req *dto.PromotionRequest) {
+	promotion.Name = req.Name
+	promotion.Type = req.Type
+	promotion.Value = req.Value
+	promotion.ProductID = req.ProductID
+	promotion.CategoryID = req.CategoryID
+	promotion.BuyQuantity = req.BuyQuantity
+	promotion.GetQuantity = req.GetQuantity
+	promotion.MinSubtotal = req.MinSubtotal
+	promotion.StartsAt = req.StartsAt
+	promotion.EndsAt = req.EndsAt
+	if req.Active != nil {
+		promotion.Active = *req.Active
+	}
+}
+
+// validate checks the request rules, the fields each promotion type
+// needs, and that the targeted product or category exists and is not
+// deleted.
+func (s *PromotionService) validate(ctx context.Context, req *dto.PromotionRequest) error {
+	if err := validation.Struct(req); err != nil {
+		return err
+	}
+
+	var invalid []validation.FieldError
+	fail := func(field, rule, message string) {
+		invalid = append(invalid, validation.FieldError{Field: field, Rule: rule, Message: message})
+	}
+
+	switch req.Type {
+	case model.PromotionPercentage:
+		if req.Value < 1 || req.Value > 100 {
+			fail("value", "range", "must be between 1 and 100")
+		}
+	case model.PromotionFixed, model.PromotionBasket:
+		if req.Value < 1 {
+			fail("value", "gt", "must be greater than 0")
+		}
+	case model.PromotionBuyXGetY:
+		if req.Value != 0 {
+			fail("value", "excluded", "must be 0 for buy_x_get_y promotions")
+		}
+		if req.BuyQuantity < 1 {
+			fail("buy_quantity", "gt", "must be greater than 0")
+		}
+		if req.GetQuantity < 1 {
+			fail("get_quantity", "gt", "must be greater than 0")
+		}
+	}
+	if req.Type != model.PromotionBuyXGetY {
+		if req.BuyQuantity != 0 {
+			fail("buy_quantity", "excluded", "must be 0 unless type is buy_x_get_y")
+		}
+		if req.GetQuantity != 0 {
+			fail("get_quantity", "excluded", "must be 0 unless type is buy_x_get_y")
+		}
+	}
+
+	if req.ProductID != nil && req.CategoryID != nil {
+		fail("category_id", "excluded_with", "must not be set together with product_id")
+	}
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		fail("ends_at", "gtfield", "must be after starts_at")
+	}
+
+	if req.ProductID != nil {
+		_, err := s.productRepo.GetByID(ctx, *req.ProductID, false)
+		if errors.Is(err, repository.ErrProductNotFound) {
+			fail("product_id", "exists", fmt.Sprintf("product %d does not exist", *req.ProductID))
+		} else if err != nil {
+			return err
+		}
+	}
+	if req.CategoryID != nil {
+		_, err := s.categoryRepo.GetByID(ctx, *req.CategoryID, false)
+		if errors.Is(err, repository.ErrCategoryNotFound) {
+			fail("category_id", "exists", fmt.Sprintf("category %d does not exist", *req.CategoryID))
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if len(invalid) > 0 {
+		return validation.Failed(invalid...)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"category-crud/apperror"
+	"category-crud/model"
+	"category-crud/model/dto"
+	"category-crud/repository/memory"
+	"category-crud/validation"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// testServices wires the services to one in-memory store, the way boot
+// does for the memory driver.
+type testServices struct {
+	store       *memory.Store
+	category    *CategoryService
+	product     *ProductService
+	transaction *TransactionService
+	shift       *ShiftService
+}
+
+func newTestServices(t *testing.T) *testServices {
+	t.Helper()
+
+	store := memory.NewStore()
+	categoryRepo := memory.NewCategoryRepository(store)
+	productRepo := memory.NewProductRepository(store)
+	taxClassRepo := memory.NewTaxClassRepository(store)
+	transactionRepo := memory.NewTransactionRepository(store)
+
+	return &testServices{
+		store:       store,
+		category:    NewCategoryService(categoryRepo, taxClassRepo),
+		product:     NewProductService(productRepo, categoryRepo, taxClassRepo),
+		transaction: NewTransactionService(transactionRepo, TransactionOptions{Tax: model.TaxPolicy{Inclusive: true}}),
+		shift:       NewShiftService(memory.NewShiftRepository(store), transactionRepo),
+	}
+}
+
+func (s *testServices) createUser(t *testing.T, username, role string) *model.User {
+	t.Helper()
+
+	user := &model.User{Username: username, Name: username, Role: role, Active: true}
+	if err := memory.NewUserRepository(s.store).Create(context.Background(), user); err != nil {
+		t.Fatalf("create user %s: %v", username, err)
+	}
+	return user
+}
+
+func (s *testServices) createProduct(t *testing.T, name string, price int64, stock int, categories ...int) *dto.ProductRequest {
+	t.Helper()
+
+	product := &dto.ProductRequest{
+		Name:       name,
+		Price:      model.NewMoney(price),
+		Stock:      stock,
+		Categories: categories,
+	}
+	if err := s.product.Create(context.Background(), product); err != nil {
+		t.Fatalf("create product %s: %v", name, err)
+	}
+	return product
+}
+
+// cashCheckout pays for items in cash, handing over tendered.
+func cashCheckout(tendered int64, items ...model.CheckoutItem) *model.CheckoutRequest {
+	return &model.CheckoutRequest{
+		Items: items,
+		Payments: []model.PaymentRequest{
+			{Method: model.PaymentCash, Amount: model.NewMoney(tendered)},
+		},
+	}
+}
+
+func assertCode(t *testing.T, err error, want apperror.Code) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("want %s error, got nil", want)
+	}
+	if got := apperror.CodeOf(err); got != want {
+		t.Fatalf("want %s error, got %s: %v", want, got, err)
+	}
+}
+
+// TestValidationDetails checks that a rejected request names the field,
+// the rule it broke and why, for each kind of request.
+func TestValidationDetails(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(t)
+	kopi := s.createProduct(t, "Kopi", 9000, 10)
+
+	tests := []struct {
+		name string
+		call func() error
+		want []validation.FieldError
+	}{
+		{
+			name: "category",
+			call: func() error { return s.category.Create(ctx, &model.Category{Name: "  "}) },
+			want: []validation.FieldError{{Field: "name", Rule: "notblank", Message: "must not be blank"}},
+		},
+		{
+			name: "product",
+			call: func() error {
+				return s.product.Create(ctx, &dto.ProductRequest{Name: "Teh", Price: model.NewMoney(-1), Stock: 1})
+			},
+			want: []validation.FieldError{{Field: "price", Rule: "gte", Message: "must be at least 0"}},
+		},
+		{
+			name: "checkout item",
+			call: func() error {
+				_, _, err := s.transaction.Checkout(ctx, cashCheckout(9000, model.CheckoutItem{ProductID: kopi.ID}), nil, "")
+				return err
+			},
+			want: []validation.FieldError{{Field: "items[0].quantity", Rule: "gt", Message: "must be greater than 0"}},
+		},
+		{
+			name: "checkout payment",
+			call: func() error {
+				_, _, err := s.transaction.Checkout(ctx, cashCheckout(0, model.CheckoutItem{ProductID: kopi.ID, Quantity: 1}), nil, "")
+				return err
+			},
+			want: []validation.FieldError{{Field: "payments[0].amount", Rule: "gt", Message: "must be greater than 0"}},
+		},
+		{
+			name: "opening a shift",
+			call: func() error {
+				_, err := s.shift.Open(ctx, &model.OpenShiftRequest{OpeningFloat: model.NewMoney(-1)}, nil)
+				return err
+			},
+			want: []validation.FieldError{{Field: "opening_float", Rule: "gte", Message: "must be at least 0"}},
+		},
+		{
+			name: "closing a shift",
+			call: func() error {
+				_, err := s.shift.Close(ctx, 1, &model.CloseShiftRequest{CountedCash: model.NewMoney(-500)}, nil, false)
+				return err
+			},
+			want: []validation.FieldError{{Field: "counted_cash", Rule: "gte", Message: "must be at least 0"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			var appErr *apperror.Error
+			if !errors.As(err, &appErr) || appErr.Code != apperror.CodeValidation {
+				t.Fatalf("want a validation error, got %v", err)
+			}
+			if !reflect.DeepEqual(appErr.Details, tt.want) {
+				t.Errorf("want details %+v, got %+v", tt.want, appErr.Details)
+			}
+		})
+	}
+}
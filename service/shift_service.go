@@ -0,0 +1,95 @@
+package service
+
+import (
+	"category-crud/apperror"
+	"category-crud/model"
+	"category-crud/model/dto"
+	"category-crud/repository"
+	"category-crud/validation"
+	"context"
+)
+
+var ErrShiftNotYours = apperror.Forbidden("shift belongs to another cashier")
+
+type ShiftService struct {
+	repo            repository.ShiftRepository
+	transactionRepo repository.TransactionRepository
+}
+
+func NewShiftService(repo repository.ShiftRepository, transactionRepo repository.TransactionRepository) *ShiftService {
+	return &ShiftService{repo: repo, transactionRepo: transactionRepo}
+}
+
+func (s *ShiftService) GetAll(ctx context.Context, filter *dto.ShiftFilterRequest) (*dto.ShiftPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = dto.DefaultShiftLimit
+	}
+	if filter.Limit > dto.MaxShiftLimit {
+		filter.Limit = dto.MaxShiftLimit
+	}
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	return s.repo.GetAll(ctx, filter)
+}
+
+// Open starts a shift for cashierID, which may be nil for sales made
+// with an API key.
+func (s *ShiftService) Open(ctx context.Context, req *model.OpenShiftRequest, cashierID *int) (*model.Shift, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+
+	shift := model.Shift{
+		CashierID:    cashierID,
+		OpeningFloat: req.OpeningFloat,
+	}
+	if err := s.repo.Open(ctx, &shift); err != nil {
+		return nil, err
+	}
+	return &shift, nil
+}
+
+func (s *ShiftService) GetByID(ctx context.Context, id int) (*model.Shift, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// Close counts the drawer out and reconciles it. With ownOnly, e.g. for
+// cashiers, only a shift of cashierID may be closed.
+func (s *ShiftService) Close(ctx context.Context, id int, req *model.CloseShiftRequest, cashierID *int, ownOnly bool) (*model.Shift, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+
+	if ownOnly {
+		shift, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if !sameCashier(shift.CashierID, cashierID) {
+			return nil, ErrShiftNotYours
+		}
+	}
+
+	return s.repo.Close(ctx, id, req)
+}
+
+// GetReport aggregates the sales and refunds made during a shift the way
+// GetReport of TransactionService does for a date range.
+func (s *ShiftService) GetReport(ctx context.Context, id int, top int) (*model.Report, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.transactionRepo.GetReport(ctx, &model.ReportFilter{
+		ShiftID: &id,
+		Top:     reportTop(top),
+	})
+}
+
+func sameCashier(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"category-crud/apperror"
+	"category-crud/auth"
+	"category-crud/model"
+	"category-crud/repository"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestShiftServiceClose(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(t)
+	cashier := s.createUser(t, "kasir", auth.RoleCashier)
+	kopi := s.createProduct(t, "Kopi", 9000, 10)
+
+	shift, err := s.shift.Open(ctx, &model.OpenShiftRequest{OpeningFloat: model.NewMoney(50000)}, &cashier.ID)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	item := model.CheckoutItem{ProductID: kopi.ID, Quantity: 1}
+	checkouts := []*model.CheckoutRequest{
+		// 11000 change goes back out of the drawer
+		cashCheckout(20000, item),
+		{
+			Items:    []model.CheckoutItem{item},
+			Payments: []model.PaymentRequest{{Method: model.PaymentCard, Amount: model.NewMoney(9000), Reference: "slip-1"}},
+		},
+		// 5000 on card, 4000 of the cash stays after 1000 change
+		{
+			Items: []model.CheckoutItem{item},
+			Payments: []model.PaymentRequest{
+				{Method: model.PaymentCard, Amount: model.NewMoney(5000), Reference: "slip-2"},
+				{Method: model.PaymentCash, Amount: model.NewMoney(5000)},
+			},
+		},
+	}
+	var sales []*model.Transaction
+	for _, req := range checkouts {
+		sale, _, err := s.transaction.Checkout(ctx, req, &cashier.ID, "")
+		if err != nil {
+			t.Fatalf("Checkout: %v", err)
+		}
+		if sale.ShiftID == nil || *sale.ShiftID != shift.ID {
+			t.Fatalf("want sale %d on shift %d, got %v", sale.ID, shift.ID, sale.ShiftID)
+		}
+		sales = append(sales, sale)
+	}
+	// the cash sale is paid back in cash, the split sale only up to its
+	// 4000 in cash
+	for _, sale := range []*model.Transaction{sales[0], sales[2]} {
+		if _, err := s.transaction.Refund(ctx, sale.ID, &model.RefundRequest{}, &cashier.ID); err != nil {
+			t.Fatalf("Refund %d: %v", sale.ID, err)
+		}
+	}
+
+	closed, err := s.shift.Close(ctx, shift.ID, &model.CloseShiftRequest{CountedCash: model.NewMoney(49500), Note: "short"}, &cashier.ID, true)
+	if err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	want := model.ShiftReconciliation{
+		OpeningFloat: model.NewMoney(50000),
+		CashSales:    model.NewMoney(13000),
+		CashRefunds:  model.NewMoney(13000),
+		ExpectedCash: model.NewMoney(50000),
+		CountedCash:  model.NewMoney(49500),
+		Variance:     model.NewMoney(-500),
+	}
+	if closed.Reconciliation == nil || *closed.Reconciliation != want {
+		t.Errorf("want %+v, got %+v", want, closed.Reconciliation)
+	}
+	if closed.ClosedAt == nil || closed.Note != "short" {
+		t.Errorf("want the shift closed with its note, got %+v", closed)
+	}
+
+	// closing again keeps the first count
+	_, err = s.shift.Close(ctx, shift.ID, &model.CloseShiftRequest{CountedCash: model.NewMoney(50000)}, &cashier.ID, true)
+	assertCode(t, err, apperror.CodeConflict)
+	if !errors.Is(err, repository.ErrShiftClosed) {
+		t.Errorf("want ErrShiftClosed, got %v", err)
+	}
+	stored, err := s.shift.GetByID(ctx, shift.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.Reconciliation == nil || stored.Reconciliation.CountedCash.Amount != 49500 {
+		t.Errorf("want the first count kept, got %+v", stored.Reconciliation)
+	}
+
+	// sales after closing belong to no shift
+	sale, _, err := s.transaction.Checkout(ctx, cashCheckout(9000, item), &cashier.ID, "")
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if sale.ShiftID != nil {
+		t.Errorf("want no shift on a sale after closing, got %d", *sale.ShiftID)
+	}
+}
+
+// TestShiftServiceRefundInLaterShift refunds a sale of a closed shift, so
+// the cash comes out of the drawer open at the time of the refund.
+func TestShiftServiceRefundInLaterShift(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(t)
+	cashier := s.createUser(t, "kasir", auth.RoleCashier)
+	kopi := s.createProduct(t, "Kopi", 9000, 10)
+	open := func() *model.Shift {
+		t.Helper()
+		shift, err := s.shift.Open(ctx, &model.OpenShiftRequest{OpeningFloat: model.NewMoney(50000)}, &cashier.ID)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		return shift
+	}
+
+	morning := open()
+	sale, _, err := s.transaction.Checkout(ctx, cashCheckout(10000, model.CheckoutItem{ProductID: kopi.ID, Quantity: 1}), &cashier.ID, "")
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if _, err := s.shift.Close(ctx, morning.ID, &model.CloseShiftRequest{CountedCash: model.NewMoney(59000)}, &cashier.ID, true); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	evening := open()
+	refund, err := s.transaction.Refund(ctx, sale.ID, &model.RefundRequest{}, &cashier.ID)
+	if err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	if refund.ShiftID == nil || *refund.ShiftID != evening.ID || refund.CashierID == nil || *refund.CashierID != cashier.ID {
+		t.Errorf("want the refund made by %d on shift %d, got %v on %v", cashier.ID, evening.ID, refund.CashierID, refund.ShiftID)
+	}
+
+	closed, err := s.shift.Close(ctx, evening.ID, &model.CloseShiftRequest{CountedCash: model.NewMoney(41000)}, &cashier.ID, true)
+	if err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	want := model.ShiftReconciliation{
+		OpeningFloat: model.NewMoney(50000),
+		CashSales:    model.NewMoney(0),
+		CashRefunds:  model.NewMoney(9000),
+		ExpectedCash: model.NewMoney(41000),
+		CountedCash:  model.NewMoney(41000),
+		Variance:     model.NewMoney(0),
+	}
+	if closed.Reconciliation == nil || *closed.Reconciliation != want {
+		t.Errorf("want %+v, got %+v", want, closed.Reconciliation)
+	}
+
+	// each shift's report has what was done during it
+	for _, tt := range []struct {
+		shift          *model.Shift
+		gross, refunds int64
+	}{
+		{morning, 9000, 0},
+		{evening, 0, 9000},
+	} {
+		report, err := s.shift.GetReport(ctx, tt.shift.ID, 0)
+		if err != nil {
+			t.Fatalf("GetReport: %v", err)
+		}
+		if report.GrossSales.Amount != tt.gross || report.TotalRefunds.Amount != tt.refunds {
+			t.Errorf("shift %d: want gross %d and refunds %d, got %d and %d",
+				tt.shift.ID, tt.gross, tt.refunds, report.GrossSales.Amount, report.TotalRefunds.Amount)
+		}
+	}
+}
+
+func TestShiftServiceOneOpenShift(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(t)
+	ani := s.createUser(t, "ani", auth.RoleCashier)
+	budi := s.createUser(t, "budi", auth.RoleCashier)
+	open := func(cashierID *int) (*model.Shift, error) {
+		return s.shift.Open(ctx, &model.OpenShiftRequest{OpeningFloat: model.NewMoney(10000)}, cashierID)
+	}
+
+	first, err := open(&ani.ID)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	_, err = open(&ani.ID)
+	assertCode(t, err, apperror.CodeConflict)
+	if !errors.Is(err, repository.ErrShiftAlreadyOpen) {
+		t.Errorf("want ErrShiftAlreadyOpen, got %v", err)
+	}
+
+	// other cashiers and API key sales have drawers of their own
+	if _, err := open(&budi.ID); err != nil {
+		t.Errorf("Open for another cashier: %v", err)
+	}
+	if _, err := open(nil); err != nil {
+		t.Errorf("Open without a cashier: %v", err)
+	}
+	_, err = open(nil)
+	assertCode(t, err, apperror.CodeConflict)
+
+	if _, err := s.shift.Close(ctx, first.ID, &model.CloseShiftRequest{CountedCash: model.NewMoney(10000)}, &ani.ID, true); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := open(&ani.ID); err != nil {
+		t.Errorf("want a new shift once the first is closed, got %v", err)
+	}
+}
+
+func TestShiftServiceCloseOwnOnly(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(t)
+	ani := s.createUser(t, "ani", auth.RoleCashier)
+	budi := s.createUser(t, "budi", auth.RoleCashier)
+	manager := s.createUser(t, "manager", auth.RoleManager)
+
+	shift, err := s.shift.Open(ctx, &model.OpenShiftRequest{}, &ani.ID)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	req := &model.CloseShiftRequest{CountedCash: model.NewMoney(0)}
+
+	_, err = s.shift.Close(ctx, shift.ID, req, &budi.ID, true)
+	assertCode(t, err, apperror.CodeForbidden)
+	_, err = s.shift.Close(ctx, shift.ID, req, nil, true)
+	assertCode(t, err, apperror.CodeForbidden)
+	_, err = s.shift.Close(ctx, shift.ID, &model.CloseShiftRequest{CountedCash: model.NewMoney(-1)}, &ani.ID, true)
+	assertCode(t, err, apperror.CodeValidation)
+	_, err = s.shift.Close(ctx, 999, req, &ani.ID, true)
+	assertCode(t, err, apperror.CodeNotFound)
+
+	// managers may close anyone's shift
+	if _, err := s.shift.Close(ctx, shift.ID, req, &manager.ID, false); err != nil {
+		t.Errorf("Close by a manager: %v", err)
+	}
+}
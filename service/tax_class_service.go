@@ -0,0 +1,86 @@
+package service
+
+import (
+	"category-crud/model"
+	"category-crud/repository"
+	"category-crud/validation"
+	"context"
+	"errors"
+	"fmt"
+)
+
+type TaxClassService struct {
+	repo repository.TaxClassRepository
+}
+
+func NewTaxClassService(repo repository.TaxClassRepository) *TaxClassService {
+	return &TaxClassService{repo: repo}
+}
+
+func (s *TaxClassService) GetAll(ctx context.Context) ([]model.TaxClass, error) {
+	return s.repo.GetAll(ctx)
+}
+
+func (s *TaxClassService) Create(ctx context.Context, data *model.TaxClass) error {
+	if err := validation.Struct(data); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, data)
+}
+
+func (s *TaxClassService) GetByID(ctx context.Context, id int) (*model.TaxClass, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *TaxClassService) Update(ctx context.Context, taxClass *model.TaxClass) error {
+	if err := validation.Struct(taxClass); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, taxClass)
+}
+
+func (s *TaxClassService) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// ParseTaxPolicy reads the tax settings: priceMode is inclusive or
+// exclusive (default inclusive), rounding is half_up, down or up (default
+// half_up).
+func ParseTaxPolicy(priceMode, rounding string) (model.TaxPolicy, error) {
+	var policy model.TaxPolicy
+	switch priceMode {
+	case "", model.PriceModeInclusive:
+		policy.Inclusive = true
+	case model.PriceModeExclusive:
+	default:
+		return policy, fmt.Errorf("invalid tax price mode %q, expected inclusive or exclusive", priceMode)
+	}
+
+	switch rounding {
+	case "":
+		policy.Rounding = model.RoundHalfUp
+	case model.RoundHalfUp, model.RoundDown, model.RoundUp:
+		policy.Rounding = rounding
+	default:
+		return policy, fmt.Errorf("invalid tax rounding %q, expected half_up, down or up", rounding)
+	}
+
+	return policy, nil
+}
+
+// checkTaxClass reports a tax_class_id that does not exist.
+func checkTaxClass(ctx context.Context, repo repository.TaxClassRepository, taxClassID *int) ([]validation.FieldError, error) {
+	if taxClassID == nil {
+		return nil, nil
+	}
+
+	_, err := repo.GetByID(ctx, *taxClassID)
+	if errors.Is(err, repository.ErrTaxClassNotFound) {
+		return []validation.FieldError{{
+			Field:   "tax_class_id",
+			Rule:    "exists",
+			Message: fmt.Sprintf("tax class %d does not exist", *taxClassID),
+		}}, nil
+	}
+	return nil, err
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"category-crud/model"
+	"testing"
+)
+
+func TestParseTaxPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		priceMode string
+		rounding  string
+		want      model.TaxPolicy
+		wantErr   bool
+	}{
+		{"defaults", "", "", model.TaxPolicy{Inclusive: true, Rounding: model.RoundHalfUp}, false},
+		{"inclusive", "inclusive", "down", model.TaxPolicy{Inclusive: true, Rounding: model.RoundDown}, false},
+		{"exclusive", "exclusive", "up", model.TaxPolicy{Rounding: model.RoundUp}, false},
+		{"exclusive with default rounding", "exclusive", "", model.TaxPolicy{Rounding: model.RoundHalfUp}, false},
+		{"half up", "", "half_up", model.TaxPolicy{Inclusive: true, Rounding: model.RoundHalfUp}, false},
+		{"unknown price mode", "gross", "", model.TaxPolicy{}, true},
+		{"price mode is case sensitive", "Inclusive", "", model.TaxPolicy{}, true},
+		{"no banker's rounding", "", "half_even", model.TaxPolicy{}, true},
+		{"unknown rounding", "exclusive", "nearest", model.TaxPolicy{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTaxPolicy(tt.priceMode, tt.rounding)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("want an error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTaxPolicy: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("want %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
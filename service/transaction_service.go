@@ -1,22 +1,245 @@
 package service
 
 import (
+	"category-crud/apperror"
 	"category-crud/model"
+	"category-crud/model/dto"
 	"category-crud/repository"
+	"category-crud/validation"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
 )
 
+const (
+	defaultIdempotencyTTL = 24 * time.Hour
+	defaultVoidWindow     = 15 * time.Minute
+)
+
+var ErrVoidWindowExpired = apperror.Conflict("void window has expired, use a refund instead", nil)
+
+type TransactionOptions struct {
+	// IdempotencyTTL is how long an Idempotency-Key is remembered.
+	IdempotencyTTL time.Duration
+	// VoidWindow is how long after checkout a sale may still be voided.
+	VoidWindow time.Duration
+	// Tax is how checkout computes tax.
+	Tax model.TaxPolicy
+	// Metrics records checkout outcomes; nil disables it.
+	Metrics CheckoutMetrics
+}
+
+type CheckoutMetrics interface {
+	CheckoutSucceeded(transaction *model.Transaction)
+	CheckoutFailed(reason string)
+}
+
+type noCheckoutMetrics struct{}
+
+func (noCheckoutMetrics) CheckoutSucceeded(*model.Transaction) {}
+func (noCheckoutMetrics) CheckoutFailed(string)                {}
+
 type TransactionService struct {
-	repo *repository.TransactionRepository
+	repo    repository.TransactionRepository
+	options TransactionOptions
+}
+
+func NewTransactionService(repo repository.TransactionRepository, options TransactionOptions) *TransactionService {
+	if options.IdempotencyTTL <= 0 {
+		options.IdempotencyTTL = defaultIdempotencyTTL
+	}
+	if options.VoidWindow <= 0 {
+		options.VoidWindow = defaultVoidWindow
+	}
+	if options.Metrics == nil {
+		options.Metrics = noCheckoutMetrics{}
+	}
+	return &TransactionService{repo: repo, options: options}
+}
+
+// Checkout records a sale rung up by cashierID, which may be nil. With a
+// non-empty idempotencyKey a retried request returns the original
+// transaction and replayed is true. Keys are scoped to cashierID, so
+// cashiers cannot replay each other's sales.
+func (s *TransactionService) Checkout(ctx context.Context, req *model.CheckoutRequest, cashierID *int, idempotencyKey string) (*model.Transaction, bool, error) {
+	transaction, replayed, err := s.checkout(ctx, req, cashierID, idempotencyKey)
+	switch {
+	case err != nil:
+		s.options.Metrics.CheckoutFailed(string(apperror.CodeOf(err)))
+	case !replayed:
+		s.options.Metrics.CheckoutSucceeded(transaction)
+	}
+
+	return transaction, replayed, err
+}
+
+func (s *TransactionService) checkout(ctx context.Context, req *model.CheckoutRequest, cashierID *int, idempotencyKey string) (*model.Transaction, bool, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, false, err
+	}
+	if fields := checkPayments(req.Payments); len(fields) > 0 {
+		return nil, false, validation.Failed(fields...)
+	}
+
+	var key *model.IdempotencyKey
+	if idempotencyKey != "" {
+		hash, err := hashRequest(req)
+		if err != nil {
+			return nil, false, err
+		}
+		key = &model.IdempotencyKey{
+			Key:         idempotencyKey,
+			RequestHash: hash,
+			ExpiresAt:   time.Now().Add(s.options.IdempotencyTTL),
+		}
+	}
+
+	return s.repo.CreateTransaction(ctx, req, cashierID, key, s.options.Tax)
+}
+
+// checkPayments reports card, QRIS and e-wallet payments without a
+// reference. Whether the payments cover the total is only known once the
+// sale is priced, so the repository checks that.
+func checkPayments(payments []model.PaymentRequest) []validation.FieldError {
+	var fields []validation.FieldError
+	for i, payment := range payments {
+		if payment.Method != model.PaymentCash && strings.TrimSpace(payment.Reference) == "" {
+			fields = append(fields, validation.FieldError{
+				Field:   fmt.Sprintf("payments[%d].reference", i),
+				Rule:    "required",
+				Message: fmt.Sprintf("is required for %s payments", payment.Method),
+			})
+		}
+	}
+	return fields
 }
 
-func NewTransactionService(repo *repository.TransactionRepository) *TransactionService {
-	return &TransactionService{repo: repo}
+// hashRequest fingerprints a checkout payload so a reused idempotency key
+// can be told apart from a genuine retry. The cashier is not part of it:
+// keys are already scoped to the cashier, and leaving it out keeps the
+// hashes stored before keys were scoped valid.
+func hashRequest(req *model.CheckoutRequest) (string, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
 }
 
-func (s *TransactionService) Checkout(items []model.CheckoutItem) (*model.Transaction, error) {
-	return s.repo.CreateTransaction(items)
+func (s *TransactionService) GetAll(ctx context.Context, filter *dto.TransactionFilterRequest) (*dto.TransactionPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = dto.DefaultTransactionLimit
+	}
+	if filter.Limit > dto.MaxTransactionLimit {
+		filter.Limit = dto.MaxTransactionLimit
+	}
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	return s.repo.GetAll(ctx, filter)
 }
 
-func (s *TransactionService) GetReport(startDate string, endDate string) (*model.Report, error) {
-	return s.repo.GetReport(startDate, endDate)
+// ExportTransactions streams every detail line of the transactions
+// matching filter to yield; pagination is ignored.
+func (s *TransactionService) ExportTransactions(ctx context.Context, filter *dto.TransactionFilterRequest, yield func(*model.TransactionLine) error) error {
+	return s.repo.ExportLines(ctx, filter, yield)
+}
+
+func (s *TransactionService) GetByID(ctx context.Context, id int) (*model.Transaction, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// Refund returns some or all items of a transaction to stock. Cash is
+// paid out of the drawer of the shift cashierID has open.
+func (s *TransactionService) Refund(ctx context.Context, transactionID int, req *model.RefundRequest, cashierID *int) (*model.Refund, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+	return s.repo.CreateRefund(ctx, transactionID, model.RefundTypeRefund, req, cashierID)
+}
+
+// Void cancels a whole transaction, which is only allowed within the
+// configured void window after checkout.
+func (s *TransactionService) Void(ctx context.Context, transactionID int, req *model.VoidRequest, cashierID *int) (*model.Refund, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.repo.GetByID(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Since(transaction.CreatedAt) > s.options.VoidWindow {
+		return nil, ErrVoidWindowExpired
+	}
+
+	return s.repo.CreateRefund(ctx, transactionID, model.RefundTypeVoid, &model.RefundRequest{
+		Reason: req.Reason,
+	}, cashierID)
+}
+
+// GetReport aggregates the sales between req.StartDate and req.EndDate,
+// both inclusive and defaulting to today.
+func (s *TransactionService) GetReport(ctx context.Context, req *dto.ReportRequest) (*model.Report, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	filter := model.ReportFilter{
+		StartDate: today,
+		EndDate:   today.AddDate(0, 0, 1),
+		Top:       reportTop(req.Top),
+	}
+	if req.StartDate != nil {
+		filter.StartDate = *req.StartDate
+	}
+	if req.EndDate != nil {
+		filter.EndDate = req.EndDate.AddDate(0, 0, 1)
+	}
+	if !filter.StartDate.Before(filter.EndDate) {
+		return nil, apperror.BadRequest("start_date must be before end_date")
+	}
+
+	return s.repo.GetReport(ctx, &filter)
+}
+
+// reportTop bounds the length of best-seller lists.
+func reportTop(top int) int {
+	if top <= 0 {
+		return dto.DefaultReportTop
+	}
+	return min(top, dto.MaxReportTop)
+}
+
+// GetTimeseries buckets the sales between req.StartDate and req.EndDate,
+// both inclusive and defaulting to today in req.Location.
+func (s *TransactionService) GetTimeseries(ctx context.Context, req *dto.TimeseriesRequest) (*model.Timeseries, error) {
+	now := time.Now().In(req.Location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, req.Location)
+
+	filter := model.TimeseriesFilter{
+		StartDate: today,
+		EndDate:   today.AddDate(0, 0, 1),
+		Interval:  req.Interval,
+		Location:  req.Location,
+	}
+	if req.StartDate != nil {
+		filter.StartDate = *req.StartDate
+	}
+	if req.EndDate != nil {
+		filter.EndDate = req.EndDate.AddDate(0, 0, 1)
+	}
+	if !filter.StartDate.Before(filter.EndDate) {
+		return nil, apperror.BadRequest("start_date must be before end_date")
+	}
+	if repository.CountBuckets(&filter, dto.MaxTimeseriesBuckets+1) > dto.MaxTimeseriesBuckets {
+		return nil, apperror.BadRequest(fmt.Sprintf("date range is too long for interval %s, at most %d buckets are allowed", filter.Interval, dto.MaxTimeseriesBuckets))
+	}
+
+	return s.repo.GetTimeseries(ctx, &filter)
 }
@@ -0,0 +1,498 @@
+package service
+
+import (
+	"category-crud/apperror"
+	"category-crud/model"
+	"category-crud/model/dto"
+	"category-crud/repository"
+	"category-crud/repository/memory"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTransactionServiceCheckout(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(t)
+
+	coffee := s.createProduct(t, "Kopi", 8000, 5)
+	tea := s.createProduct(t, "Teh", 5000, 5)
+
+	transaction, replayed, err := s.transaction.Checkout(ctx, cashCheckout(30000,
+		model.CheckoutItem{ProductID: coffee.ID, Quantity: 2},
+		model.CheckoutItem{ProductID: tea.ID, Quantity: 1},
+	), nil, "")
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if replayed {
+		t.Error("first checkout reported as replayed")
+	}
+	if transaction.TotalAmount.Amount != 21000 {
+		t.Errorf("want total 21000, got %d", transaction.TotalAmount.Amount)
+	}
+	if len(transaction.Details) != 2 {
+		t.Fatalf("want 2 details, got %d", len(transaction.Details))
+	}
+	for _, detail := range transaction.Details {
+		if detail.ProductID == coffee.ID && (detail.ProductName != "Kopi" || detail.UnitPrice.Amount != 8000) {
+			t.Errorf("coffee line did not snapshot name and price: %+v", detail)
+		}
+	}
+	if len(transaction.Payments) != 1 || transaction.Payments[0].Change.Amount != 9000 {
+		t.Errorf("want 9000 change, got %+v", transaction.Payments)
+	}
+
+	for _, want := range []struct {
+		id    int
+		stock int
+	}{{coffee.ID, 3}, {tea.ID, 4}} {
+		product, err := s.product.GetByID(ctx, want.id, false)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if product.Stock != want.stock {
+			t.Errorf("product %d: want stock %d, got %d", want.id, want.stock, product.Stock)
+		}
+	}
+
+	stored, err := s.transaction.GetByID(ctx, transaction.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.TotalAmount != transaction.TotalAmount || len(stored.Details) != 2 {
+		t.Errorf("GetByID: got %+v", stored)
+	}
+}
+
+func TestTransactionServiceCheckoutRejected(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(t)
+
+	coffee := s.createProduct(t, "Kopi", 8000, 2)
+	tea := s.createProduct(t, "Teh", 5000, 5)
+
+	tests := []struct {
+		name string
+		req  *model.CheckoutRequest
+		want apperror.Code
+	}{
+		{
+			name: "no items",
+			req:  cashCheckout(10000),
+			want: apperror.CodeValidation,
+		},
+		{
+			name: "zero quantity",
+			req:  cashCheckout(10000, model.CheckoutItem{ProductID: tea.ID}),
+			want: apperror.CodeValidation,
+		},
+		{
+			name: "unknown product",
+			req:  cashCheckout(10000, model.CheckoutItem{ProductID: 99, Quantity: 1}),
+			want: apperror.CodeUnprocessable,
+		},
+		{
+			name: "insufficient stock",
+			req: cashCheckout(100000,
+				model.CheckoutItem{ProductID: tea.ID, Quantity: 1},
+				model.CheckoutItem{ProductID: coffee.ID, Quantity: 3},
+			),
+			want: apperror.CodeInsufficientStock,
+		},
+		{
+			name: "card without reference",
+			req: &model.CheckoutRequest{
+				Items:    []model.CheckoutItem{{ProductID: tea.ID, Quantity: 1}},
+				Payments: []model.PaymentRequest{{Method: model.PaymentCard, Amount: model.NewMoney(5000)}},
+			},
+			want: apperror.CodeValidation,
+		},
+		{
+			name: "underpaid",
+			req:  cashCheckout(4999, model.CheckoutItem{ProductID: tea.ID, Quantity: 1}),
+			want: apperror.CodeUnprocessable,
+		},
+		{
+			// only a sale that comes to nothing may go without payments
+			name: "no payments",
+			req:  &model.CheckoutRequest{Items: []model.CheckoutItem{{ProductID: tea.ID, Quantity: 1}}},
+			want: apperror.CodeUnprocessable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := s.transaction.Checkout(ctx, tt.req, nil, "")
+			assertCode(t, err, tt.want)
+		})
+	}
+
+	// a rejected checkout must not touch stock, even for its valid lines
+	for _, want := range []struct {
+		id    int
+		stock int
+	}{{coffee.ID, 2}, {tea.ID, 5}} {
+		product, err := s.product.GetByID(ctx, want.id, false)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if product.Stock != want.stock {
+			t.Errorf("product %d: want stock %d, got %d", want.id, want.stock, product.Stock)
+		}
+	}
+}
+
+func TestTransactionServiceCheckoutZeroTotal(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(t)
+	sample := s.createProduct(t, "Sampel", 0, 10)
+	item := model.CheckoutItem{ProductID: sample.ID, Quantity: 2}
+
+	for name, payments := range map[string][]model.PaymentRequest{"no payments": nil, "empty payments": {}} {
+		transaction, _, err := s.transaction.Checkout(ctx, &model.CheckoutRequest{Items: []model.CheckoutItem{item}, Payments: payments}, nil, "")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if transaction.TotalAmount.Amount != 0 || len(transaction.Payments) != 0 {
+			t.Errorf("%s: want a free sale without payments, got %d with %+v", name, transaction.TotalAmount.Amount, transaction.Payments)
+		}
+	}
+
+	// cash handed over for nothing would all be change
+	_, _, err := s.transaction.Checkout(ctx, cashCheckout(1000, item), nil, "")
+	assertCode(t, err, apperror.CodeUnprocessable)
+
+	product, err := s.product.GetByID(ctx, sample.ID, false)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if product.Stock != 6 {
+		t.Errorf("want two free sales taken from stock, got stock %d", product.Stock)
+	}
+}
+
+func TestTransactionServiceIdempotency(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(t)
+
+	alice := s.createUser(t, "alice", "cashier")
+	bob := s.createUser(t, "bob", "cashier")
+	coffee := s.createProduct(t, "Kopi", 8000, 10)
+	req := func(quantity int) *model.CheckoutRequest {
+		return cashCheckout(50000, model.CheckoutItem{ProductID: coffee.ID, Quantity: quantity})
+	}
+
+	first, replayed, err := s.transaction.Checkout(ctx, req(1), &alice.ID, "key-1")
+	if err != nil || replayed {
+		t.Fatalf("first checkout: replayed %v, err %v", replayed, err)
+	}
+
+	t.Run("retry replays", func(t *testing.T) {
+		again, replayed, err := s.transaction.Checkout(ctx, req(1), &alice.ID, "key-1")
+		if err != nil {
+			t.Fatalf("Checkout: %v", err)
+		}
+		if !replayed || again.ID != first.ID {
+			t.Errorf("want replay of transaction %d, got %d (replayed %v)", first.ID, again.ID, replayed)
+		}
+	})
+
+	t.Run("different payload", func(t *testing.T) {
+		_, _, err := s.transaction.Checkout(ctx, req(2), &alice.ID, "key-1")
+		assertCode(t, err, apperror.CodeUnprocessable)
+	})
+
+	t.Run("other cashier", func(t *testing.T) {
+		other, replayed, err := s.transaction.Checkout(ctx, req(1), &bob.ID, "key-1")
+		if err != nil {
+			t.Fatalf("Checkout: %v", err)
+		}
+		if replayed || other.ID == first.ID {
+			t.Fatalf("bob was replayed alice's transaction %d", first.ID)
+		}
+		if other.CashierID == nil || *other.CashierID != bob.ID {
+			t.Errorf("want cashier %d, got %v", bob.ID, other.CashierID)
+		}
+	})
+
+	t.Run("no cashier", func(t *testing.T) {
+		anonymous, replayed, err := s.transaction.Checkout(ctx, req(1), nil, "key-1")
+		if err != nil {
+			t.Fatalf("Checkout: %v", err)
+		}
+		if replayed || anonymous.ID == first.ID {
+			t.Fatalf("API key caller was replayed alice's transaction %d", first.ID)
+		}
+	})
+
+	product, err := s.product.GetByID(ctx, coffee.ID, false)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if product.Stock != 7 {
+		t.Errorf("want 3 sales, stock 7, got stock %d", product.Stock)
+	}
+}
+
+func TestTransactionServiceIdempotencyExpiry(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(t)
+	s.transaction = NewTransactionService(memory.NewTransactionRepository(s.store), TransactionOptions{
+		IdempotencyTTL: time.Nanosecond,
+		Tax:            model.TaxPolicy{Inclusive: true},
+	})
+
+	coffee := s.createProduct(t, "Kopi", 8000, 10)
+	first, _, err := s.transaction.Checkout(ctx, cashCheckout(8000, model.CheckoutItem{ProductID: coffee.ID, Quantity: 1}), nil, "key-1")
+	if err != nil {
+		t.Fatalf("first checkout: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+
+	// once expired the key is free again, even for a different payload
+	second, replayed, err := s.transaction.Checkout(ctx, cashCheckout(16000, model.CheckoutItem{ProductID: coffee.ID, Quantity: 2}), nil, "key-1")
+	if err != nil {
+		t.Fatalf("checkout after expiry: %v", err)
+	}
+	if replayed || second.ID == first.ID {
+		t.Errorf("expired key replayed transaction %d", first.ID)
+	}
+}
+
+// TestTransactionServiceReportChange checks that change handed back is
+// neither revenue nor what cash took in.
+func TestTransactionServiceReportChange(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(t)
+	kopi := s.createProduct(t, "Kopi", 9000, 10)
+
+	checkouts := []*model.CheckoutRequest{
+		cashCheckout(20000, model.CheckoutItem{ProductID: kopi.ID, Quantity: 1}),
+		{
+			Items: []model.CheckoutItem{{ProductID: kopi.ID, Quantity: 1}},
+			Payments: []model.PaymentRequest{
+				{Method: model.PaymentCard, Amount: model.NewMoney(5000), Reference: "slip-1"},
+				{Method: model.PaymentCash, Amount: model.NewMoney(5000)},
+			},
+		},
+	}
+	for _, req := range checkouts {
+		if _, _, err := s.transaction.Checkout(ctx, req, nil, ""); err != nil {
+			t.Fatalf("Checkout: %v", err)
+		}
+	}
+
+	report, err := s.transaction.GetReport(ctx, &dto.ReportRequest{})
+	if err != nil {
+		t.Fatalf("GetReport: %v", err)
+	}
+	if report.TotalRevenue.Amount != 18000 {
+		t.Errorf("want revenue 18000, got %d", report.TotalRevenue.Amount)
+	}
+
+	want := map[string]model.PaymentSales{
+		model.PaymentCash:    {Method: model.PaymentCash, Payments: 2, Amount: model.NewMoney(13000), Tendered: model.NewMoney(25000), Change: model.NewMoney(12000)},
+		model.PaymentCard:    {Method: model.PaymentCard, Payments: 1, Amount: model.NewMoney(5000), Tendered: model.NewMoney(5000), Change: model.NewMoney(0)},
+		model.PaymentQRIS:    {Method: model.PaymentQRIS, Amount: model.NewMoney(0), Tendered: model.NewMoney(0), Change: model.NewMoney(0)},
+		model.PaymentEWallet: {Method: model.PaymentEWallet, Amount: model.NewMoney(0), Tendered: model.NewMoney(0), Change: model.NewMoney(0)},
+	}
+	var paid int64
+	for _, row := range report.Payments {
+		if row != want[row.Method] {
+			t.Errorf("%s: want %+v, got %+v", row.Method, want[row.Method], row)
+		}
+		paid += row.Amount.Amount
+	}
+	if len(report.Payments) != len(model.PaymentMethods) {
+		t.Errorf("want a row per payment method, got %+v", report.Payments)
+	}
+	if paid != report.TotalRevenue.Amount {
+		t.Errorf("payment methods took in %d, want the revenue %d", paid, report.TotalRevenue.Amount)
+	}
+}
+
+// TestHashRequestFormat pins the hash to the bare checkout payload, so
+// keys stored by earlier releases still match their retries.
+func TestHashRequestFormat(t *testing.T) {
+	req := cashCheckout(20000, model.CheckoutItem{ProductID: 1, Quantity: 2})
+	payload, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(payload)
+
+	got, err := hashRequest(req)
+	if err != nil {
+		t.Fatalf("hashRequest: %v", err)
+	}
+	if want := hex.EncodeToString(sum[:]); got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
+
+func TestTransactionServiceRefund(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(t)
+
+	taxClass := &model.TaxClass{Name: "PPN", RateBps: 1000}
+	if err := memory.NewTaxClassRepository(s.store).Create(ctx, taxClass); err != nil {
+		t.Fatalf("create tax class: %v", err)
+	}
+	roti := &dto.ProductRequest{Name: "Roti", Price: model.NewMoney(1000), Stock: 10, TaxClassID: &taxClass.ID}
+	if err := s.product.Create(ctx, roti); err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	kopi := s.createProduct(t, "Kopi", 9000, 10)
+
+	// 7000 of rolls carry 636 tax, which seven units do not split evenly
+	sale, _, err := s.transaction.Checkout(ctx, cashCheckout(16000,
+		model.CheckoutItem{ProductID: roti.ID, Quantity: 7},
+		model.CheckoutItem{ProductID: kopi.ID, Quantity: 1},
+	), nil, "")
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	details := make(map[int]int, len(sale.Details)) // product id -> detail id
+	for _, detail := range sale.Details {
+		details[detail.ProductID] = detail.ID
+	}
+	if sale.Details[0].TaxAmount.Amount != 636 {
+		t.Fatalf("want 636 tax on the rolls, got %d", sale.Details[0].TaxAmount.Amount)
+	}
+
+	rolls := func(quantity int) []model.RefundItem {
+		return []model.RefundItem{{TransactionDetailID: details[roti.ID], Quantity: quantity}}
+	}
+	steps := []struct {
+		name        string
+		items       []model.RefundItem
+		amount, tax int64
+		code        apperror.Code
+		details     []repository.RefundItemError
+	}{
+		{name: "three rolls", items: rolls(3), amount: 3000, tax: 272},
+		{
+			name:    "more rolls than are left",
+			items:   rolls(5),
+			code:    apperror.CodeUnprocessable,
+			details: []repository.RefundItemError{{TransactionDetailID: details[roti.ID], Reason: repository.RefundReasonExceedsRefundable, Requested: 5, Refundable: 4}},
+		},
+		{
+			name:    "unknown detail",
+			items:   []model.RefundItem{{TransactionDetailID: 999, Quantity: 1}},
+			code:    apperror.CodeUnprocessable,
+			details: []repository.RefundItemError{{TransactionDetailID: 999, Reason: repository.RefundReasonDetailNotFound, Requested: 1}},
+		},
+		{name: "three more rolls", items: rolls(3), amount: 3000, tax: 272},
+		{name: "the last roll takes the remainder", items: rolls(1), amount: 1000, tax: 92},
+		{name: "the rest", amount: 9000},
+		{name: "nothing left", code: apperror.CodeConflict},
+	}
+	for _, step := range steps {
+		refund, err := s.transaction.Refund(ctx, sale.ID, &model.RefundRequest{Items: step.items}, nil)
+		if step.code != "" {
+			assertCode(t, err, step.code)
+			if step.details != nil {
+				var appErr *apperror.Error
+				errors.As(err, &appErr)
+				if !reflect.DeepEqual(appErr.Details, step.details) {
+					t.Errorf("%s: want details %+v, got %+v", step.name, step.details, appErr.Details)
+				}
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if refund.TotalAmount.Amount != step.amount || refund.TaxAmount.Amount != step.tax {
+			t.Errorf("%s: want %d with %d tax, got %d with %d tax", step.name, step.amount, step.tax, refund.TotalAmount.Amount, refund.TaxAmount.Amount)
+		}
+	}
+
+	for _, product := range []*dto.ProductRequest{roti, kopi} {
+		stored, err := s.product.GetByID(ctx, product.ID, false)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if stored.Stock != 10 {
+			t.Errorf("%s: want every unit back in stock, got %d", product.Name, stored.Stock)
+		}
+	}
+}
+
+func TestTransactionServiceReportAfterRefund(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(t)
+	kopi := s.createProduct(t, "Kopi", 9000, 10)
+
+	sale, _, err := s.transaction.Checkout(ctx, cashCheckout(27000, model.CheckoutItem{ProductID: kopi.ID, Quantity: 3}), nil, "")
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if _, err := s.transaction.Refund(ctx, sale.ID, &model.RefundRequest{
+		Items: []model.RefundItem{{TransactionDetailID: sale.Details[0].ID, Quantity: 1}},
+	}, nil); err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+
+	report, err := s.transaction.GetReport(ctx, &dto.ReportRequest{})
+	if err != nil {
+		t.Fatalf("GetReport: %v", err)
+	}
+	if report.GrossSales.Amount != 27000 || report.TotalRefunds.Amount != 9000 || report.NetRevenue.Amount != 18000 {
+		t.Errorf("want gross 27000, refunds 9000 and net 18000, got %d, %d and %d",
+			report.GrossSales.Amount, report.TotalRefunds.Amount, report.NetRevenue.Amount)
+	}
+	if report.TotalTransaks != 1 {
+		t.Errorf("a partial refund keeps the sale counted, got %d transactions", report.TotalTransaks)
+	}
+}
+
+func TestTransactionServiceVoid(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(t)
+	kopi := s.createProduct(t, "Kopi", 9000, 10)
+	item := model.CheckoutItem{ProductID: kopi.ID, Quantity: 2}
+
+	sale, _, err := s.transaction.Checkout(ctx, cashCheckout(18000, item), nil, "")
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	void, err := s.transaction.Void(ctx, sale.ID, &model.VoidRequest{Reason: "wrong order"}, nil)
+	if err != nil {
+		t.Fatalf("Void: %v", err)
+	}
+	if void.Type != model.RefundTypeVoid || void.TotalAmount.Amount != 18000 {
+		t.Errorf("want a void of 18000, got %s of %d", void.Type, void.TotalAmount.Amount)
+	}
+	_, err = s.transaction.Void(ctx, sale.ID, &model.VoidRequest{}, nil)
+	assertCode(t, err, apperror.CodeConflict)
+	_, err = s.transaction.Refund(ctx, sale.ID, &model.RefundRequest{}, nil)
+	assertCode(t, err, apperror.CodeConflict)
+
+	s.transaction = NewTransactionService(memory.NewTransactionRepository(s.store), TransactionOptions{
+		VoidWindow: time.Nanosecond,
+		Tax:        model.TaxPolicy{Inclusive: true},
+	})
+	late, _, err := s.transaction.Checkout(ctx, cashCheckout(18000, item), nil, "")
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+
+	// past the window the sale can only be refunded
+	if _, err := s.transaction.Void(ctx, late.ID, &model.VoidRequest{}, nil); !errors.Is(err, ErrVoidWindowExpired) {
+		t.Errorf("want ErrVoidWindowExpired, got %v", err)
+	}
+	if _, err := s.transaction.Refund(ctx, late.ID, &model.RefundRequest{}, nil); err != nil {
+		t.Errorf("Refund after the void window: %v", err)
+	}
+
+	_, err = s.transaction.Void(ctx, 999, &model.VoidRequest{}, nil)
+	assertCode(t, err, apperror.CodeNotFound)
+}
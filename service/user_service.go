@@ -0,0 +1,145 @@
+package service
+
+import (
+	"category-crud/apperror"
+	"category-crud/auth"
+	"category-crud/model"
+	"category-crud/model/dto"
+	"category-crud/repository"
+	"category-crud/validation"
+	"context"
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrInvalidLogin = apperror.Unauthorized("username atau password salah")
+
+// dummyPasswordHash is compared against when the username is unknown so
+// failed logins take the same time whether or not the user exists.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+type UserService struct {
+	repo   repository.UserRepository
+	issuer *auth.Issuer
+}
+
+func NewUserService(repo repository.UserRepository, issuer *auth.Issuer) *UserService {
+	return &UserService{repo: repo, issuer: issuer}
+}
+
+func (s *UserService) GetAll(ctx context.Context) ([]model.User, error) {
+	return s.repo.GetAll(ctx)
+}
+
+func (s *UserService) GetByID(ctx context.Context, id int) (*model.User, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *UserService) Create(ctx context.Context, req *dto.UserRequest) (*model.User, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+	if req.Password == "" {
+		return nil, validation.Failed(validation.FieldError{
+			Field:   "password",
+			Rule:    "required",
+			Message: "is required",
+		})
+	}
+
+	hash, err := hashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &model.User{
+		Username:     req.Username,
+		Name:         req.Name,
+		Role:         req.Role,
+		Active:       req.Active == nil || *req.Active,
+		PasswordHash: hash,
+	}
+	if err := s.repo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Update changes a user's profile. The password and active flag are only
+// changed when given.
+func (s *UserService) Update(ctx context.Context, req *dto.UserRequest) (*model.User, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.GetByID(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Username = req.Username
+	user.Name = req.Name
+	user.Role = req.Role
+	if req.Active != nil {
+		user.Active = *req.Active
+	}
+	user.PasswordHash = ""
+	if req.Password != "" {
+		if user.PasswordHash, err = hashPassword(req.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *UserService) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// Login checks the credentials of an active user and issues a token
+// carrying the user's id and role.
+func (s *UserService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.LoginResponse, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.GetByUsername(ctx, req.Username)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(req.Password))
+		return nil, ErrInvalidLogin
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)) != nil || !user.Active {
+		return nil, ErrInvalidLogin
+	}
+
+	token, expiresAt, err := s.issuer.Issue(user.ID, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.LoginResponse{
+		Token:     token,
+		TokenType: "Bearer",
+		ExpiresAt: expiresAt,
+		User:      *user,
+	}, nil
+}
+
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
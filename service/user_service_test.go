@@ -0,0 +1,92 @@
+package service
+
+import (
+	"category-crud/apperror"
+	"category-crud/auth"
+	"category-crud/config"
+	"category-crud/model/dto"
+	"category-crud/repository/memory"
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestUserServiceRevokesTokens logs a user in and checks that the token
+// stops working as soon as the account changes.
+func TestUserServiceRevokesTokens(t *testing.T) {
+	ctx := context.Background()
+	var cfg config.Template
+	cfg.Auth.JWT.HS256Secret = "test-secret-of-at-least-32-bytes!"
+
+	users := memory.NewUserRepository(memory.NewStore())
+	issuer, err := auth.NewIssuer(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator, err := auth.NewAuthenticator(cfg, users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewUserService(users, issuer)
+
+	tests := []struct {
+		name   string
+		change func(t *testing.T, req *dto.UserRequest)
+	}{
+		{
+			name: "deactivated",
+			change: func(t *testing.T, req *dto.UserRequest) {
+				active := false
+				req.Active = &active
+				if _, err := s.Update(ctx, req); err != nil {
+					t.Fatalf("Update: %v", err)
+				}
+			},
+		},
+		{
+			name: "demoted",
+			change: func(t *testing.T, req *dto.UserRequest) {
+				req.Role = auth.RoleCashier
+				if _, err := s.Update(ctx, req); err != nil {
+					t.Fatalf("Update: %v", err)
+				}
+			},
+		},
+		{
+			name: "deleted",
+			change: func(t *testing.T, req *dto.UserRequest) {
+				if err := s.Delete(ctx, req.ID); err != nil {
+					t.Fatalf("Delete: %v", err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &dto.UserRequest{Username: tt.name, Password: "password123", Role: auth.RoleManager}
+			user, err := s.Create(ctx, req)
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			login, err := s.Login(ctx, &dto.LoginRequest{Username: tt.name, Password: "password123"})
+			if err != nil {
+				t.Fatalf("Login: %v", err)
+			}
+			if _, err := authenticator.AuthenticateToken(ctx, login.Token); err != nil {
+				t.Fatalf("fresh token rejected: %v", err)
+			}
+
+			req.ID = user.ID
+			req.Password = ""
+			tt.change(t, req)
+
+			if _, err := authenticator.AuthenticateToken(ctx, login.Token); !errors.Is(err, auth.ErrInvalidCredentials) {
+				t.Errorf("want the token revoked, got %v", err)
+			}
+			_, err = s.Login(ctx, &dto.LoginRequest{Username: tt.name, Password: "password123"})
+			if tt.name != "demoted" {
+				assertCode(t, err, apperror.CodeUnauthorized)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// Package validation enforces the `validate` struct tags declared on
+// request DTOs and reports failures as field level errors.
+package validation
+
+import (
+	"category-crud/apperror"
+	"category-crud/model"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError points at a single invalid input, e.g.
+// {"field": "items[1].quantity", "rule": "gt", "message": "must be greater than 0"}.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// report JSON names instead of Go field names
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	v.RegisterValidation("notblank", func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != ""
+	})
+
+	// amount rules such as gte=0 apply to the minor units
+	v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		return field.Interface().(model.Money).Amount
+	}, model.Money{})
+
+	return v
+}
+
+// Struct validates a request DTO and returns an apperror.Validation
+// listing every invalid field, or nil.
+func Struct(request interface{}) error {
+	err := validate.Struct(request)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	fields := make([]FieldError, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		fields = append(fields, FieldError{
+			Field:   fieldPath(fieldErr.Namespace()),
+			Rule:    fieldErr.Tag(),
+			Message: message(fieldErr),
+		})
+	}
+
+	return Failed(fields...)
+}
+
+// Failed builds the validation error for checks that cannot be expressed
+// as tags, such as references to rows that must exist.
+func Failed(fields ...FieldError) error {
+	return apperror.Validation("request validation failed", fields)
+}
+
+// fieldPath drops the root struct name: "CheckoutRequest.items[0].quantity"
+// becomes "items[0].quantity".
+func fieldPath(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+func message(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	isCollection := fieldErr.Kind() == reflect.Slice || fieldErr.Kind() == reflect.Map
+	isString := fieldErr.Kind() == reflect.String
+
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "notblank":
+		return "must not be blank"
+	case "gt":
+		if isCollection {
+			return fmt.Sprintf("must contain more than %s %s", param, items(param))
+		}
+		return fmt.Sprintf("must be greater than %s", param)
+	case "gte":
+		return fmt.Sprintf("must be at least %s", param)
+	case "lte":
+		return fmt.Sprintf("must be at most %s", param)
+	case "min":
+		if isCollection {
+			return fmt.Sprintf("must contain at least %s %s", param, items(param))
+		}
+		if isString {
+			return fmt.Sprintf("must be at least %s characters", param)
+		}
+		return fmt.Sprintf("must be at least %s", param)
+	case "max":
+		if isCollection {
+			return fmt.Sprintf("must contain at most %s %s", param, items(param))
+		}
+		if isString {
+			return fmt.Sprintf("must be at most %s characters", param)
+		}
+		return fmt.Sprintf("must be at most %s", param)
+	case "unique":
+		if param != "" {
+			return fmt.Sprintf("must not contain duplicate %s values", jsonName(fieldErr.Type(), param))
+		}
+		return "must not contain duplicates"
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.ReplaceAll(param, " ", ", "))
+	default:
+		return fmt.Sprintf("failed the %s rule", fieldErr.Tag())
+	}
+}
+
+func items(count string) string {
+	if count == "1" {
+		return "item"
+	}
+	return "items"
+}
+
+// jsonName resolves a Go field name used as a rule parameter, such as
+// unique=ProductID, to its JSON name on the collection's element type.
+func jsonName(collection reflect.Type, fieldName string) string {
+	element := collection.Elem()
+	for element.Kind() == reflect.Pointer {
+		element = element.Elem()
+	}
+	if element.Kind() != reflect.Struct {
+		return fieldName
+	}
+
+	field, ok := element.FieldByName(fieldName)
+	if !ok {
+		return fieldName
+	}
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return fieldName
+}